@@ -3,12 +3,15 @@ package main
 import (
 	"log"
 	"time"
+	_ "time/tzdata" // APP_TIMEZONE tetap bisa dimuat di image tanpa zoneinfo
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/config"
 	"bjb-backoffice/internal/database"
 	"bjb-backoffice/internal/domain"
 	httpHandler "bjb-backoffice/internal/http/handler"
 	httpRouter "bjb-backoffice/internal/http/router"
+	"bjb-backoffice/internal/mail"
 	"bjb-backoffice/internal/repository"
 	"bjb-backoffice/internal/service"
 
@@ -20,7 +23,8 @@ import (
 func main() {
 	_ = godotenv.Load()
 	cfg := config.Load()
-	db := database.Connect(cfg.DBDSN)
+	clock.SetLocation(cfg.Location)
+	db := database.Connect(cfg.DBDSN, cfg.Timezone)
 
 	// AutoMigrate
 	if err := db.AutoMigrate(
@@ -38,38 +42,90 @@ func main() {
 	findingRepo := repository.NewFindingRepository(db)
 	lateRepo := repository.NewLatenessRepository(db)
 	schedRepo := repository.NewScheduleRepository(db)
+	versionRepo := repository.NewScheduleVersionRepository(db)
 	leaveRepo := repository.NewLeaveRepository(db)
 	swapRepo := repository.NewSwapRepository(db)
 	notifRepo := repository.NewNotificationRepository(db)
 	holidayRepo := repository.NewHolidaySwapRepository(db)
 	cwcRepo := repository.NewCWCRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	resetRepo := repository.NewPasswordResetRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	teamRepo := repository.NewTeamRepository(db)
+	permRepo := repository.NewPermissionRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	shiftRepo := repository.NewShiftRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+	coverageRepo := repository.NewCoverageRepository(db)
+	staffingRepo := repository.NewStaffingRepository(db)
+	laborRepo := repository.NewLaborRuleRepository(db)
+	rosterRepo := repository.NewRosterDraftRepository(db)
+	publicHolidayRepo := repository.NewPublicHolidayRepository(db)
 
 	// services
-	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret, cfg.JWTIssuer, cfg.JWTExpiryH)
-	userSvc := service.NewUserService(userRepo, roleRepo, authSvc)
-	findingSvc := service.NewFindingService(findingRepo, userRepo)
+	auditSvc := service.NewAuditService(auditRepo)
+	guardSvc := service.NewLoginGuardService(loginAttemptRepo, service.LoginGuardConfig{
+		MaxAttempts: cfg.LoginMaxAttempts,
+		LockFor:     time.Duration(cfg.LoginLockM) * time.Minute,
+		IPMax:       cfg.LoginIPMax,
+		IPWindow:    time.Duration(cfg.LoginIPWindowM) * time.Minute,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    4 * time.Second,
+	}, auditSvc)
+	authSvc := service.NewAuthService(userRepo, sessionRepo, teamRepo, guardSvc, cfg.JWTSecret, cfg.JWTIssuer, cfg.JWTAccessM, cfg.JWTRefresh)
+	mailer := mail.NewSender(cfg.MailDriver, cfg.MailFrom, cfg.MailDir)
+	resetSvc := service.NewPasswordResetService(userRepo, resetRepo, authSvc, mailer, cfg.FrontendURL, cfg.ResetTTLM)
+	userSvc := service.NewUserService(userRepo, roleRepo, authSvc, schedRepo, leaveRepo, swapRepo, holidayRepo, auditSvc)
+	findingSvc := service.NewFindingService(findingRepo, userRepo, auditSvc)
 	notifSvc := service.NewNotificationService(notifRepo)
-	lateSvc := service.NewLatenessService(lateRepo, userRepo)
-	schedSvc := service.NewScheduleService(schedRepo)
-	leaveSvc := service.NewLeaveService(leaveRepo, userRepo, notifSvc, findingSvc, schedSvc) // pass schedSvc
-	swapSvc := service.NewSwapService(swapRepo, schedSvc, notifSvc, userRepo)
-	holidaySvc := service.NewHolidaySwapService(holidayRepo, schedSvc, notifSvc, userRepo)
-	cwcSvc := service.NewCWCService(cwcRepo)
+	lateSvc := service.NewLatenessService(lateRepo, userRepo, auditSvc)
+	laborSvc := service.NewLaborService(laborRepo, schedRepo, userRepo, auditSvc)
+	schedSvc := service.NewScheduleService(schedRepo, versionRepo, userRepo, laborSvc, notifSvc, auditSvc)
+	shiftSvc := service.NewShiftService(shiftRepo, schedRepo, versionRepo, userRepo, laborSvc, auditSvc)
+	teamSvc := service.NewTeamService(teamRepo, userRepo, auditSvc)
+	permSvc := service.NewPermissionService(permRepo, auditSvc)
+	if err := permSvc.Init(); err != nil {
+		log.Fatal("load permissions: ", err)
+	}
+	staffingSvc := service.NewStaffingService(staffingRepo, schedRepo, permSvc, auditSvc)
+	leaveSvc := service.NewLeaveService(leaveRepo, userRepo, notifSvc, findingSvc, schedSvc, teamSvc, staffingSvc, publicHolidayRepo, auditSvc) // pass schedSvc
+	swapSvc := service.NewSwapService(swapRepo, schedSvc, notifSvc, userRepo, teamSvc, staffingSvc, auditSvc)
+	holidaySvc := service.NewHolidaySwapService(holidayRepo, schedSvc, notifSvc, userRepo, teamSvc, staffingSvc, auditSvc)
+	cwcSvc := service.NewCWCService(cwcRepo, auditSvc)
+	coverageSvc := service.NewCoverageService(coverageRepo, schedRepo, leaveRepo, publicHolidayRepo, auditSvc)
+	rosterSvc := service.NewRosterService(rosterRepo, shiftRepo, schedRepo, versionRepo, userRepo, leaveRepo, coverageRepo, publicHolidayRepo, laborSvc, auditSvc)
+	publicHolidaySvc := service.NewPublicHolidayService(publicHolidayRepo, schedRepo, userRepo, auditSvc)
+	calendarSvc := service.NewCalendarService(calendarRepo, schedRepo, leaveRepo, holidayRepo, userRepo, auditSvc)
 
 	// handlers
-	authH := httpHandler.NewAuthHandler(authSvc)
+	authH := httpHandler.NewAuthHandler(authSvc, resetSvc)
 	userH := httpHandler.NewUserHandler(userSvc)
-	findingH := httpHandler.NewFindingHandler(findingSvc)
-	lateH := httpHandler.NewLatenessHandler(lateSvc)
-	schedH := httpHandler.NewScheduleHandler(schedSvc, userRepo)
+	findingH := httpHandler.NewFindingHandler(findingSvc, teamSvc, userSvc)
+	lateH := httpHandler.NewLatenessHandler(lateSvc, teamSvc, userSvc)
+	schedH := httpHandler.NewScheduleHandler(schedSvc, userRepo, teamSvc, shiftSvc, publicHolidaySvc)
 	leaveH := httpHandler.NewLeaveHandler(leaveSvc)
 	swapH := httpHandler.NewSwapHandler(swapSvc, schedSvc, userSvc)
 	notifH := httpHandler.NewNotificationHandler(notifSvc)
-	holidayH := httpHandler.NewHolidaySwapHandler(holidaySvc)
+	holidayH := httpHandler.NewHolidaySwapHandler(holidaySvc, userSvc)
 	cwcH := httpHandler.NewCWCHandler(cwcSvc)
+	securityH := httpHandler.NewSecurityHandler(guardSvc)
+	teamH := httpHandler.NewTeamHandler(teamSvc, userSvc)
+	permH := httpHandler.NewPermissionHandler(permSvc)
+	auditH := httpHandler.NewAuditHandler(auditSvc, userSvc)
+	shiftH := httpHandler.NewShiftHandler(shiftSvc)
+	calH := httpHandler.NewCalendarHandler(calendarSvc, cfg.PublicURL)
+	covH := httpHandler.NewCoverageHandler(coverageSvc)
+	staffH := httpHandler.NewStaffingHandler(staffingSvc)
+	laborH := httpHandler.NewLaborHandler(laborSvc, teamSvc)
+	rosterH := httpHandler.NewRosterHandler(rosterSvc, teamSvc)
+	publicHolidayH := httpHandler.NewPublicHolidayHandler(publicHolidaySvc, teamSvc)
 
 	// Gin & CORS
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{
+		// URL feed kalender berisi token rahasia, jangan sampai tercatat di log
+		Skip: func(c *gin.Context) bool { return c.FullPath() == "/api/v1/calendar/:feed" },
+	}), gin.Recovery())
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
@@ -85,8 +141,10 @@ func main() {
 	// Router
 	httpRouter.Setup(
 		r,
-		authH, userH, findingH, lateH, schedH, leaveH, swapH, notifH, holidayH, cwcH,
+		authH, userH, findingH, lateH, schedH, leaveH, swapH, notifH, holidayH, cwcH, securityH, teamH, permH, auditH, shiftH, calH, covH, staffH, laborH, rosterH, publicHolidayH,
 		[]byte(cfg.JWTSecret),
+		authSvc,
+		permSvc,
 	)
 
 	log.Println("listening on :8080")
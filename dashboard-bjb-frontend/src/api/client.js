@@ -12,13 +12,58 @@ api.interceptors.request.use((cfg) => {
   return cfg;
 });
 
-// optional: on 401, cleanup token (biar ga stuck) — nanti UI akan redirect via ProtectedRoute
+function storedRefresh() {
+  return sessionStorage.getItem("refresh_token") || localStorage.getItem("refresh_token");
+}
+
+function storeTokens(access, refresh) {
+  // simpan di storage yang sama dengan token sebelumnya (remember me → localStorage)
+  const persist = !!localStorage.getItem("refresh_token");
+  sessionStorage.setItem("token", access);
+  sessionStorage.setItem("refresh_token", refresh);
+  if (persist) {
+    localStorage.setItem("token", access);
+    localStorage.setItem("refresh_token", refresh);
+  }
+  api.defaults.headers.common.Authorization = `Bearer ${access}`;
+}
+
+function clearTokens() {
+  ["token", "refresh_token", "user"].forEach((k) => {
+    sessionStorage.removeItem(k);
+    localStorage.removeItem(k);
+  });
+}
+
+// satu refresh untuk banyak request paralel yang sama-sama kena 401
+let refreshing = null;
+
+async function refreshTokens() {
+  const rt = storedRefresh();
+  if (!rt) throw new Error("no refresh token");
+  const { data } = await axios.post(`${api.defaults.baseURL}/auth/refresh`, { refresh_token: rt });
+  storeTokens(data.access_token, data.refresh_token);
+  return data.access_token;
+}
+
+// on 401: coba refresh sekali, kalau gagal bersihkan token — UI akan redirect via ProtectedRoute
 api.interceptors.response.use(
   r => r,
-  err => {
-    if (err?.response?.status === 401) {
-      sessionStorage.removeItem("token"); sessionStorage.removeItem("user");
-      localStorage.removeItem("token"); localStorage.removeItem("user");
+  async err => {
+    const cfg = err?.config;
+    const isAuthCall = cfg?.url?.includes("/auth/");
+    if (err?.response?.status === 401 && cfg && !cfg._retried && !isAuthCall && storedRefresh()) {
+      cfg._retried = true;
+      try {
+        refreshing = refreshing || refreshTokens().finally(() => { refreshing = null; });
+        const access = await refreshing;
+        cfg.headers.Authorization = `Bearer ${access}`;
+        return api(cfg);
+      } catch {
+        clearTokens();
+      }
+    } else if (err?.response?.status === 401) {
+      clearTokens();
     }
     return Promise.reject(err);
   }
@@ -21,8 +21,10 @@ export function setStoredUser(u, persist = false) {
 
 export function clearAuth() {
   sessionStorage.removeItem("token");
+  sessionStorage.removeItem("refresh_token");
   sessionStorage.removeItem("user");
   localStorage.removeItem("token");
+  localStorage.removeItem("refresh_token");
   localStorage.removeItem("user");
   window.dispatchEvent(new Event("auth:user-changed"));
 }
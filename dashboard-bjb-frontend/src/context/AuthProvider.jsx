@@ -33,9 +33,11 @@ export function AuthProvider({ children }) {
 
     api.defaults.headers.common.Authorization = `Bearer ${token}`;
     sessionStorage.setItem("token", token);
+    sessionStorage.setItem("refresh_token", data?.refresh_token || "");
     sessionStorage.setItem("user", JSON.stringify(u || null));
     if (remember) {
       localStorage.setItem("token", token);
+      localStorage.setItem("refresh_token", data?.refresh_token || "");
       localStorage.setItem("user", JSON.stringify(u || null));
     }
     setUser(u || null);
@@ -43,9 +45,11 @@ export function AuthProvider({ children }) {
   };
 
   const logout = () => {
+    const rt = sessionStorage.getItem("refresh_token") || localStorage.getItem("refresh_token");
+    if (rt) api.post("/auth/logout", { refresh_token: rt }).catch(() => {});
     delete api.defaults.headers.common.Authorization;
-    sessionStorage.removeItem("token"); sessionStorage.removeItem("user");
-    localStorage.removeItem("token"); localStorage.removeItem("user");
+    sessionStorage.removeItem("token"); sessionStorage.removeItem("user"); sessionStorage.removeItem("refresh_token");
+    localStorage.removeItem("token"); localStorage.removeItem("user"); localStorage.removeItem("refresh_token");
     setUser(null);
     window.location.href = "/login";
   };
@@ -154,7 +154,7 @@ export default function AgentHolidaySwap(){
                     </div>
                   )}
                 </div>
-                <div className="meta"><span>Target:</span><b>{nameById[it.target_user_id] || `Agent #${it.target_user_id}`}</b></div>
+                <div className="meta"><span>Target:</span><b>{it.target_user_name || nameById[it.target_user_id] || `Agent #${it.target_user_id}`}</b></div>
                 <div className="meta"><span>Tanggal:</span><b>{dayjs(it.off_date).format("DD MMM YYYY")}</b></div>
                 <div className="meta"><span>Status:</span><b><Chip>{it.status}</Chip></b></div>
                 {it.reason && <div className="meta"><span>Alasan:</span><b>{it.reason}</b></div>}
@@ -183,7 +183,7 @@ export default function AgentHolidaySwap(){
                     </div>
                   )}
                 </div>
-                <div className="meta"><span>Pengaju:</span><b>{nameById[it.requester_id] || `Agent #${it.requester_id}`}</b></div>
+                <div className="meta"><span>Pengaju:</span><b>{it.requester_name || nameById[it.requester_id] || `Agent #${it.requester_id}`}</b></div>
                 <div className="meta"><span>Tanggal:</span><b>{dayjs(it.off_date).format("DD MMM YYYY")}</b></div>
                 <div className="meta"><span>Status:</span><b><Chip>{it.status}</Chip></b></div>
                 {it.reason && <div className="meta"><span>Alasan:</span><b>{it.reason}</b></div>}
@@ -229,7 +229,7 @@ export default function AgentHolidaySwap(){
                 </div>
                 <div className="meta"><span>Peran Saya:</span><b>{it.requester_id === myId ? "Pengaju" : "Target"}</b></div>
                 <div className="meta"><span>Tanggal OFF:</span><b>{dayjs(it.off_date).format("DD MMM YYYY")}</b></div>
-                <div className="meta"><span>Lawannya:</span><b>{it.requester_id === myId ? (nameById[it.target_user_id] || `Agent #${it.target_user_id}`) : (nameById[it.requester_id] || `Agent #${it.requester_id}`)}</b></div>
+                <div className="meta"><span>Lawannya:</span><b>{it.requester_id === myId ? (it.target_user_name || nameById[it.target_user_id] || `Agent #${it.target_user_id}`) : (it.requester_name || nameById[it.requester_id] || `Agent #${it.requester_id}`)}</b></div>
                 {it.reason && <div className="meta"><span>Alasan:</span><b>{it.reason}</b></div>}
               </div>
             ))}
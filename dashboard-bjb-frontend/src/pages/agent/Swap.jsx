@@ -325,7 +325,7 @@ export default function AgentSwap() {
                 {sw.target_user_id && (
                   <div className="meta">
                     <span>Ditujukan ke:</span>
-                    <b>{nameById[sw.target_user_id] || `Agent #${sw.target_user_id}`}</b>
+                    <b>{sw.target_user_name || nameById[sw.target_user_id] || `Agent #${sw.target_user_id}`}</b>
                   </div>
                 )}
                 <div className="meta">
@@ -389,7 +389,7 @@ export default function AgentSwap() {
                   {sw.target_user_id && (
                     <div className="meta">
                       <span>Ditujukan ke:</span>
-                      <b>{nameById[sw.target_user_id] || `Agent #${sw.target_user_id}`}</b>
+                      <b>{sw.target_user_name || nameById[sw.target_user_id] || `Agent #${sw.target_user_id}`}</b>
                     </div>
                   )}
                   <div className="meta">
@@ -445,6 +445,7 @@ export default function AgentSwap() {
             {filteredHistory.length === 0 && <div className="helper">Belum ada riwayat.</div>}
             {filteredHistory.map((it) => {
               const otherId = it.requester_id === myId ? it.counterparty_id : it.requester_id;
+              const otherName = it.requester_id === myId ? it.counterparty_name : it.requester_name;
               return (
                 <div key={it.id} className="swap-item">
                   <div className="swap-item__top">
@@ -455,7 +456,7 @@ export default function AgentSwap() {
                   </div>
                   <div className="meta">
                     <span>Dengan:</span>
-                    <b>{otherId ? nameById[otherId] || `Agent #${otherId}` : "—"}</b>
+                    <b>{otherId ? otherName || nameById[otherId] || `Agent #${otherId}` : "—"}</b>
                   </div>
                   <div className="meta">
                     <span>Window:</span>
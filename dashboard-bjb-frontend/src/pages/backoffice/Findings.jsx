@@ -127,7 +127,7 @@ export default function BackofficeFindings(){
                     <button className="btn btn-secondary" onClick={()=>onDelete(it.id)}>Hapus</button>
                   </div>
                 </div>
-                <div className="meta"><span>Agent:</span><b>{nameById[it.agent_id] || `Agent #${it.agent_id}`}</b></div>
+                <div className="meta"><span>Agent:</span><b>{it.agent_name || nameById[it.agent_id] || `Agent #${it.agent_id}`}</b></div>
                 <div className="meta"><span>Tanggal:</span><b>{dayjs(it.issued_at).format("DD MMM YYYY")}</b></div>
                 <div className="meta"><span>Kategori:</span><b>{cat}</b></div>
                 {desc && <div className="meta"><span>Catatan:</span><b>{desc}</b></div>}
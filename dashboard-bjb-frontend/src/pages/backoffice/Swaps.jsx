@@ -189,11 +189,11 @@ export default function BackofficeSwaps() {
                 </div>
                 <div className="meta">
                   <span>Pengaju:</span>
-                  <b>{nameById[it.requester_id] || `Agent #${it.requester_id}`}</b>
+                  <b>{it.requester_name || nameById[it.requester_id] || `Agent #${it.requester_id}`}</b>
                 </div>
                 <div className="meta">
                   <span>Target:</span>
-                  <b>{nameById[it.target_user_id] || `Agent #${it.target_user_id}`}</b>
+                  <b>{it.target_user_name || nameById[it.target_user_id] || `Agent #${it.target_user_id}`}</b>
                 </div>
                 {it.reason && (
                   <div className="meta">
@@ -210,7 +210,7 @@ export default function BackofficeSwaps() {
             <div className="modal-backdrop" onClick={() => setOpen(null)}>
               <div className="card modal" onClick={(e) => e.stopPropagation()} style={{ width: 520 }}>
                 <h3 style={{ marginTop: 0 }}>
-                  Buat Jadwal untuk {nameById[open.target_user_id] || `Agent #${open.target_user_id}`}
+                  Buat Jadwal untuk {open.target_user_name || nameById[open.target_user_id] || `Agent #${open.target_user_id}`}
                 </h3>
                 <div className="helper">
                   Tanggal: <b>{dayjs(open.off_date).format("DD MMM YYYY")}</b> — end otomatis +8 jam.
@@ -282,11 +282,11 @@ export default function BackofficeSwaps() {
                 </div>
                 <div className="meta">
                   <span>Pengaju:</span>
-                  <b>{nameById[it.requester_id] || `Agent #${it.requester_id}`}</b>
+                  <b>{it.requester_name || nameById[it.requester_id] || `Agent #${it.requester_id}`}</b>
                 </div>
                 <div className="meta">
                   <span>Target:</span>
-                  <b>{nameById[it.target_user_id] || `Agent #${it.target_user_id}`}</b>
+                  <b>{it.target_user_name || nameById[it.target_user_id] || `Agent #${it.target_user_id}`}</b>
                 </div>
                 {it.reason && (
                   <div className="meta">
@@ -354,7 +354,7 @@ export default function BackofficeSwaps() {
                 {sw.target_user_id && (
                   <div className="meta">
                     <span>Ditujukan ke:</span>
-                    <b>{nameById[sw.target_user_id] || `Agent #${sw.target_user_id}`}</b>
+                    <b>{sw.target_user_name || nameById[sw.target_user_id] || `Agent #${sw.target_user_id}`}</b>
                   </div>
                 )}
                 <div className="meta">
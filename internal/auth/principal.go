@@ -0,0 +1,62 @@
+// Package auth menyimpan identitas user yang sedang login (Principal) di
+// context.Context. Principal di-resolve sekali oleh middleware JWTAuth lalu
+// diteruskan ke service, jadi service tidak perlu percaya ID dari handler.
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Principal = user yang sedang login.
+type Principal struct {
+	ID        uint
+	Email     string
+	Name      string
+	Roles     []string
+	TeamID    *uint // tim aktif hari ini; nil = belum punya tim
+	SessionID uint
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext mengembalikan principal; ok=false kalau request tidak lewat JWTAuth.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	p, ok := ctx.Value(ctxKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Require = FromContext yang mengembalikan ErrUnauthenticated bila kosong.
+func Require(ctx context.Context) (*Principal, error) {
+	p, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return p, nil
+}
+
+// ActorID untuk audit; 0 = sistem (seed, job).
+func ActorID(ctx context.Context) uint {
+	if p, ok := FromContext(ctx); ok {
+		return p.ID
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+// Package clock menyimpan zona waktu organisasi. Semua batas hari / bulan dan
+// perbandingan "hari yang sama" memakai zona ini, bukan time.Local server,
+// supaya hasilnya sama di mana pun API dijalankan.
+package clock
+
+import "time"
+
+// DefaultTimezone dipakai kalau APP_TIMEZONE kosong.
+const DefaultTimezone = "Asia/Jakarta"
+
+// sebelum SetLocation: WIB (UTC+7, tanpa DST)
+var loc = time.FixedZone("WIB", 7*60*60)
+
+// SetLocation dipanggil sekali saat start (dari config), sebelum server jalan.
+func SetLocation(l *time.Location) {
+	if l != nil {
+		loc = l
+	}
+}
+
+func Location() *time.Location { return loc }
+
+// Now = waktu sekarang di zona organisasi.
+func Now() time.Time { return time.Now().In(Location()) }
+
+// Date = pukul 00:00 tanggal itu di zona organisasi.
+func Date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, Location())
+}
+
+// DayOf = awal hari (00:00) tempat t jatuh menurut zona organisasi.
+func DayOf(t time.Time) time.Time {
+	t = t.In(Location())
+	return Date(t.Year(), t.Month(), t.Day())
+}
+
+// FromDate untuk kolom DATE: driver mengembalikannya sebagai 00:00 UTC, jadi
+// tanggalnya diambil apa adanya (tanpa konversi zona) lalu dijadikan 00:00 lokal.
+func FromDate(t time.Time) time.Time {
+	return Date(t.Year(), t.Month(), t.Day())
+}
+
+// SameDay: a dan b jatuh di tanggal yang sama menurut zona organisasi.
+func SameDay(a, b time.Time) bool {
+	return DayOf(a).Equal(DayOf(b))
+}
+
+// MonthStart = tanggal 1 bulan tempat t jatuh menurut zona organisasi.
+func MonthStart(t time.Time) time.Time {
+	t = t.In(Location())
+	return Date(t.Year(), t.Month(), 1)
+}
@@ -1,7 +1,9 @@
 package database
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -9,20 +11,77 @@ import (
 	"bjb-backoffice/internal/domain"
 )
 
-func Connect(dsn string) *gorm.DB {
+// Connect membuka koneksi lalu menjalankan migrasi. timezone = zona organisasi
+// (APP_TIMEZONE), dipakai untuk membaca kolom TIMESTAMP lama tanpa zona.
+func Connect(dsn, timezone string) *gorm.DB {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("db connect: %v", err)
 	}
 
+	// harus sebelum AutoMigrate: AutoMigrate juga mengubah tipe kolom ke
+	// timestamptz, tapi memakai zona session DB (biasanya UTC) sehingga jam
+	// jadwal lama bergeser
+	if err := db.Exec(timestamptzSQL(timezone)).Error; err != nil {
+		log.Fatalf("timestamptz migration: %v", err)
+	}
+
+	// unique index lama atas teams.name ikut mengunci nama tim yang sudah
+	// dihapus (soft delete); diganti idx_teams_name_live (WHERE deleted_at IS NULL)
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_teams_name`).Error; err != nil {
+		log.Fatalf("drop idx_teams_name: %v", err)
+	}
+
 	// Auto-migrate
 	if err := db.AutoMigrate(
-		&domain.Role{}, &domain.User{}, &domain.UserRole{},
+		&domain.Role{}, &domain.User{}, &domain.UserRole{}, &domain.Session{}, &domain.PasswordResetToken{},
+		&domain.LoginAttempt{}, &domain.AccountLock{},
 		&domain.Finding{}, &domain.Lateness{},
 		&domain.Schedule{}, &domain.LeaveRequest{}, &domain.SwapRequest{}, &domain.Notification{},
+		&domain.Team{}, &domain.TeamMember{}, &domain.RolePermission{}, &domain.PermissionKey{},
+		&domain.AuditLog{},
+		&domain.ShiftTemplate{}, &domain.RotationPattern{}, &domain.RotationSlot{}, &domain.CalendarFeed{}, &domain.CoverageRequirement{}, &domain.StaffingRule{}, &domain.LaborRules{}, &domain.RosterDraft{}, &domain.RosterDraftItem{}, &domain.ScheduleVersion{}, &domain.PublicHoliday{},
 	); err != nil {
 		log.Fatalf("auto-migrate: %v", err)
 	}
 
+	// audit_logs append-only: tolak UPDATE/DELETE di level DB
+	if err := db.Exec(auditImmutableSQL).Error; err != nil {
+		log.Fatalf("audit trigger: %v", err)
+	}
+
 	return db
 }
+
+// timestamptzSQL = migrations/20261018_schedules_timestamptz.sql dengan zona
+// dari konfigurasi. Kolom yang sudah timestamptz dilewati, aman dijalankan ulang.
+func timestamptzSQL(timezone string) string {
+	return fmt.Sprintf(`
+DO $$
+DECLARE
+	tz  CONSTANT TEXT := '%s';
+	col RECORD;
+BEGIN
+	FOR col IN
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND data_type = 'timestamp without time zone'
+		  AND (table_name, column_name) IN (
+			('schedules', 'start_at'), ('schedules', 'end_at'), ('schedules', 'published_at'),
+			('holiday_swaps', 'off_date'))
+	LOOP
+		EXECUTE format('ALTER TABLE %%I ALTER COLUMN %%I TYPE TIMESTAMPTZ USING %%I AT TIME ZONE %%L',
+			col.table_name, col.column_name, col.column_name, tz);
+	END LOOP;
+END $$;`, strings.ReplaceAll(timezone, "'", "''"))
+}
+
+const auditImmutableSQL = `
+CREATE OR REPLACE FUNCTION audit_logs_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_audit_logs_immutable ON audit_logs;
+CREATE TRIGGER trg_audit_logs_immutable BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION audit_logs_immutable();
+`
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// AuditLog = jejak setiap perubahan data (append-only; UPDATE/DELETE ditolak
+// oleh trigger database).
+type AuditLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	ActorID    *uint     `gorm:"index"` // nil = sistem
+	Action     string    `gorm:"size:64;index;not null"`
+	EntityType string    `gorm:"size:40;index:idx_audit_entity;not null"`
+	EntityID   uint      `gorm:"index:idx_audit_entity"`
+	Before     *string   `gorm:"type:jsonb"`
+	After      *string   `gorm:"type:jsonb"`
+	CreatedAt  time.Time `gorm:"index;not null"`
+}
+
+// Entity type audit
+const (
+	AuditUser          = "user"
+	AuditSchedule      = "schedule"
+	AuditFinding       = "finding"
+	AuditLateness      = "lateness"
+	AuditLeave         = "leave"
+	AuditSwap          = "swap"
+	AuditHoliday       = "holiday_swap"
+	AuditCWC           = "cwc"
+	AuditTeam          = "team"
+	AuditPermission    = "permission"
+	AuditSecurity      = "security"
+	AuditShift         = "shift_template"
+	AuditRotation      = "rotation_pattern"
+	AuditCoverage      = "coverage_requirement"
+	AuditStaffing      = "staffing_rule"
+	AuditLaborRules    = "labor_rules"
+	AuditRoster        = "roster_draft"
+	AuditPublicHoliday = "public_holiday"
+)
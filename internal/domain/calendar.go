@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// CalendarFeed = token langganan .ics milik satu user (yang disimpan hanya
+// hash-nya). Regenerate menimpa hash sehingga URL lama langsung mati.
+type CalendarFeed struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"uniqueIndex;not null"`
+	TokenHash  string `gorm:"size:64;uniqueIndex;not null"`
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
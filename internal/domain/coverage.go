@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// CoverageRequirement = kebutuhan headcount minimal per channel pada jam
+// tertentu, mis. VOICE butuh 12 agent 08:00–16:00 Senin–Jumat. EndTime <=
+// StartTime berarti lewat tengah malam (dihitung dari hari mulai).
+type CoverageRequirement struct {
+	ID        uint        `gorm:"primaryKey"`
+	Channel   WorkChannel `gorm:"type:VARCHAR(10);index;not null"`
+	DayMask   int         `gorm:"not null"` // bit 0 = Senin … bit 6 = Minggu
+	StartTime string      `gorm:"size:5;not null"`
+	EndTime   string      `gorm:"size:5;not null"`
+	Headcount int         `gorm:"not null"`
+	// headcount pada hari libur (kalender PublicHoliday); nil = sama dengan Headcount
+	HolidayHeadcount *int
+	ValidFrom        *time.Time `gorm:"type:date"` // nil = sejak dulu
+	ValidTo          *time.Time `gorm:"type:date"` // nil = seterusnya (inklusif)
+	Note             string     `gorm:"size:255"`
+	Active           bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// WeekdayBit: Senin = 1<<0 … Minggu = 1<<6.
+func WeekdayBit(d time.Weekday) int { return 1 << ((int(d) + 6) % 7) }
+
+// AppliesOn = requirement berlaku untuk shift yang mulai di tanggal day.
+func (r CoverageRequirement) AppliesOn(day time.Time) bool {
+	if !r.Active || r.DayMask&WeekdayBit(day.Weekday()) == 0 {
+		return false
+	}
+	d := day.Format("2006-01-02")
+	if r.ValidFrom != nil && d < r.ValidFrom.Format("2006-01-02") {
+		return false
+	}
+	if r.ValidTo != nil && d > r.ValidTo.Format("2006-01-02") {
+		return false
+	}
+	return true
+}
+
+// HeadcountOn = kebutuhan headcount; holiday = tanggal mulai adalah hari libur.
+func (r CoverageRequirement) HeadcountOn(holiday bool) int {
+	if holiday && r.HolidayHeadcount != nil {
+		return *r.HolidayHeadcount
+	}
+	return r.Headcount
+}
+
+// Window = rentang jam requirement pada tanggal day.
+func (r CoverageRequirement) Window(day time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	return ClockWindow(day, r.StartTime, r.EndTime, loc)
+}
+
+// ClockWindow menerjemahkan jam "HH:MM"–"HH:MM" ke rentang waktu pada tanggal
+// day; end <= start didorong ke hari berikutnya.
+func ClockWindow(day time.Time, startHHMM, endHHMM string, loc *time.Location) (time.Time, time.Time, error) {
+	st, err := time.Parse("15:04", startHHMM)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_time %q invalid (HH:MM)", startHHMM)
+	}
+	en, err := time.Parse("15:04", endHHMM)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_time %q invalid (HH:MM)", endHHMM)
+	}
+	d := day.In(loc)
+	start := time.Date(d.Year(), d.Month(), d.Day(), st.Hour(), st.Minute(), 0, 0, loc)
+	end := time.Date(d.Year(), d.Month(), d.Day(), en.Hour(), en.Minute(), 0, 0, loc)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1) // lewat tengah malam
+	}
+	return start, end, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func TestClockWindow(t *testing.T) {
+	day := time.Date(2026, 10, 10, 0, 0, 0, 0, wib)
+	at := func(d, h, m int) time.Time { return time.Date(2026, 10, d, h, m, 0, 0, wib) }
+
+	tests := []struct {
+		name       string
+		day        time.Time
+		start, end string
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantErr    bool
+	}{
+		{name: "siang", day: day, start: "08:00", end: "16:00", wantStart: at(10, 8, 0), wantEnd: at(10, 16, 0)},
+		{name: "lewat tengah malam", day: day, start: "22:00", end: "06:00", wantStart: at(10, 22, 0), wantEnd: at(11, 6, 0)},
+		{name: "selesai tepat tengah malam", day: day, start: "16:00", end: "00:00", wantStart: at(10, 16, 0), wantEnd: at(11, 0, 0)},
+		{name: "jam sama = 24 jam", day: day, start: "07:30", end: "07:30", wantStart: at(10, 7, 30), wantEnd: at(11, 7, 30)},
+		{
+			name: "tanggal diambil dari zona loc", day: time.Date(2026, 10, 9, 20, 0, 0, 0, time.UTC),
+			start: "08:00", end: "16:00", wantStart: at(10, 8, 0), wantEnd: at(10, 16, 0),
+		},
+		{name: "start invalid", day: day, start: "8am", end: "16:00", wantErr: true},
+		{name: "end invalid", day: day, start: "08:00", end: "25:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, en, err := ClockWindow(tt.day, tt.start, tt.end, wib)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ClockWindow(%q, %q) error = nil, want error", tt.start, tt.end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ClockWindow(%q, %q) error = %v", tt.start, tt.end, err)
+			}
+			if !st.Equal(tt.wantStart) || !en.Equal(tt.wantEnd) {
+				t.Errorf("ClockWindow(%q, %q) = %s–%s, want %s–%s", tt.start, tt.end, st, en, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
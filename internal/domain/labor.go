@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// LaborRules = aturan ketenagakerjaan untuk jadwal (satu baris, berlaku
+// global). Nilai 0 = aturan tersebut tidak dicek.
+type LaborRules struct {
+	ID                 uint `gorm:"primaryKey"`
+	MinRestHours       int  `gorm:"not null;default:0"` // jeda minimal antar shift
+	MaxConsecutiveDays int  `gorm:"not null;default:0"` // hari kerja berturut-turut
+	MaxWeeklyHours     int  `gorm:"not null;default:0"` // per minggu Senin–Minggu
+	MinDaysOffPerMonth int  `gorm:"not null;default:0"`
+	MaxShiftHours      int  `gorm:"not null;default:0"`
+	Active             bool // tanpa default:true supaya false ikut tersimpan saat insert
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type LaborRuleCode string
+
+const (
+	LaborMinRest        LaborRuleCode = "MIN_REST"
+	LaborMaxConsecutive LaborRuleCode = "MAX_CONSECUTIVE_DAYS"
+	LaborMaxWeeklyHours LaborRuleCode = "MAX_WEEKLY_HOURS"
+	LaborMinDaysOff     LaborRuleCode = "MIN_DAYS_OFF"
+	LaborMaxShiftLength LaborRuleCode = "MAX_SHIFT_LENGTH"
+)
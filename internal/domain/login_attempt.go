@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// LoginAttempt = jejak audit setiap percobaan login (berhasil maupun gagal).
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey"`
+	Email     string    `gorm:"size:160;index;not null"`
+	IP        string    `gorm:"size:64;index"`
+	UserID    *uint     `gorm:"index"`
+	Success   bool      `gorm:"not null;default:false"`
+	Reason    string    `gorm:"size:40"` // OK | UNKNOWN_EMAIL | BAD_PASSWORD | LOCKED | IP_BLOCKED | THROTTLED | INACTIVE
+	UserAgent string    `gorm:"size:255"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// AccountLock menyimpan counter gagal login per email & status lockout-nya.
+type AccountLock struct {
+	ID           uint   `gorm:"primaryKey"`
+	Email        string `gorm:"size:160;uniqueIndex;not null"`
+	FailedCount  int    `gorm:"not null;default:0"`
+	LastFailedAt *time.Time
+	LockedUntil  *time.Time `gorm:"index"`
+	UnlockedBy   *uint
+	UnlockedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+const (
+	LoginReasonOK           = "OK"
+	LoginReasonUnknownEmail = "UNKNOWN_EMAIL"
+	LoginReasonBadPassword  = "BAD_PASSWORD"
+	LoginReasonLocked       = "LOCKED"
+	LoginReasonIPBlocked    = "IP_BLOCKED"
+	LoginReasonThrottled    = "THROTTLED" // masih dalam delay progresif
+	LoginReasonInactive     = "INACTIVE"
+)
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// PasswordResetToken = token sekali pakai untuk reset password (yang disimpan hanya hash-nya).
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
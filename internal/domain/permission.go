@@ -0,0 +1,140 @@
+package domain
+
+import "time"
+
+// Permission = hak akses granular; route & handler mengecek permission,
+// bukan daftar role. Mapping role → permission disimpan di tabel role_permissions.
+type Permission string
+
+const (
+	PermUsersRead         Permission = "users:read"
+	PermUsersManage       Permission = "users:manage"
+	PermSecurityManage    Permission = "security:manage"
+	PermPermissionsManage Permission = "permissions:manage"
+	PermTeamsRead         Permission = "teams:read"
+	PermTeamsManage       Permission = "teams:manage"
+	PermScheduleReadAll   Permission = "schedule:read_all"
+	PermScheduleWrite     Permission = "schedule:write"
+	PermFindingsReadAll   Permission = "findings:read_all"
+	PermFindingsWrite     Permission = "findings:write"
+	PermLatenessReadAll   Permission = "lateness:read_all"
+	PermLatenessWrite     Permission = "lateness:write"
+	PermLeaveReadAll      Permission = "leave:read_all"
+	PermLeaveApprove      Permission = "leave:approve"
+	PermSwapReadAll       Permission = "swap:read_all"
+	PermSwapRespond       Permission = "swap:respond"
+	PermHolidayReadAll    Permission = "holiday:read_all"
+	PermHolidayRespond    Permission = "holiday:respond"
+	PermHolidayApprove    Permission = "holiday:approve"
+	PermCWCRead           Permission = "cwc:read"
+	PermCWCWrite          Permission = "cwc:write"
+	PermAuditRead         Permission = "audit:read"
+	PermShiftsManage      Permission = "shifts:manage"
+	PermCoverageManage    Permission = "coverage:manage"
+	PermStaffingOverride  Permission = "staffing:override"
+	PermLaborRulesManage  Permission = "labor_rules:manage"
+	PermHolidaysManage    Permission = "holidays:manage"
+)
+
+type PermissionDef struct {
+	Key         Permission `json:"key"`
+	Description string     `json:"description"`
+}
+
+// PermissionRegistry = semua permission yang dikenal sistem.
+var PermissionRegistry = []PermissionDef{
+	{PermUsersRead, "Lihat daftar user"},
+	{PermUsersManage, "Kelola user (buat, ubah, arsip, import/export)"},
+	{PermSecurityManage, "Kelola lockout & audit login"},
+	{PermPermissionsManage, "Kelola mapping role → permission"},
+	{PermTeamsRead, "Lihat tim"},
+	{PermTeamsManage, "Kelola tim & anggota"},
+	{PermScheduleReadAll, "Lihat jadwal semua user"},
+	{PermScheduleWrite, "Buat/ubah/hapus jadwal"},
+	{PermFindingsReadAll, "Lihat temuan semua agent"},
+	{PermFindingsWrite, "Input/hapus temuan"},
+	{PermLatenessReadAll, "Lihat keterlambatan semua agent"},
+	{PermLatenessWrite, "Input/hapus keterlambatan"},
+	{PermLeaveReadAll, "Lihat pengajuan cuti semua user"},
+	{PermLeaveApprove, "Approve/reject cuti"},
+	{PermSwapReadAll, "Lihat semua tukar dinas"},
+	{PermSwapRespond, "Terima/batalkan tukar dinas (agent)"},
+	{PermHolidayReadAll, "Lihat semua tukar libur"},
+	{PermHolidayRespond, "Terima/tolak/batalkan tukar libur (agent)"},
+	{PermHolidayApprove, "Approve tukar libur (buat jadwal)"},
+	{PermCWCRead, "Lihat data CWC"},
+	{PermCWCWrite, "Input/hapus data CWC"},
+	{PermAuditRead, "Lihat audit log"},
+	{PermShiftsManage, "Kelola shift template & pola rotasi"},
+	{PermCoverageManage, "Kelola kebutuhan headcount (coverage)"},
+	{PermStaffingOverride, "Loloskan approval yang melanggar minimum staffing"},
+	{PermLaborRulesManage, "Kelola aturan jam kerja & istirahat jadwal"},
+	{PermHolidaysManage, "Kelola kalender hari libur (tanggal merah & libur perusahaan)"},
+}
+
+func (p Permission) Valid() bool {
+	for _, d := range PermissionRegistry {
+		if d.Key == p {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions = grant awal (sama dengan daftar role yang dulu
+// di-hardcode di router). Dipakai saat seeding permission yang belum pernah
+// tercatat di permission_keys, jadi grant hasil edit admin tidak ditimpa.
+var DefaultRolePermissions = map[RoleName][]Permission{
+	RoleSuperAdmin: {
+		PermUsersRead, PermUsersManage, PermSecurityManage, PermPermissionsManage,
+		PermTeamsRead, PermTeamsManage, PermScheduleReadAll, PermScheduleWrite,
+		PermFindingsReadAll, PermFindingsWrite, PermLatenessReadAll, PermLatenessWrite,
+		PermLeaveReadAll, PermLeaveApprove, PermSwapReadAll, PermHolidayReadAll, PermHolidayApprove,
+		PermCWCRead, PermCWCWrite, PermAuditRead, PermShiftsManage, PermCoverageManage,
+		PermStaffingOverride, PermLaborRulesManage, PermHolidaysManage,
+	},
+	RoleSPV: {
+		PermUsersRead, PermTeamsRead, PermTeamsManage, PermScheduleReadAll,
+		PermFindingsReadAll, PermFindingsWrite, PermLatenessReadAll, PermLatenessWrite,
+		PermLeaveReadAll, PermLeaveApprove, PermSwapReadAll, PermHolidayReadAll, PermHolidayApprove,
+		PermCWCRead, PermCWCWrite, PermAuditRead, PermCoverageManage, PermStaffingOverride,
+		PermLaborRulesManage, PermHolidaysManage,
+	},
+	RoleTL: {
+		PermUsersRead, PermTeamsRead, PermScheduleReadAll, PermScheduleWrite,
+		PermFindingsReadAll, PermFindingsWrite, PermLatenessReadAll, PermLatenessWrite,
+		PermLeaveReadAll, PermLeaveApprove, PermSwapReadAll, PermHolidayReadAll, PermHolidayApprove,
+		PermCWCRead, PermCWCWrite, PermShiftsManage,
+	},
+	RoleQC: {
+		PermUsersRead, PermTeamsRead, PermScheduleReadAll,
+		PermFindingsReadAll, PermFindingsWrite, PermLatenessReadAll,
+		PermLeaveReadAll, PermLeaveApprove, PermSwapReadAll, PermHolidayReadAll, PermHolidayApprove,
+		PermCWCRead, PermCWCWrite,
+	},
+	RoleHRAdmin: {
+		PermUsersRead, PermTeamsRead, PermScheduleReadAll, PermScheduleWrite,
+		PermFindingsReadAll, PermFindingsWrite, PermLatenessReadAll, PermLatenessWrite,
+		PermLeaveReadAll, PermLeaveApprove, PermSwapReadAll, PermHolidayReadAll, PermHolidayApprove,
+		PermCWCRead, PermCWCWrite, PermShiftsManage, PermCoverageManage, PermLaborRulesManage,
+		PermHolidaysManage,
+	},
+	RoleAgent: {
+		PermSwapRespond, PermHolidayRespond,
+	},
+}
+
+// RolePermission = satu grant role → permission.
+type RolePermission struct {
+	ID         uint       `gorm:"primaryKey"`
+	Role       RoleName   `gorm:"size:50;not null;uniqueIndex:ux_role_perm"`
+	Permission Permission `gorm:"size:80;not null;uniqueIndex:ux_role_perm"`
+	CreatedAt  time.Time
+}
+
+// PermissionKey mencatat permission yang sudah pernah di-seed, supaya
+// permission baru di registry otomatis dapat grant default di DB lama.
+type PermissionKey struct {
+	Key       Permission `gorm:"primaryKey;size:80"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// HolidayKind: tanggal merah nasional atau libur khusus perusahaan.
+type HolidayKind string
+
+const (
+	HolidayNational HolidayKind = "NATIONAL"
+	HolidayCompany  HolidayKind = "COMPANY"
+)
+
+// PublicHoliday = satu tanggal di kalender hari libur. Shift yang tanggal
+// bisnisnya jatuh di tanggal ini ditandai shift hari libur (premi), hari ini
+// tidak dihitung sebagai hari cuti, dan coverage memakai headcount hari libur.
+type PublicHoliday struct {
+	ID        uint        `gorm:"primaryKey"`
+	Date      time.Time   `gorm:"type:date;uniqueIndex;not null"`
+	Name      string      `gorm:"size:120;not null"`
+	Kind      HolidayKind `gorm:"type:VARCHAR(10);not null;default:'NATIONAL'"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -13,6 +13,21 @@ const (
 	RoleAgent      RoleName = "AGENT"
 )
 
+// BackofficeRoles = semua role non-agent (penerima notifikasi approval dsb).
+var BackofficeRoles = []RoleName{RoleSuperAdmin, RoleSPV, RoleQC, RoleTL, RoleHRAdmin}
+
+// AllRoles = semua role yang dikenal sistem (validasi input import dsb).
+var AllRoles = []RoleName{RoleSuperAdmin, RoleSPV, RoleQC, RoleTL, RoleHRAdmin, RoleAgent}
+
+func (r RoleName) Valid() bool {
+	for _, x := range AllRoles {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
+
 type Role struct {
 	ID        uint     `gorm:"primaryKey"`
 	Name      RoleName `gorm:"uniqueIndex;size:50;not null"`
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+type RosterDraftStatus string
+
+const (
+	RosterDraftOpen      RosterDraftStatus = "DRAFT"
+	RosterDraftApplied   RosterDraftStatus = "APPLIED"
+	RosterDraftDiscarded RosterDraftStatus = "DISCARDED"
+)
+
+// RosterDraft = hasil generator roster satu bulan. Item belum menjadi jadwal
+// sampai draft di-apply oleh TL; jadwal yang sudah ada di bulan itu tidak diubah.
+type RosterDraft struct {
+	ID        uint              `gorm:"primaryKey"`
+	Month     time.Time         `gorm:"type:date;index;not null"` // tanggal 1
+	TeamID    *uint             `gorm:"index"`                    // nil = semua agent
+	Status    RosterDraftStatus `gorm:"type:VARCHAR(10);index;not null;default:'DRAFT'"`
+	Unfilled  int               `gorm:"not null;default:0"` // slot kebutuhan yang tidak terisi
+	CreatedBy uint              `gorm:"index;not null"`
+	AppliedAt *time.Time
+	AppliedBy *uint
+	Items     []RosterDraftItem `gorm:"foreignKey:DraftID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type RosterDraftItem struct {
+	ID              uint        `gorm:"primaryKey"`
+	DraftID         uint        `gorm:"index;not null"`
+	UserID          uint        `gorm:"index;not null"`
+	StartAt         time.Time   `gorm:"not null"`
+	EndAt           time.Time   `gorm:"not null"`
+	Channel         WorkChannel `gorm:"type:VARCHAR(10);not null"`
+	ShiftName       *string     `gorm:"size:50"`
+	ShiftTemplateID *uint
+}
@@ -9,14 +9,42 @@ const (
 	ChannelSosmed WorkChannel = "SOSMED"
 )
 
+// ScheduleStatus: jadwal DRAFT hanya terlihat penyusun jadwal; agent baru
+// melihatnya setelah bulan tersebut dipublish.
+type ScheduleStatus string
+
+const (
+	ScheduleDraft     ScheduleStatus = "DRAFT"
+	SchedulePublished ScheduleStatus = "PUBLISHED"
+)
+
 type Schedule struct {
-	ID        uint        `gorm:"primaryKey"`
-	UserID    uint        `gorm:"index;not null"`
-	StartAt   time.Time   `gorm:"not null"`
-	EndAt     time.Time   `gorm:"not null"`
-	Channel   WorkChannel `gorm:"type:VARCHAR(10);not null"` // 👈 VOICE/SOSMED
-	ShiftName *string     `gorm:"size:50"`
-	Notes     *string     `gorm:"size:255"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              uint           `gorm:"primaryKey"`
+	UserID          uint           `gorm:"index;not null"`
+	StartAt         time.Time      `gorm:"not null;type:timestamptz"`
+	EndAt           time.Time      `gorm:"not null;type:timestamptz"`
+	Channel         WorkChannel    `gorm:"type:VARCHAR(10);not null"` // 👈 VOICE/SOSMED
+	ShiftName       *string        `gorm:"size:50"`
+	ShiftTemplateID *uint          `gorm:"index"` // diisi kalau dibuat dari template / rotasi
+	Notes           *string        `gorm:"size:255"`
+	Status          ScheduleStatus `gorm:"type:VARCHAR(10);index;not null;default:'PUBLISHED'"` // jadwal lama = PUBLISHED
+	PublishedAt     *time.Time     `gorm:"type:timestamptz"`
+	CreatedAt       time.Time      `gorm:"type:timestamptz"`
+	UpdatedAt       time.Time      `gorm:"type:timestamptz"`
+}
+
+// Aturan shift lintas tengah malam (mis. 22:00–06:00): shift dimiliki oleh
+// tanggal bisnis = tanggal lokal jam mulainya. Shift 10 Okt 22:00 – 11 Okt 06:00
+// adalah shift tanggal 10; tanggal 11 tetap dihitung OFF kalau tidak punya
+// shift sendiri (sisa jam paginya hanya "limpahan" dari tanggal 10).
+
+// BusinessDate = tengah malam tanggal mulai shift di zona loc.
+func (s Schedule) BusinessDate(loc *time.Location) time.Time {
+	st := s.StartAt.In(loc)
+	return time.Date(st.Year(), st.Month(), st.Day(), 0, 0, 0, 0, loc)
+}
+
+// Overnight = shift selesai setelah tanggal bisnisnya berakhir.
+func (s Schedule) Overnight(loc *time.Location) bool {
+	return s.EndAt.After(s.BusinessDate(loc).AddDate(0, 0, 1))
 }
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleBusinessDate(t *testing.T) {
+	at := func(d, h, m int) time.Time { return time.Date(2026, 10, d, h, m, 0, 0, wib) }
+
+	tests := []struct {
+		name          string
+		start, end    time.Time
+		wantDate      time.Time
+		wantOvernight bool
+	}{
+		{name: "shift pagi", start: at(10, 8, 0), end: at(10, 16, 0), wantDate: at(10, 0, 0)},
+		{name: "shift malam milik tanggal mulai", start: at(10, 22, 0), end: at(11, 6, 0), wantDate: at(10, 0, 0), wantOvernight: true},
+		{name: "selesai tepat tengah malam", start: at(10, 16, 0), end: at(11, 0, 0), wantDate: at(10, 0, 0)},
+		{name: "mulai dini hari", start: at(11, 0, 0), end: at(11, 8, 0), wantDate: at(11, 0, 0)},
+		{
+			name:  "StartAt UTC dibaca di zona loc",
+			start: time.Date(2026, 10, 9, 17, 30, 0, 0, time.UTC), end: time.Date(2026, 10, 10, 1, 30, 0, 0, time.UTC),
+			wantDate: at(10, 0, 0),
+		},
+		{
+			name:  "UTC lewat tengah malam lokal",
+			start: time.Date(2026, 10, 10, 15, 0, 0, 0, time.UTC), end: time.Date(2026, 10, 10, 23, 0, 0, 0, time.UTC),
+			wantDate: at(10, 0, 0), wantOvernight: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Schedule{StartAt: tt.start, EndAt: tt.end}
+			if got := s.BusinessDate(wib); !got.Equal(tt.wantDate) {
+				t.Errorf("BusinessDate() = %s, want %s", got, tt.wantDate)
+			}
+			if got := s.Overnight(wib); got != tt.wantOvernight {
+				t.Errorf("Overnight() = %v, want %v", got, tt.wantOvernight)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+type ScheduleChangeOp string
+
+const (
+	ScheduleOpCreate ScheduleChangeOp = "CREATE"
+	ScheduleOpUpdate ScheduleChangeOp = "UPDATE"
+	ScheduleOpDelete ScheduleChangeOp = "DELETE"
+)
+
+// ScheduleSource = jalur yang mengubah jadwal.
+type ScheduleSource string
+
+const (
+	ScheduleSourceManual   ScheduleSource = "MANUAL"
+	ScheduleSourceLeave    ScheduleSource = "LEAVE"
+	ScheduleSourceSwap     ScheduleSource = "SWAP"
+	ScheduleSourceHoliday  ScheduleSource = "HOLIDAY_SWAP"
+	ScheduleSourceRotation ScheduleSource = "ROTATION"
+	ScheduleSourceImport   ScheduleSource = "IMPORT"
+	ScheduleSourceRoster   ScheduleSource = "ROSTER"
+	ScheduleSourceClone    ScheduleSource = "CLONE"
+	ScheduleSourcePublish  ScheduleSource = "PUBLISH"
+	ScheduleSourceRestore  ScheduleSource = "RESTORE"
+	// kondisi jadwal sebelum versioning ada, dicatat saat perubahan pertama
+	ScheduleSourceLegacy ScheduleSource = "LEGACY"
+)
+
+// ScheduleVersion = snapshot jadwal SETELAH satu perubahan; untuk DELETE berisi
+// kondisi terakhir sebelum dihapus. Tetap ada walau baris jadwalnya sudah dihapus.
+type ScheduleVersion struct {
+	ID              uint             `gorm:"primaryKey"`
+	ScheduleID      uint             `gorm:"index;not null"`
+	Op              ScheduleChangeOp `gorm:"type:VARCHAR(10);not null"`
+	Source          ScheduleSource   `gorm:"type:VARCHAR(20);index;not null"`
+	Reason          *string          `gorm:"size:255"`
+	ActorID         *uint            `gorm:"index"` // nil = sistem
+	UserID          uint             `gorm:"index;not null"`
+	StartAt         time.Time        `gorm:"index;not null;type:timestamptz"`
+	EndAt           time.Time        `gorm:"not null;type:timestamptz"`
+	Channel         WorkChannel      `gorm:"type:VARCHAR(10);not null"`
+	ShiftName       *string          `gorm:"size:50"`
+	ShiftTemplateID *uint
+	Notes           *string        `gorm:"size:255"`
+	Status          ScheduleStatus `gorm:"type:VARCHAR(10);not null"`
+	CreatedAt       time.Time      `gorm:"index;type:timestamptz"`
+}
+
+// Schedule membentuk ulang jadwal dari snapshot (dipakai saat restore).
+func (v ScheduleVersion) Schedule() Schedule {
+	return Schedule{
+		ID: v.ScheduleID, UserID: v.UserID, StartAt: v.StartAt, EndAt: v.EndAt, Channel: v.Channel,
+		ShiftName: v.ShiftName, ShiftTemplateID: v.ShiftTemplateID, Notes: v.Notes, Status: v.Status,
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Session = satu refresh token yang aktif untuk satu login (device/browser).
+// Token aslinya tidak disimpan, hanya hash SHA-256.
+type Session struct {
+	ID           uint       `gorm:"primaryKey"`
+	UserID       uint       `gorm:"index;not null"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	RevokedAt    *time.Time `gorm:"index"`
+	ReplacedByID *uint      // diisi saat token di-rotate lewat /auth/refresh
+	UserAgent    string     `gorm:"size:255"`
+	IP           string     `gorm:"size:64"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// ShiftTemplate = shift bernama yang bisa dipakai ulang, mis. "Pagi" 07:00–15:00 VOICE.
+// EndTime <= StartTime berarti shift lewat tengah malam. Code = singkatan yang
+// dipakai di sel roster spreadsheet (mis. "P", "S", "M").
+type ShiftTemplate struct {
+	ID        uint        `gorm:"primaryKey"`
+	Name      string      `gorm:"size:50;uniqueIndex;not null"`
+	Code      *string     `gorm:"size:10;uniqueIndex"`
+	StartTime string      `gorm:"size:5;not null"` // "HH:MM"
+	EndTime   string      `gorm:"size:5;not null"` // "HH:MM"
+	Channel   WorkChannel `gorm:"type:VARCHAR(10);not null"`
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Window menghitung jam mulai & selesai shift untuk tanggal day (zona loc).
+func (t ShiftTemplate) Window(day time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	return ClockWindow(day, t.StartTime, t.EndTime, loc)
+}
+
+// RotationPattern = pola rotasi beberapa minggu. Hari ke-0 = Senin minggu
+// pertama; hari tanpa slot = OFF.
+type RotationPattern struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:80;uniqueIndex;not null"`
+	Weeks     int    `gorm:"not null"`
+	Active    bool
+	Slots     []RotationSlot `gorm:"foreignKey:PatternID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (p RotationPattern) CycleDays() int { return p.Weeks * 7 }
+
+// RotationSlot = shift yang dipakai pada hari ke-DayIndex dalam siklus.
+type RotationSlot struct {
+	ID              uint `gorm:"primaryKey"`
+	PatternID       uint `gorm:"uniqueIndex:ux_rotation_day;not null"`
+	DayIndex        int  `gorm:"uniqueIndex:ux_rotation_day;not null"`
+	ShiftTemplateID uint `gorm:"index;not null"`
+	ShiftTemplate   ShiftTemplate
+}
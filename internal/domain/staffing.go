@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type StaffingMode string
+
+const (
+	StaffingWarn  StaffingMode = "WARN"  // tetap diproses, dengan peringatan
+	StaffingBlock StaffingMode = "BLOCK" // ditolak kecuali override SPV
+)
+
+// StaffingRule = headcount minimal per channel yang harus tersisa kapan pun
+// ada perubahan jadwal lewat cuti / tukar dinas / tukar libur.
+type StaffingRule struct {
+	ID           uint         `gorm:"primaryKey"`
+	Channel      WorkChannel  `gorm:"type:VARCHAR(10);uniqueIndex;not null"`
+	MinHeadcount int          `gorm:"not null"`
+	Mode         StaffingMode `gorm:"type:VARCHAR(10);not null;default:'WARN'"`
+	Active       bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team = squad agent di bawah satu TL (Leader) dan SPV (Supervisor).
+type Team struct {
+	ID uint `gorm:"primaryKey"`
+	// unik di antara tim yang belum dihapus; nama tim terhapus boleh dipakai lagi
+	Name         string `gorm:"size:120;not null;uniqueIndex:idx_teams_name_live,where:deleted_at IS NULL"`
+	LeaderID     *uint  `gorm:"index"` // user ber-role TL
+	SupervisorID *uint  `gorm:"index"` // user ber-role SPV
+	Active       bool
+	Members      []TeamMember
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	// soft delete: keanggotaan lama tetap ada untuk laporan tanggal lampau
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// TeamMember = keanggotaan agent di tim pada rentang tanggal tertentu.
+// EffectiveTo nil = masih berlaku; keduanya inklusif.
+type TeamMember struct {
+	ID            uint       `gorm:"primaryKey"`
+	TeamID        uint       `gorm:"index;not null"`
+	UserID        uint       `gorm:"index;not null"`
+	EffectiveFrom time.Time  `gorm:"type:date;not null"`
+	EffectiveTo   *time.Time `gorm:"type:date"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (m TeamMember) ActiveOn(day time.Time) bool {
+	d := day.Format("2006-01-02")
+	if m.EffectiveFrom.Format("2006-01-02") > d {
+		return false
+	}
+	return m.EffectiveTo == nil || m.EffectiveTo.Format("2006-01-02") >= d
+}
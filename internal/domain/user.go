@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -11,13 +12,23 @@ type User struct {
 	UUID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();uniqueIndex"`
 	FullName     string    `gorm:"size:120;not null"`
 	Email        string    `gorm:"size:160;uniqueIndex;not null"`
-	PasswordHash string    `gorm:"size:255;not null"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`
 	PhotoURL     *string   `gorm:"size:255"` // 👈 ADD THIS
 	Active       bool      `gorm:"default:true"`
 
+	// jejak lifecycle aktif/nonaktif
+	DeactivatedAt *time.Time
+	DeactivatedBy *uint
+	ReactivatedAt *time.Time
+	ReactivatedBy *uint
+
 	Roles     []Role     `gorm:"many2many:user_roles;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 	UserRoles []UserRole `gorm:"foreignKey:UserID"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// soft delete: user "diarsipkan", histori (jadwal, temuan, cuti, swap) tetap utuh
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	ArchivedBy *uint
 }
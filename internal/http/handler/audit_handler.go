@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/repository"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	svc     *service.AuditService
+	userSvc *service.UserService
+}
+
+func NewAuditHandler(svc *service.AuditService, userSvc *service.UserService) *AuditHandler {
+	return &AuditHandler{svc: svc, userSvc: userSvc}
+}
+
+// GET /audit?actor_id=&action=&entity_type=&entity_id=&from=&to=&page=&size=
+// from/to boleh RFC3339 atau YYYY-MM-DD (to inklusif untuk format tanggal).
+func (h *AuditHandler) List(c *gin.Context) {
+	q := c.Request.URL.Query()
+	f := repository.AuditFilter{Action: q.Get("action"), EntityType: q.Get("entity_type")}
+	if v := q.Get("actor_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+			return
+		}
+		u := uint(id)
+		f.ActorID = &u
+	}
+	if v := q.Get("entity_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity_id"})
+			return
+		}
+		u := uint(id)
+		f.EntityID = &u
+	}
+	var ok bool
+	if f.From, ok = parseAuditTime(q.Get("from"), false); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	if f.To, ok = parseAuditTime(q.Get("to"), true); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	f.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	f.Size, _ = strconv.Atoi(c.DefaultQuery("size", "50"))
+
+	rows, total, err := h.svc.List(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	names := map[uint]string{}
+	out := make([]gin.H, 0, len(rows))
+	for _, a := range rows {
+		actorName := "system"
+		if a.ActorID != nil {
+			n, ok := names[*a.ActorID]
+			if !ok {
+				n = h.userSvc.DisplayName(*a.ActorID)
+				names[*a.ActorID] = n
+			}
+			actorName = n
+		}
+		out = append(out, gin.H{
+			"id": a.ID, "actor_id": a.ActorID, "actor_name": actorName,
+			"action": a.Action, "entity_type": a.EntityType, "entity_id": a.EntityID,
+			"before": rawJSON(a.Before), "after": rawJSON(a.After), "created_at": a.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"page": f.Page, "size": f.Size, "total": total, "items": out})
+}
+
+func parseAuditTime(v string, end bool) (*time.Time, bool) {
+	if v == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, true
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, clock.Location())
+	if err != nil {
+		return nil, false
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+func rawJSON(s *string) json.RawMessage {
+	if s == nil {
+		return nil
+	}
+	return json.RawMessage(*s)
+}
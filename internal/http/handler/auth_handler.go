@@ -1,42 +1,152 @@
 package handler
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
+	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-type AuthHandler struct{ auth *service.AuthService }
+type AuthHandler struct {
+	auth   *service.AuthService
+	resets *service.PasswordResetService
+}
 
-func NewAuthHandler(auth *service.AuthService) *AuthHandler { return &AuthHandler{auth: auth} }
+func NewAuthHandler(auth *service.AuthService, resets *service.PasswordResetService) *AuthHandler {
+	return &AuthHandler{auth: auth, resets: resets}
+}
 
 type loginReq struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
 }
 
+type refreshReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func sessionMeta(c *gin.Context) service.SessionMeta {
+	return service.SessionMeta{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+func tokenPairJSON(p *service.TokenPair, user *domain.User) gin.H {
+	return gin.H{
+		"token":              p.AccessToken, // kompatibel dengan FE lama
+		"access_token":       p.AccessToken,
+		"access_expires_at":  p.AccessExpiresAt,
+		"refresh_token":      p.RefreshToken,
+		"refresh_expires_at": p.RefreshExpiresAt,
+		"user": gin.H{
+			"id":        user.ID,
+			"uuid":      user.UUID,
+			"name":      user.FullName,
+			"email":     user.Email,
+			"roles":     userRolesToStrings(user),
+			"photo_url": user.PhotoURL, // ⬅️ penting agar Topbar bisa langsung render foto
+		},
+	}
+}
+
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req loginReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	token, user, err := h.auth.Login(req.Email, req.Password)
+	pair, user, err := h.auth.Login(req.Email, req.Password, sessionMeta(c))
 	if err != nil {
+		var locked *service.LockedError
+		if errors.As(err, &locked) {
+			secs := int(locked.RetryAfter().Seconds())
+			c.Header("Retry-After", strconv.Itoa(secs))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": locked.Error(), "retry_after": secs})
+			return
+		}
+		if errors.Is(err, service.ErrAccountInactive) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		var cred *service.CredentialsError
+		if errors.As(err, &cred) && cred.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(cred.RetryAfter.Seconds()))))
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":        user.ID,
-			"uuid":      user.UUID,
-			"name":      user.FullName,
-			"email":     user.Email,
-			"roles":     userRolesToStrings(user),
-			"photo_url": user.PhotoURL, // ⬅️ penting agar Topbar bisa langsung render foto
-		},
-	})
+	c.JSON(http.StatusOK, tokenPairJSON(pair, user))
+}
+
+// POST /auth/refresh — tukar refresh token (rotasi) dengan pasangan token baru
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req refreshReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pair, user, err := h.auth.Refresh(req.RefreshToken, sessionMeta(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefresh) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokenPairJSON(pair, user))
+}
+
+// POST /auth/logout — cabut session milik refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req refreshReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.auth.Logout(req.RefreshToken); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+type forgotPasswordReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// POST /auth/forgot-password — selalu 200 (tidak membocorkan email terdaftar atau tidak)
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.resets.ForgotPassword(req.Email); err != nil {
+		log.Printf("[auth-forgot] failed email=%q err=%v", req.Email, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "if the email is registered, a reset link has been sent"})
+}
+
+type resetPasswordReq struct {
+	Token string `json:"token" binding:"required"`
+	New   string `json:"new" binding:"required,min=6"`
+}
+
+// POST /auth/reset-password
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.resets.ResetPassword(req.Token, req.New); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "password reset"})
 }
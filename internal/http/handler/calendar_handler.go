@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	svc       *service.CalendarService
+	publicURL string // base URL API, untuk membentuk link langganan
+}
+
+func NewCalendarHandler(s *service.CalendarService, publicURL string) *CalendarHandler {
+	return &CalendarHandler{svc: s, publicURL: strings.TrimRight(publicURL, "/")}
+}
+
+func (h *CalendarHandler) feedJSON(f *domain.CalendarFeed, raw string) gin.H {
+	out := gin.H{"active": f != nil}
+	if f != nil {
+		out["created_at"] = f.CreatedAt
+		out["last_used_at"] = f.LastUsedAt
+	}
+	if raw != "" {
+		out["url"] = h.publicURL + "/api/v1/calendar/" + raw + ".ics"
+	}
+	return out
+}
+
+// GET /me/calendar-feed — status feed (URL hanya tampil saat regenerate)
+func (h *CalendarHandler) Mine(c *gin.Context) {
+	f, err := h.svc.Feed(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.feedJSON(f, ""))
+}
+
+// POST /me/calendar-feed/regenerate — buat token baru; URL lama tidak berlaku lagi
+func (h *CalendarHandler) Regenerate(c *gin.Context) {
+	raw, f, err := h.svc.RegenerateToken(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.feedJSON(f, raw))
+}
+
+// DELETE /me/calendar-feed
+func (h *CalendarHandler) Revoke(c *gin.Context) {
+	if err := h.svc.Revoke(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
+
+// GET /calendar/:feed — publik, :feed = "<token>.ics"
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("feed"), ".ics")
+	body, err := h.svc.RenderICS(token)
+	if errors.Is(err, service.ErrCalendarFeedNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "feed not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot build feed"})
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CoverageHandler struct {
+	svc *service.CoverageService
+}
+
+func NewCoverageHandler(s *service.CoverageService) *CoverageHandler { return &CoverageHandler{svc: s} }
+
+type coverageReq struct {
+	Channel   domain.WorkChannel `json:"channel"`
+	Days      []int              `json:"days"` // 1 = Senin … 7 = Minggu
+	StartTime string             `json:"start_time"`
+	EndTime   string             `json:"end_time"`
+	Headcount *int               `json:"headcount"`
+	// headcount di hari libur; null = ikut headcount (saat update: -1 = kosongkan)
+	HolidayHeadcount *int    `json:"holiday_headcount"`
+	ValidFrom        *string `json:"valid_from"` // YYYY-MM-DD, "" = kosongkan
+	ValidTo          *string `json:"valid_to"`
+	Note             *string `json:"note"`
+	Active           *bool   `json:"active"`
+}
+
+func (r coverageReq) input() (service.CoverageInput, bool) {
+	in := service.CoverageInput{
+		Channel: r.Channel, Days: r.Days, StartTime: r.StartTime, EndTime: r.EndTime,
+		Headcount: r.Headcount, HolidayHeadcount: r.HolidayHeadcount, Note: r.Note, Active: r.Active,
+	}
+	var ok bool
+	if in.ValidFrom, ok = optionalDate(r.ValidFrom); !ok {
+		return in, false
+	}
+	if in.ValidTo, ok = optionalDate(r.ValidTo); !ok {
+		return in, false
+	}
+	return in, true
+}
+
+// optionalDate: nil = tidak diubah, "" = zero time (kosongkan).
+func optionalDate(v *string) (*time.Time, bool) {
+	if v == nil {
+		return nil, true
+	}
+	if *v == "" {
+		return &time.Time{}, true
+	}
+	t, err := time.ParseInLocation("2006-01-02", *v, clock.Location())
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+func coverageJSON(m *domain.CoverageRequirement) gin.H {
+	days := []int{}
+	for d := 1; d <= 7; d++ {
+		if m.DayMask&(1<<(d-1)) != 0 {
+			days = append(days, d)
+		}
+	}
+	date := func(t *time.Time) any {
+		if t == nil {
+			return nil
+		}
+		return t.Format("2006-01-02")
+	}
+	return gin.H{
+		"id": m.ID, "channel": m.Channel, "days": days,
+		"start_time": m.StartTime, "end_time": m.EndTime, "headcount": m.Headcount,
+		"holiday_headcount": m.HolidayHeadcount,
+		"valid_from":        date(m.ValidFrom), "valid_to": date(m.ValidTo),
+		"note": m.Note, "active": m.Active,
+	}
+}
+
+// GET /coverage/requirements?channel=
+func (h *CoverageHandler) List(c *gin.Context) {
+	var ch *domain.WorkChannel
+	if v := c.Query("channel"); v != "" {
+		w := domain.WorkChannel(v)
+		ch = &w
+	}
+	items, err := h.svc.List(ch)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, coverageJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+func (h *CoverageHandler) Create(c *gin.Context) {
+	var req coverageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	in, ok := req.input()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid valid_from/valid_to"})
+		return
+	}
+	m, err := h.svc.Create(c.Request.Context(), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, coverageJSON(m))
+}
+
+func (h *CoverageHandler) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req coverageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	in, ok := req.input()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid valid_from/valid_to"})
+		return
+	}
+	m, err := h.svc.Update(c.Request.Context(), uint(id), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, coverageJSON(m))
+}
+
+func (h *CoverageHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// GET /coverage/report?period=day|week|month&date=YYYY-MM-DD (atau from=&to=)
+// &channel=VOICE|SOSMED&interval=30
+func (h *CoverageHandler) Report(c *gin.Context) {
+	from, to, ok := periodRange(c)
+	if !ok {
+		return
+	}
+	in := service.CoverageReportInput{From: from, To: to}
+	if v := c.Query("channel"); v != "" {
+		ch := domain.WorkChannel(v)
+		if ch != domain.ChannelVoice && ch != domain.ChannelSosmed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "channel must be VOICE or SOSMED"})
+			return
+		}
+		in.Channel = &ch
+	}
+	if v := c.Query("interval"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid interval"})
+			return
+		}
+		in.IntervalMinutes = n
+	}
+	rep, err := h.svc.Report(in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// periodRange membaca ?from=&to= (tanggal, inklusif) atau ?period=day|week|month
+// &date= (default hari ini). week = Senin–Minggu, month = satu bulan kalender.
+func periodRange(c *gin.Context) (time.Time, time.Time, bool) {
+	bad := func(msg string) (time.Time, time.Time, bool) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return time.Time{}, time.Time{}, false
+	}
+	if f, t := c.Query("from"), c.Query("to"); f != "" || t != "" {
+		from, err := time.ParseInLocation("2006-01-02", f, clock.Location())
+		if err != nil {
+			return bad("invalid from")
+		}
+		to, err := time.ParseInLocation("2006-01-02", t, clock.Location())
+		if err != nil {
+			return bad("invalid to")
+		}
+		return from, to, true
+	}
+	day := clock.Now()
+	if v := c.Query("date"); v != "" {
+		d, err := time.ParseInLocation("2006-01-02", v, clock.Location())
+		if err != nil {
+			return bad("invalid date")
+		}
+		day = d
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, clock.Location())
+	switch c.DefaultQuery("period", "day") {
+	case "day":
+		return day, day, true
+	case "week":
+		mon := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return mon, mon.AddDate(0, 0, 6), true
+	case "month":
+		first := clock.MonthStart(day)
+		return first, first.AddDate(0, 1, -1), true
+	}
+	return bad("period harus day, week atau month")
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -32,12 +33,12 @@ func (h *CWCHandler) UpsertDaily(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	d, err := time.Parse("2006-01-02", req.Date)
+	d, err := time.ParseInLocation("2006-01-02", req.Date, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, use YYYY-MM-DD"})
 		return
 	}
-	if err := h.svc.UpsertDaily(service.UpsertDailyInput{
+	if err := h.svc.UpsertDaily(c.Request.Context(), service.UpsertDailyInput{
 		Date: d, Complaint: req.Complaint, Request: req.Request, Info: req.Info,
 	}); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -48,11 +49,11 @@ func (h *CWCHandler) UpsertDaily(c *gin.Context) {
 
 // GET /api/v1/cwc?start=2025-10-01&end=2025-10-31
 func (h *CWCHandler) Query(c *gin.Context) {
-	startStr := c.DefaultQuery("start", time.Now().AddDate(0, 0, -6).Format("2006-01-02"))
-	endStr := c.DefaultQuery("end", time.Now().Format("2006-01-02"))
+	startStr := c.DefaultQuery("start", clock.Now().AddDate(0, 0, -6).Format("2006-01-02"))
+	endStr := c.DefaultQuery("end", clock.Now().Format("2006-01-02"))
 
-	start, err1 := time.Parse("2006-01-02", startStr)
-	end, err2 := time.Parse("2006-01-02", endStr)
+	start, err1 := time.ParseInLocation("2006-01-02", startStr, clock.Location())
+	end, err2 := time.ParseInLocation("2006-01-02", endStr, clock.Location())
 	if err1 != nil || err2 != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date range"})
 		return
@@ -77,7 +78,7 @@ func (h *CWCHandler) GetDaily(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	d, err := time.Parse("2006-01-02", q.Date)
+	d, err := time.ParseInLocation("2006-01-02", q.Date, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format"})
 		return
@@ -102,12 +103,12 @@ func (h *CWCHandler) DeleteDaily(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	d, err := time.Parse("2006-01-02", q.Date)
+	d, err := time.ParseInLocation("2006-01-02", q.Date, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format"})
 		return
 	}
-	if err := h.svc.DeleteDaily(d); err != nil {
+	if err := h.svc.DeleteDaily(c.Request.Context(), d); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -5,15 +5,23 @@ import (
 	"strconv"
 	"time"
 
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-type FindingHandler struct{ svc *service.FindingService }
+type FindingHandler struct {
+	svc   *service.FindingService
+	teams *service.TeamService
+	users *service.UserService
+}
 
-func NewFindingHandler(s *service.FindingService) *FindingHandler { return &FindingHandler{svc: s} }
+func NewFindingHandler(s *service.FindingService, teams *service.TeamService, users *service.UserService) *FindingHandler {
+	return &FindingHandler{svc: s, teams: teams, users: users}
+}
 
 type createFindingReq struct {
 	AgentID     uint       `json:"agent_id" binding:"required"`
@@ -22,20 +30,14 @@ type createFindingReq struct {
 }
 
 func (h *FindingHandler) Create(c *gin.Context) {
-	// issuer diambil dari token (QC yang login)
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	issuerID := uint(idf)
-
 	var req createFindingReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	f, err := h.svc.Create(service.CreateFindingInput{
+	// issuer = QC yang login (dari ctx)
+	f, err := h.svc.Create(c.Request.Context(), service.CreateFindingInput{
 		AgentID:     req.AgentID,
-		IssuedByID:  issuerID,
 		Description: req.Description,
 		IssuedAt:    req.IssuedAt,
 	})
@@ -50,7 +52,7 @@ func (h *FindingHandler) Create(c *gin.Context) {
 
 func (h *FindingHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.svc.Delete(uint(id)); err != nil {
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -71,7 +73,7 @@ func (h *FindingHandler) List(c *gin.Context) {
 	var monthPtr *time.Time
 	if v := q.Get("month"); v != "" {
 		// format: 2025-10
-		if t, err := time.Parse("2006-01", v); err == nil {
+		if t, err := time.ParseInLocation("2006-01", v, clock.Location()); err == nil {
 			monthPtr = &t
 		}
 	}
@@ -88,6 +90,12 @@ func (h *FindingHandler) List(c *gin.Context) {
 		}
 	}
 
+	// filter tim: ?team=mine (TL/SPV) atau ?team_id=
+	teamIDs, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+
 	page, size := 1, 10
 	if v := q.Get("page"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -101,7 +109,7 @@ func (h *FindingHandler) List(c *gin.Context) {
 	}
 
 	items, total, err := h.svc.ListFiltered(service.ListFindingsFilter{
-		AgentID: agentID, Month: monthPtr, From: fromPtr, To: toPtr, Page: page, Size: size,
+		AgentID: agentID, AgentIDs: teamIDs, Month: monthPtr, From: fromPtr, To: toPtr, Page: page, Size: size,
 	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -110,30 +118,15 @@ func (h *FindingHandler) List(c *gin.Context) {
 
 	// RBAC visibilitas:
 	// - Agent hanya boleh lihat miliknya sendiri → jika agent & agent_id != self → 403
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	rolesAny := claims["roles"]
-	isAgent := false
-	switch rs := rolesAny.(type) {
-	case []interface{}:
-		for _, r := range rs {
-			if s, ok := r.(string); ok && s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
-	case []string:
-		for _, s := range rs {
-			if s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
+	me, ok := principal(c)
+	if !ok {
+		return
 	}
+	// tanpa permission read_all → hanya boleh lihat miliknya
+	ownOnly := !middleware.Can(c, domain.PermFindingsReadAll)
 
-	if isAgent {
-		idf, _ := claims["sub"].(float64)
-		self := uint(idf)
+	if ownOnly {
+		self := me.ID
 		// jika agent_id filter diisi dan bukan dirinya → tolak
 		if agentID != nil && *agentID != self {
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
@@ -152,10 +145,20 @@ func (h *FindingHandler) List(c *gin.Context) {
 		}
 	}
 
+	ids := make([]uint, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.AgentID)
+	}
+	names, ok := userNames(c, h.users, ids)
+	if !ok {
+		return
+	}
+
 	out := make([]gin.H, 0, len(items))
 	for _, it := range items {
 		out = append(out, gin.H{
-			"id": it.ID, "agent_id": it.AgentID, "issued_by": it.IssuedByID, "description": it.Description, "issued_at": it.IssuedAt,
+			"id": it.ID, "agent_id": it.AgentID, "agent_name": names[it.AgentID],
+			"issued_by": it.IssuedByID, "description": it.Description, "issued_at": it.IssuedAt,
 		})
 	}
 
@@ -165,13 +168,14 @@ func (h *FindingHandler) List(c *gin.Context) {
 }
 
 func (h *FindingHandler) CountMine(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	self := uint(idf)
+	me, ok := principal(c)
+	if !ok {
+		return
+	}
+	self := me.ID
 
-	monthStr := c.DefaultQuery("month", time.Now().Format("2006-01"))
-	t, err := time.Parse("2006-01", monthStr)
+	monthStr := c.DefaultQuery("month", clock.Now().Format("2006-01"))
+	t, err := time.ParseInLocation("2006-01", monthStr, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "bad month"})
 		return
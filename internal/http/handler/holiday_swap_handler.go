@@ -3,20 +3,23 @@ package handler
 import (
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-type HolidaySwapHandler struct{ svc *service.HolidaySwapService }
+type HolidaySwapHandler struct {
+	svc   *service.HolidaySwapService
+	users *service.UserService
+}
 
-func NewHolidaySwapHandler(s *service.HolidaySwapService) *HolidaySwapHandler {
-	return &HolidaySwapHandler{svc: s}
+func NewHolidaySwapHandler(s *service.HolidaySwapService, users *service.UserService) *HolidaySwapHandler {
+	return &HolidaySwapHandler{svc: s, users: users}
 }
 
 type createHolidayReq struct {
@@ -26,22 +29,17 @@ type createHolidayReq struct {
 }
 
 func (h *HolidaySwapHandler) Create(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	requester := uint(idf)
-
 	var req createHolidayReq
 	if err := c.ShouldBindJSON(&req); err != nil || req.TargetUserID == 0 || req.OffDate == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "target_user_id & off_date required"})
 		return
 	}
-	t, err := time.ParseInLocation("2006-01-02", req.OffDate, time.Local)
+	t, err := time.ParseInLocation("2006-01-02", req.OffDate, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid off_date (YYYY-MM-DD)"})
 		return
 	}
-	m, err := h.svc.Create(requester, req.TargetUserID, t, req.Reason)
+	m, err := h.svc.Create(c.Request.Context(), req.TargetUserID, t, req.Reason)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -50,6 +48,12 @@ func (h *HolidaySwapHandler) Create(c *gin.Context) {
 }
 
 func (h *HolidaySwapHandler) List(c *gin.Context) {
+	p, ok := principal(c)
+	if !ok {
+		return
+	}
+	me := p.ID
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
 
@@ -59,37 +63,16 @@ func (h *HolidaySwapHandler) List(c *gin.Context) {
 		return
 	}
 
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
-
-	// BO roles yang boleh lihat semua
-	allowed := map[string]bool{
-		"SUPER_ADMIN": true,
-		"HR_ADMIN":    true,
-		"TL":          true,
-		"SPV":         true,
-		"QC":          true,
-		"BACKOFFICE":  true, // optional
-		"ADMIN":       true, // optional
+	// backoffice (holiday:read_all) boleh lihat semua
+	isBackoffice := middleware.Can(c, domain.PermHolidayReadAll)
+
+	ids := make([]uint, 0, 2*len(rows))
+	for _, m := range rows {
+		ids = append(ids, m.RequesterID, m.TargetUserID)
 	}
-	isBackoffice := false
-	switch rs := claims["roles"].(type) {
-	case []interface{}:
-		for _, r := range rs {
-			if s, _ := r.(string); allowed[strings.ToUpper(strings.TrimSpace(s))] {
-				isBackoffice = true
-				break
-			}
-		}
-	case []string:
-		for _, s := range rs {
-			if allowed[strings.ToUpper(strings.TrimSpace(s))] {
-				isBackoffice = true
-				break
-			}
-		}
+	names, ok := userNames(c, h.users, ids)
+	if !ok {
+		return
 	}
 
 	out := make([]gin.H, 0, len(rows))
@@ -99,6 +82,7 @@ func (h *HolidaySwapHandler) List(c *gin.Context) {
 		}
 		out = append(out, gin.H{
 			"id": m.ID, "requester_id": m.RequesterID, "target_user_id": m.TargetUserID,
+			"requester_name": names[m.RequesterID], "target_user_name": names[m.TargetUserID],
 			"off_date": m.OffDate, "reason": m.Reason, "status": m.Status,
 			"approved_at": m.ApprovedAt, "created_schedule_id": m.CreatedScheduleID,
 		})
@@ -107,13 +91,8 @@ func (h *HolidaySwapHandler) List(c *gin.Context) {
 }
 
 func (h *HolidaySwapHandler) TargetAccept(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
-
 	id, _ := strconv.Atoi(c.Param("id"))
-	m, err := h.svc.TargetAccept(uint(id), me)
+	m, err := h.svc.TargetAccept(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -122,13 +101,8 @@ func (h *HolidaySwapHandler) TargetAccept(c *gin.Context) {
 }
 
 func (h *HolidaySwapHandler) TargetReject(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
-
 	id, _ := strconv.Atoi(c.Param("id"))
-	m, err := h.svc.TargetReject(uint(id), me)
+	m, err := h.svc.TargetReject(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -136,9 +110,10 @@ func (h *HolidaySwapHandler) TargetReject(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status})
 }
 
-// === BO approve: versi SIMPLE (hanya jam "HH:mm", end=+8h) ===
+// === BO approve: versi SIMPLE (jam "HH:mm"; end_time opsional, <= start = besok) ===
 type boApproveSimpleReq struct {
 	StartTime string             `json:"start_time" binding:"required"` // "HH:mm"
+	EndTime   string             `json:"end_time"`                      // "HH:mm", opsional
 	Channel   domain.WorkChannel `json:"channel" binding:"required"`    // "VOICE"|"SOSMED"
 	ShiftName *string            `json:"shift_name"`
 	Notes     *string            `json:"notes"`
@@ -153,27 +128,24 @@ func (h *HolidaySwapHandler) BOApprove(c *gin.Context) {
 		return
 	}
 
-	m, err := h.svc.BOApproveSimple(uint(id), 0, service.BOApproveSimpleInput{
+	m, check, err := h.svc.BOApproveSimple(c.Request.Context(), uint(id), service.BOApproveSimpleInput{
 		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
 		Channel:   req.Channel,
 		ShiftName: req.ShiftName,
 		Notes:     req.Notes,
+		Override:  overrideRequested(c),
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status, "created_schedule_id": m.CreatedScheduleID})
+	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status, "created_schedule_id": m.CreatedScheduleID, "staffing": check})
 }
 
 func (h *HolidaySwapHandler) Cancel(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
-
 	id, _ := strconv.Atoi(c.Param("id"))
-	m, err := h.svc.Cancel(uint(id), me)
+	m, err := h.svc.Cancel(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
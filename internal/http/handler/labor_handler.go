@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LaborHandler struct {
+	svc   *service.LaborService
+	teams *service.TeamService
+}
+
+func NewLaborHandler(s *service.LaborService, teams *service.TeamService) *LaborHandler {
+	return &LaborHandler{svc: s, teams: teams}
+}
+
+func laborRulesJSON(m *domain.LaborRules) gin.H {
+	return gin.H{
+		"min_rest_hours":         m.MinRestHours,
+		"max_consecutive_days":   m.MaxConsecutiveDays,
+		"max_weekly_hours":       m.MaxWeeklyHours,
+		"min_days_off_per_month": m.MinDaysOffPerMonth,
+		"max_shift_hours":        m.MaxShiftHours,
+		"active":                 m.Active,
+		"updated_at":             m.UpdatedAt,
+	}
+}
+
+// GET /labor-rules
+func (h *LaborHandler) Get(c *gin.Context) {
+	m, err := h.svc.Rules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, laborRulesJSON(m))
+}
+
+// 0 = aturan tidak dicek
+type laborRulesReq struct {
+	MinRestHours       int   `json:"min_rest_hours"`
+	MaxConsecutiveDays int   `json:"max_consecutive_days"`
+	MaxWeeklyHours     int   `json:"max_weekly_hours"`
+	MinDaysOffPerMonth int   `json:"min_days_off_per_month"`
+	MaxShiftHours      int   `json:"max_shift_hours"`
+	Active             *bool `json:"active"`
+}
+
+// PUT /labor-rules
+func (h *LaborHandler) Update(c *gin.Context) {
+	var req laborRulesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	m, err := h.svc.SetRules(c.Request.Context(), service.LaborRulesInput{
+		MinRestHours:       req.MinRestHours,
+		MaxConsecutiveDays: req.MaxConsecutiveDays,
+		MaxWeeklyHours:     req.MaxWeeklyHours,
+		MinDaysOffPerMonth: req.MinDaysOffPerMonth,
+		MaxShiftHours:      req.MaxShiftHours,
+		Active:             req.Active,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, laborRulesJSON(m))
+}
+
+// GET /schedules/validation?month=YYYY-MM (&team=mine | &team_id=)
+func (h *LaborHandler) Report(c *gin.Context) {
+	t, err := time.ParseInLocation("2006-01", c.DefaultQuery("month", clock.Now().Format("2006-01")), clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+		return
+	}
+	teamIDs, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+	rep, err := h.svc.MonthlyReport(t, teamIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"month": rep.Month, "rules": laborRulesJSON(rep.Rules),
+		"summary":    gin.H{"total": rep.Total, "users": rep.Users, "by_rule": rep.ByRule},
+		"violations": rep.Violations,
+	})
+}
@@ -5,15 +5,23 @@ import (
 	"strconv"
 	"time"
 
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-type LatenessHandler struct{ svc *service.LatenessService }
+type LatenessHandler struct {
+	svc   *service.LatenessService
+	teams *service.TeamService
+	users *service.UserService
+}
 
-func NewLatenessHandler(s *service.LatenessService) *LatenessHandler { return &LatenessHandler{svc: s} }
+func NewLatenessHandler(s *service.LatenessService, teams *service.TeamService, users *service.UserService) *LatenessHandler {
+	return &LatenessHandler{svc: s, teams: teams, users: users}
+}
 
 type createLateReq struct {
 	AgentID uint   `json:"agent_id" binding:"required"`
@@ -23,7 +31,7 @@ type createLateReq struct {
 
 func parseDateFlexible(s string) (time.Time, error) {
 	// coba YYYY-MM-DD dulu
-	if t, err := time.Parse("2006-01-02", s); err == nil {
+	if t, err := time.ParseInLocation("2006-01-02", s, clock.Location()); err == nil {
 		return t, nil
 	}
 	// lalu RFC3339
@@ -31,12 +39,6 @@ func parseDateFlexible(s string) (time.Time, error) {
 }
 
 func (h *LatenessHandler) Create(c *gin.Context) {
-	// NotedBy diambil dari token (HR/SPV/TL/SUPER_ADMIN yang login)
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	notedBy := uint(idf)
-
 	var req createLateReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -47,8 +49,9 @@ func (h *LatenessHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format"})
 		return
 	}
-	L, err := h.svc.Create(service.CreateLatenessInput{
-		AgentID: req.AgentID, Date: d, Minutes: req.Minutes, NotedByID: notedBy,
+	// NotedBy = user yang login (dari ctx)
+	L, err := h.svc.Create(c.Request.Context(), service.CreateLatenessInput{
+		AgentID: req.AgentID, Date: d, Minutes: req.Minutes,
 	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -61,7 +64,7 @@ func (h *LatenessHandler) Create(c *gin.Context) {
 
 func (h *LatenessHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.svc.Delete(uint(id)); err != nil {
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -91,29 +94,14 @@ func (h *LatenessHandler) List(c *gin.Context) {
 	}
 
 	// RBAC visibilitas untuk Agent: hanya boleh lihat miliknya
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	rolesAny := claims["roles"]
-	isAgent := false
-	switch rs := rolesAny.(type) {
-	case []interface{}:
-		for _, r := range rs {
-			if s, ok := r.(string); ok && s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
-	case []string:
-		for _, s := range rs {
-			if s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
+	me, ok := principal(c)
+	if !ok {
+		return
 	}
-	if isAgent {
-		idf, _ := claims["sub"].(float64)
-		self := uint(idf)
+	// tanpa permission read_all → hanya boleh lihat miliknya
+	ownOnly := !middleware.Can(c, domain.PermLatenessReadAll)
+	if ownOnly {
+		self := me.ID
 		if agentID != nil && *agentID != self {
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 			return
@@ -123,9 +111,15 @@ func (h *LatenessHandler) List(c *gin.Context) {
 		}
 	}
 
+	// filter tim: ?team=mine (TL/SPV) atau ?team_id=
+	teamIDs, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+
 	// Aggregasi?
 	if group := q.Get("group"); group != "" {
-		rows, err := h.svc.Aggregate(agentID, fromPtr, toPtr, group)
+		rows, err := h.svc.Aggregate(agentID, teamIDs, fromPtr, toPtr, group)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -154,16 +148,25 @@ func (h *LatenessHandler) List(c *gin.Context) {
 	}
 
 	items, total, err := h.svc.List(service.ListLateFilter{
-		AgentID: agentID, From: fromPtr, To: toPtr, Page: page, Size: size,
+		AgentID: agentID, AgentIDs: teamIDs, From: fromPtr, To: toPtr, Page: page, Size: size,
 	})
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	ids := make([]uint, 0, len(items))
+	for _, it := range items {
+		ids = append(ids, it.AgentID)
+	}
+	names, ok := userNames(c, h.users, ids)
+	if !ok {
+		return
+	}
 	out := make([]gin.H, 0, len(items))
 	for _, it := range items {
 		out = append(out, gin.H{
-			"id": it.ID, "agent_id": it.AgentID, "date": it.Date.Format("2006-01-02"), "minutes": it.Minutes, "noted_by": it.NotedByID,
+			"id": it.ID, "agent_id": it.AgentID, "agent_name": names[it.AgentID],
+			"date": it.Date.Format("2006-01-02"), "minutes": it.Minutes, "noted_by": it.NotedByID,
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{"page": page, "size": size, "total": total, "items": out})
@@ -9,11 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type LeaveHandler struct{ svc *service.LeaveService }
@@ -23,11 +24,6 @@ func NewLeaveHandler(s *service.LeaveService) *LeaveHandler { return &LeaveHandl
 // POST /leave-requests (multipart/form-data)
 // fields: type, start_date, end_date, reason, file
 func (h *LeaveHandler) Create(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	requester := uint(idf)
-
 	typ := c.PostForm("type")
 	startStr := c.PostForm("start_date")
 	endStr := c.PostForm("end_date")
@@ -37,12 +33,12 @@ func (h *LeaveHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "type, start_date, end_date required"})
 		return
 	}
-	sd, err := time.Parse("2006-01-02", startStr)
+	sd, err := time.ParseInLocation("2006-01-02", startStr, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "bad start_date"})
 		return
 	}
-	ed, err := time.Parse("2006-01-02", endStr)
+	ed, err := time.ParseInLocation("2006-01-02", endStr, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "bad end_date"})
 		return
@@ -74,20 +70,21 @@ func (h *LeaveHandler) Create(c *gin.Context) {
 	}
 
 	leaveType := domain.LeaveType(strings.ToUpper(strings.TrimSpace(typ)))
-	m, err := h.svc.Create(service.CreateLeaveInput{
-		RequesterID: requester,
-		Type:        leaveType,
-		StartDate:   sd,
-		EndDate:     ed,
-		Reason:      reason,
-		FileURL:     fileURL,
+	m, days, check, err := h.svc.Create(c.Request.Context(), service.CreateLeaveInput{
+		Type:      leaveType,
+		StartDate: sd,
+		EndDate:   ed,
+		Reason:    reason,
+		FileURL:   fileURL,
+		Override:  overrideRequested(c),
 	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
 		"id": m.ID, "status": m.Status, "start_date": m.StartDate, "end_date": m.EndDate, "file_url": m.FileURL,
+		"days": days, "staffing": check,
 	})
 }
 
@@ -120,28 +117,14 @@ func (h *LeaveHandler) List(c *gin.Context) {
 	}
 
 	// RBAC: agent hanya boleh lihat miliknya
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	isAgent := false
-	switch rs := claims["roles"].(type) {
-	case []interface{}:
-		for _, r := range rs {
-			if s, ok := r.(string); ok && s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
-	case []string:
-		for _, s := range rs {
-			if s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
+	me, ok := principal(c)
+	if !ok {
+		return
 	}
-	if isAgent {
-		idf, _ := claims["sub"].(float64)
-		self := uint(idf)
+	// tanpa permission read_all → hanya boleh lihat miliknya
+	ownOnly := !middleware.Can(c, domain.PermLeaveReadAll)
+	if ownOnly {
+		self := me.ID
 		if requesterID != nil && *requesterID != self {
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 			return
@@ -157,6 +140,12 @@ func (h *LeaveHandler) List(c *gin.Context) {
 		return
 	}
 
+	// jumlah hari cuti tanpa hari libur kalender
+	days, err := h.svc.CountDays(items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	out := make([]gin.H, 0, len(items))
 	for _, m := range items {
 		out = append(out, gin.H{
@@ -166,6 +155,7 @@ func (h *LeaveHandler) List(c *gin.Context) {
 			"type":           m.Type,
 			"start_date":     m.StartDate,
 			"end_date":       m.EndDate,
+			"days":           days[m.ID],
 			"reason":         m.Reason,
 			"file_url":       m.FileURL,
 			"status":         m.Status,
@@ -176,27 +166,18 @@ func (h *LeaveHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"page": page, "size": size, "total": total, "items": out})
 }
 
+// PATCH /leave-requests/:id/approve?override=true
 func (h *LeaveHandler) Approve(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	approver := uint(idf)
-
 	id, _ := strconv.Atoi(c.Param("id"))
-	m, err := h.svc.Approve(uint(id), approver)
+	m, check, err := h.svc.Approve(c.Request.Context(), uint(id), overrideRequested(c))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status})
+	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status, "staffing": check})
 }
 
 func (h *LeaveHandler) Reject(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	approver := uint(idf)
-
 	id, _ := strconv.Atoi(c.Param("id"))
 	var req struct {
 		Reason string `json:"reason" binding:"required"`
@@ -205,7 +186,7 @@ func (h *LeaveHandler) Reject(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "reason required"})
 		return
 	}
-	m, err := h.svc.Reject(uint(id), approver, req.Reason)
+	m, err := h.svc.Reject(c.Request.Context(), uint(id), req.Reason)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -231,13 +212,8 @@ func (h *LeaveHandler) svcGetNameFallback(uid uint) string {
 }
 
 func (h *LeaveHandler) Cancel(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
-
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.svc.Cancel(uint(id), me); err != nil {
+	if err := h.svc.Cancel(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
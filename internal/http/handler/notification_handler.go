@@ -8,7 +8,6 @@ import (
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type NotificationHandler struct{ svc *service.NotificationService }
@@ -18,10 +17,11 @@ func NewNotificationHandler(s *service.NotificationService) *NotificationHandler
 }
 
 func (h *NotificationHandler) ListMine(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
+	p, ok := principal(c)
+	if !ok {
+		return
+	}
+	me := p.ID
 
 	unread := c.DefaultQuery("unread", "false") == "true"
 
@@ -58,7 +58,7 @@ func (h *NotificationHandler) ListMine(c *gin.Context) {
 
 func (h *NotificationHandler) MarkRead(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.svc.MarkRead(uint(id)); err != nil {
+	if err := h.svc.MarkRead(c.Request.Context(), uint(id)); err != nil {
 		log.Printf("[notif-read] failed id=%d err=%v", id, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionHandler struct{ svc *service.PermissionService }
+
+func NewPermissionHandler(s *service.PermissionService) *PermissionHandler {
+	return &PermissionHandler{svc: s}
+}
+
+// GET /me/permissions — permission efektif user login (untuk FE show/hide menu)
+func (h *PermissionHandler) Mine(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": h.svc.For(middleware.Roles(c))})
+}
+
+// GET /permissions — registry semua permission
+func (h *PermissionHandler) Registry(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"items": h.svc.Registry()})
+}
+
+// GET /permissions/roles — mapping role → permission
+func (h *PermissionHandler) Grants(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"roles": h.svc.Grants()})
+}
+
+type setGrantsReq struct {
+	Permissions []domain.Permission `json:"permissions"`
+}
+
+// PUT /permissions/roles/:role — ganti seluruh grant role tsb
+func (h *PermissionHandler) SetRoleGrants(c *gin.Context) {
+	var req setGrantsReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := domain.RoleName(c.Param("role"))
+	perms, err := h.svc.SetRoleGrants(c.Request.Context(), role, req.Permissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"role": role, "permissions": perms})
+}
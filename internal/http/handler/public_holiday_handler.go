@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+	"bjb-backoffice/internal/tabular"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PublicHolidayHandler struct {
+	svc   *service.PublicHolidayService
+	teams *service.TeamService
+}
+
+func NewPublicHolidayHandler(s *service.PublicHolidayService, teams *service.TeamService) *PublicHolidayHandler {
+	return &PublicHolidayHandler{svc: s, teams: teams}
+}
+
+type publicHolidayReq struct {
+	Date string             `json:"date"` // YYYY-MM-DD
+	Name string             `json:"name"`
+	Kind domain.HolidayKind `json:"kind"` // NATIONAL | COMPANY
+}
+
+func publicHolidayJSON(m *domain.PublicHoliday) gin.H {
+	return gin.H{
+		"id": m.ID, "date": clock.FromDate(m.Date).Format("2006-01-02"),
+		"name": m.Name, "kind": m.Kind,
+	}
+}
+
+func (r publicHolidayReq) input() (service.PublicHolidayInput, bool) {
+	in := service.PublicHolidayInput{Name: r.Name, Kind: r.Kind}
+	if r.Date != "" {
+		d, err := time.ParseInLocation("2006-01-02", r.Date, clock.Location())
+		if err != nil {
+			return in, false
+		}
+		in.Date = &d
+	}
+	return in, true
+}
+
+// GET /public-holidays?year=YYYY (default tahun ini) atau ?from=&to=
+func (h *PublicHolidayHandler) List(c *gin.Context) {
+	var from, to time.Time
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var ok bool
+		if from, to, ok = periodRange(c); !ok {
+			return
+		}
+	} else {
+		y, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(clock.Now().Year())))
+		if err != nil || y < 1900 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+		from, to = clock.Date(y, time.January, 1), clock.Date(y, time.December, 31)
+	}
+	items, err := h.svc.List(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, publicHolidayJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from.Format("2006-01-02"), "to": to.Format("2006-01-02"), "items": out})
+}
+
+func (h *PublicHolidayHandler) Create(c *gin.Context) {
+	var req publicHolidayReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	in, ok := req.input()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	m, err := h.svc.Create(c.Request.Context(), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, publicHolidayJSON(m))
+}
+
+func (h *PublicHolidayHandler) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req publicHolidayReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	in, ok := req.input()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	m, err := h.svc.Update(c.Request.Context(), uint(id), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, publicHolidayJSON(m))
+}
+
+func (h *PublicHolidayHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// POST /public-holidays/import?dry_run=true (multipart: file .csv/.xlsx)
+// kolom: date (YYYY-MM-DD), name, kind (opsional)
+func (h *PublicHolidayHandler) Import(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if file.Size > 2*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file too large (max 2MB)"})
+		return
+	}
+	format, err := tabular.FormatFromName(file.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file"})
+		return
+	}
+	defer f.Close()
+	rows, err := tabular.Read(f, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+	res, err := h.svc.Import(c.Request.Context(), rows, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if !dryRun && res.Invalid > 0 {
+		status = http.StatusUnprocessableEntity // tidak ada yang disimpan
+	}
+	c.JSON(status, res)
+}
+
+// GET /public-holidays/shifts?month=YYYY-MM (&team=mine | &team_id=)
+// shift di hari libur per agent untuk perhitungan premi
+func (h *PublicHolidayHandler) Shifts(c *gin.Context) {
+	t, err := time.ParseInLocation("2006-01", c.DefaultQuery("month", clock.Now().Format("2006-01")), clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+		return
+	}
+	scope, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+	rep, err := h.svc.ShiftReport(t, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
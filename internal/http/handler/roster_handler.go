@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RosterHandler struct {
+	svc   *service.RosterService
+	teams *service.TeamService
+}
+
+func NewRosterHandler(s *service.RosterService, teams *service.TeamService) *RosterHandler {
+	return &RosterHandler{svc: s, teams: teams}
+}
+
+func rosterDraftJSON(d *domain.RosterDraft) gin.H {
+	return gin.H{
+		"id": d.ID, "month": d.Month.Format("2006-01"), "team_id": d.TeamID, "status": d.Status,
+		"unfilled": d.Unfilled, "created_by": d.CreatedBy, "created_at": d.CreatedAt,
+		"applied_at": d.AppliedAt, "applied_by": d.AppliedBy,
+	}
+}
+
+func rosterItemsJSON(items []domain.RosterDraftItem) []gin.H {
+	out := make([]gin.H, 0, len(items))
+	for _, it := range items {
+		out = append(out, gin.H{
+			"id": it.ID, "user_id": it.UserID, "start_at": it.StartAt, "end_at": it.EndAt,
+			"channel": it.Channel, "shift_name": it.ShiftName, "shift_template_id": it.ShiftTemplateID,
+		})
+	}
+	return out
+}
+
+type generateRosterReq struct {
+	Month       string `json:"month" binding:"required"` // YYYY-MM
+	TemplateIDs []uint `json:"template_ids"`             // kosong = semua template aktif
+}
+
+// POST /schedules/roster/generate (&team=mine | &team_id=)
+func (h *RosterHandler) Generate(c *gin.Context) {
+	var req generateRosterReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	month, err := time.ParseInLocation("2006-01", req.Month, clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month (YYYY-MM)"})
+		return
+	}
+	scope, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+	in := service.GenerateRosterInput{Month: month, Scope: scope, TemplateIDs: req.TemplateIDs}
+	if v, err := strconv.ParseUint(c.Query("team_id"), 10, 64); err == nil && v > 0 {
+		id := uint(v)
+		in.TeamID = &id
+	}
+	res, err := h.svc.Generate(c.Request.Context(), in)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"draft": rosterDraftJSON(res.Draft), "items": rosterItemsJSON(res.Draft.Items),
+		"gaps": res.Gaps, "fairness": res.Fairness,
+	})
+}
+
+// GET /roster-drafts?month=YYYY-MM
+func (h *RosterHandler) List(c *gin.Context) {
+	var month *time.Time
+	if v := c.Query("month"); v != "" {
+		t, err := time.ParseInLocation("2006-01", v, clock.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month (YYYY-MM)"})
+			return
+		}
+		month = &t
+	}
+	items, err := h.svc.ListDrafts(month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, rosterDraftJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+// GET /roster-drafts/:id
+func (h *RosterHandler) Get(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	d, err := h.svc.GetDraft(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "draft not found"})
+		return
+	}
+	violations, err := h.svc.DraftLaborViolations(d)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"draft": rosterDraftJSON(d), "items": rosterItemsJSON(d.Items), "fairness": h.svc.Fairness(d),
+		"labor_violations": violations,
+	})
+}
+
+// DELETE /roster-drafts/:id/items/:itemId
+func (h *RosterHandler) RemoveItem(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	itemID, _ := strconv.Atoi(c.Param("itemId"))
+	if err := h.svc.RemoveItem(c.Request.Context(), uint(id), uint(itemID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// POST /roster-drafts/:id/apply
+func (h *RosterHandler) Apply(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	n, err := h.svc.Apply(c.Request.Context(), uint(id))
+	switch {
+	case errors.Is(err, repository.ErrScheduleOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		writeServiceError(c, err)
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": domain.RosterDraftApplied, "created": n})
+	}
+}
+
+// POST /roster-drafts/:id/discard
+func (h *RosterHandler) Discard(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.svc.Discard(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": domain.RosterDraftDiscarded})
+}
+
+type cloneSchedulesReq struct {
+	Period        service.ClonePeriod `json:"period" binding:"required"` // WEEK | MONTH
+	Source        string              `json:"source" binding:"required"` // YYYY-MM-DD (minggu) / YYYY-MM (bulan)
+	Target        string              `json:"target" binding:"required"`
+	UserIDs       []uint              `json:"user_ids"`
+	Channel       *domain.WorkChannel `json:"channel"`
+	SkipConflicts bool                `json:"skip_conflicts"`
+}
+
+// parseClonePeriod: bulan boleh "YYYY-MM" atau tanggal mana pun di bulan itu.
+func parseClonePeriod(p service.ClonePeriod, v string) (time.Time, error) {
+	if p == service.CloneMonth {
+		if t, err := time.ParseInLocation("2006-01", v, clock.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.ParseInLocation("2006-01-02", v, clock.Location())
+}
+
+// POST /schedules/clone?dry_run=true (&team=mine | &team_id=)
+// dry_run → preview + laporan bentrok / cuti tanpa menyimpan.
+// Ada bentrok tanpa skip_conflicts → 409 berisi laporan yang sama.
+func (h *RosterHandler) Clone(c *gin.Context) {
+	var req cloneSchedulesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	src, err := parseClonePeriod(req.Period, req.Source)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid source"})
+		return
+	}
+	dst, err := parseClonePeriod(req.Period, req.Target)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target"})
+		return
+	}
+	scope, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+	res, err := h.svc.CloneSchedules(c.Request.Context(), service.CloneScheduleInput{
+		Period: req.Period, Source: src, Target: dst, UserIDs: req.UserIDs, Channel: req.Channel,
+		Scope: scope, SkipConflicts: req.SkipConflicts, DryRun: c.Query("dry_run") == "true",
+	})
+	switch {
+	case errors.Is(err, service.ErrCloneConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "result": res})
+		return
+	case errors.As(err, new(*service.LaborRuleError)):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "violations": res.LaborViolations, "result": res})
+		return
+	case errors.Is(err, repository.ErrScheduleOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if !res.DryRun && res.Created > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, res)
+}
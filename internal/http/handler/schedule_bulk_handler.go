@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ids eksplisit dan/atau rentang tanggal mulai (inklusif) + filter opsional
+type bulkFilterReq struct {
+	IDs       []uint              `json:"ids"`
+	From      string              `json:"from"` // YYYY-MM-DD
+	To        string              `json:"to"`   // YYYY-MM-DD (inklusif)
+	UserIDs   []uint              `json:"user_ids"`
+	Channel   *domain.WorkChannel `json:"channel"`
+	ShiftName *string             `json:"shift_name"`
+}
+
+type bulkChangeReq struct {
+	ShiftMinutes int                 `json:"shift_minutes"`
+	Channel      *domain.WorkChannel `json:"channel"`
+	ShiftName    *string             `json:"shift_name"`
+}
+
+type bulkUpdateReq struct {
+	Filter bulkFilterReq `json:"filter"`
+	Change bulkChangeReq `json:"change"`
+	Reason string        `json:"reason"`
+}
+
+type bulkDeleteReq struct {
+	Filter bulkFilterReq `json:"filter"`
+	Reason string        `json:"reason"`
+}
+
+// bulkFilter mengubah request ke filter service; ok=false → respon sudah ditulis.
+func (h *ScheduleHandler) bulkFilter(c *gin.Context, req bulkFilterReq) (service.BulkScheduleFilter, bool) {
+	f := service.BulkScheduleFilter{IDs: req.IDs, UserIDs: req.UserIDs, Channel: req.Channel, ShiftName: req.ShiftName}
+	if req.From != "" || req.To != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.From, clock.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return f, false
+		}
+		to, err := time.ParseInLocation("2006-01-02", req.To, clock.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return f, false
+		}
+		to = to.AddDate(0, 0, 1)
+		f.From, f.To = &from, &to
+	}
+	scope, ok := teamScope(c, h.teams)
+	if !ok {
+		return f, false
+	}
+	f.Scope = scope
+	return f, true
+}
+
+// POST /schedules/bulk-update?dry_run=true (&team=mine | &team_id=)
+// dry_run → daftar jadwal yang kena + kondisi sesudahnya + pelanggaran aturan
+// jam kerja, tanpa menyimpan. Ada bentrok → 409 berisi laporan yang sama;
+// melanggar aturan jam kerja → 422. Tidak ada yang diubah.
+func (h *ScheduleHandler) BulkUpdate(c *gin.Context) {
+	var req bulkUpdateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, ok := h.bulkFilter(c, req.Filter)
+	if !ok {
+		return
+	}
+	ctx := service.WithScheduleChange(c.Request.Context(), domain.ScheduleSourceManual, req.Reason)
+	res, err := h.svc.BulkUpdate(ctx, f, service.BulkScheduleChange{
+		ShiftMinutes: req.Change.ShiftMinutes, Channel: req.Change.Channel, ShiftName: req.Change.ShiftName,
+	}, c.Query("dry_run") == "true")
+	switch {
+	case errors.Is(err, service.ErrBulkConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "result": res})
+		return
+	case err != nil:
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// POST /schedules/bulk-delete?dry_run=true (&team=mine | &team_id=)
+func (h *ScheduleHandler) BulkDelete(c *gin.Context) {
+	var req bulkDeleteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, ok := h.bulkFilter(c, req.Filter)
+	if !ok {
+		return
+	}
+	ctx := service.WithScheduleChange(c.Request.Context(), domain.ScheduleSourceManual, req.Reason)
+	res, err := h.svc.BulkDelete(ctx, f, c.Query("dry_run") == "true")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
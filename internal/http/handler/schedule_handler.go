@@ -1,34 +1,45 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/repository"
 	"bjb-backoffice/internal/service"
+	"bjb-backoffice/internal/tabular"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type ScheduleHandler struct {
 	svc   *service.ScheduleService
 	users repository.UserRepository // <-- untuk ambil full_name
+	teams *service.TeamService
+	shift *service.ShiftService
+	// kalender hari libur untuk respon bulanan
+	holidays *service.PublicHolidayService
 }
 
-func NewScheduleHandler(s *service.ScheduleService, ur repository.UserRepository) *ScheduleHandler {
-	return &ScheduleHandler{svc: s, users: ur}
+func NewScheduleHandler(s *service.ScheduleService, ur repository.UserRepository, teams *service.TeamService, shift *service.ShiftService, holidays *service.PublicHolidayService) *ScheduleHandler {
+	return &ScheduleHandler{svc: s, users: ur, teams: teams, shift: shift, holidays: holidays}
 }
 
+// Jadwal dibuat dari start_at/end_at/channel, ATAU dari shift_template_id + date.
 type createScheduleReq struct {
-	UserID    uint               `json:"user_id" binding:"required"`
-	StartAt   string             `json:"start_at" binding:"required"` // RFC3339
-	EndAt     string             `json:"end_at" binding:"required"`   // RFC3339
-	Channel   domain.WorkChannel `json:"channel" binding:"required"`  // "VOICE" | "SOSMED"
-	ShiftName *string            `json:"shift_name"`
-	Notes     *string            `json:"notes"`
+	UserID          uint               `json:"user_id" binding:"required"`
+	StartAt         string             `json:"start_at"` // RFC3339
+	EndAt           string             `json:"end_at"`   // RFC3339
+	Channel         domain.WorkChannel `json:"channel"`  // "VOICE" | "SOSMED"
+	ShiftName       *string            `json:"shift_name"`
+	Notes           *string            `json:"notes"`
+	ShiftTemplateID *uint              `json:"shift_template_id"`
+	Date            string             `json:"date"`   // YYYY-MM-DD, dipakai bersama shift_template_id
+	Reason          string             `json:"reason"` // opsional, dicatat di riwayat jadwal
 }
 
 func (h *ScheduleHandler) Create(c *gin.Context) {
@@ -37,21 +48,36 @@ func (h *ScheduleHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	st, err := time.Parse(time.RFC3339, req.StartAt)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_at"})
-		return
-	}
-	en, err := time.Parse(time.RFC3339, req.EndAt)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_at"})
-		return
+	var in service.CreateScheduleInput
+	if req.ShiftTemplateID != nil {
+		day, err := time.ParseInLocation("2006-01-02", req.Date, clock.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+			return
+		}
+		if in, err = h.shift.ScheduleInputFromTemplate(*req.ShiftTemplateID, req.UserID, day, req.Notes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		st, err := time.Parse(time.RFC3339, req.StartAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_at"})
+			return
+		}
+		en, err := time.Parse(time.RFC3339, req.EndAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_at"})
+			return
+		}
+		in = service.CreateScheduleInput{
+			UserID: req.UserID, StartAt: st, EndAt: en, Channel: req.Channel, ShiftName: req.ShiftName, Notes: req.Notes,
+		}
 	}
-	m, err := h.svc.Create(service.CreateScheduleInput{
-		UserID: req.UserID, StartAt: st, EndAt: en, Channel: req.Channel, ShiftName: req.ShiftName, Notes: req.Notes,
-	})
+	ctx := service.WithScheduleChange(c.Request.Context(), domain.ScheduleSourceManual, req.Reason)
+	m, err := h.svc.Create(ctx, in)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"id": m.ID})
@@ -64,6 +90,7 @@ type updateScheduleReq struct {
 	Channel   *domain.WorkChannel `json:"channel"`
 	ShiftName *string             `json:"shift_name"`
 	Notes     *string             `json:"notes"`
+	Reason    string              `json:"reason"`
 }
 
 func (h *ScheduleHandler) Update(c *gin.Context) {
@@ -107,8 +134,9 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 			return
 		}
 	}
-	if err := h.svc.UpdateSchedule(sch); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	ctx := service.WithScheduleChange(c.Request.Context(), domain.ScheduleSourceManual, req.Reason)
+	if err := h.svc.UpdateSchedule(ctx, sch); err != nil {
+		writeServiceError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "updated"})
@@ -116,7 +144,8 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 
 func (h *ScheduleHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.svc.Delete(uint(id)); err != nil {
+	ctx := service.WithScheduleChange(c.Request.Context(), domain.ScheduleSourceManual, c.Query("reason"))
+	if err := h.svc.Delete(ctx, uint(id)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -125,8 +154,8 @@ func (h *ScheduleHandler) Delete(c *gin.Context) {
 
 // GET /schedules/monthly — RBAC: Agent hanya miliknya; backoffice bisa pilih ?user_id atau semua
 func (h *ScheduleHandler) ListMonthly(c *gin.Context) {
-	monthStr := c.DefaultQuery("month", time.Now().Format("2006-01"))
-	t, err := time.Parse("2006-01", monthStr)
+	monthStr := c.DefaultQuery("month", clock.Now().Format("2006-01"))
+	t, err := time.ParseInLocation("2006-01", monthStr, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
 		return
@@ -141,30 +170,14 @@ func (h *ScheduleHandler) ListMonthly(c *gin.Context) {
 		}
 	}
 
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	isAgent := false
-	self := uint(0)
-	if idf, ok := claims["sub"].(float64); ok {
-		self = uint(idf)
-	}
-	switch rs := claims["roles"].(type) {
-	case []interface{}:
-		for _, r := range rs {
-			if s, ok := r.(string); ok && s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
-	case []string:
-		for _, s := range rs {
-			if s == "AGENT" {
-				isAgent = true
-				break
-			}
-		}
+	me, ok := principal(c)
+	if !ok {
+		return
 	}
-	if isAgent {
+	self := me.ID
+	// tanpa schedule:read_all → hanya boleh lihat miliknya
+	ownOnly := !middleware.Can(c, domain.PermScheduleReadAll)
+	if ownOnly {
 		if userID != nil && *userID != self {
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 			return
@@ -174,56 +187,172 @@ func (h *ScheduleHandler) ListMonthly(c *gin.Context) {
 		}
 	}
 
-	items, err := h.svc.ListMonthly(userID, t)
+	items, err := h.svc.ListVisibleMonthly(userID, t, canSeeDrafts(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	holidays, isHoliday, ok := h.monthHolidays(c, t)
+	if !ok {
+		return
+	}
 	out := make([]gin.H, 0, len(items))
 	for _, it := range items {
+		bd := it.BusinessDate(clock.Location()).Format("2006-01-02")
 		out = append(out, gin.H{
 			"id": it.ID, "user_id": it.UserID,
 			"start_at": it.StartAt, "end_at": it.EndAt,
 			"channel":    it.Channel,
 			"shift_name": it.ShiftName, "notes": it.Notes,
+			"status":        it.Status,
+			"business_date": bd,
+			"overnight":     it.Overnight(clock.Location()),
+			"holiday":       isHoliday[bd], // shift hari libur (premi)
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"month": monthStr, "items": out})
+	c.JSON(http.StatusOK, gin.H{"month": monthStr, "holidays": holidays, "items": out})
+}
+
+// monthHolidays = hari libur bulan t beserta set tanggalnya (YYYY-MM-DD)
+// untuk flag "holiday" per shift; false = respon error sudah ditulis.
+func (h *ScheduleHandler) monthHolidays(c *gin.Context, t time.Time) ([]service.HolidayDay, map[string]bool, bool) {
+	holidays, err := h.holidays.Month(t)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	set := make(map[string]bool, len(holidays))
+	for _, d := range holidays {
+		set[d.Date] = true
+	}
+	return holidays, set, true
 }
 
 // GET /schedules/monthly-all — semua user untuk matrix; tambahkan user_full_name
 func (h *ScheduleHandler) ListMonthlyAll(c *gin.Context) {
-	monthStr := c.DefaultQuery("month", time.Now().Format("2006-01"))
-	t, err := time.Parse("2006-01", monthStr)
+	monthStr := c.DefaultQuery("month", clock.Now().Format("2006-01"))
+	t, err := time.ParseInLocation("2006-01", monthStr, clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
 		return
 	}
 
-	items, err := h.svc.ListMonthly(nil, t) // nil => semua user
+	// filter tim: ?team=mine (TL/SPV) atau ?team_id=
+	teamIDs, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListVisibleMonthly(nil, t, canSeeDrafts(c)) // nil => semua user
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	var inTeam map[uint]bool
+	if teamIDs != nil {
+		inTeam = make(map[uint]bool, len(teamIDs))
+		for _, id := range teamIDs {
+			inTeam[id] = true
+		}
+	}
 
-	// ambil nama user → map[id]full_name
-	users, _, _ := h.users.List(1, 5000)
-	names := make(map[uint]string, len(users))
-	for _, u := range users {
-		names[u.ID] = u.FullName
+	// nama user yang punya jadwal (termasuk nonaktif/arsip: roster bulan lalu tetap utuh)
+	ids := make([]uint, 0, len(items))
+	seen := map[uint]bool{}
+	for _, it := range items {
+		if !seen[it.UserID] {
+			seen[it.UserID] = true
+			ids = append(ids, it.UserID)
+		}
+	}
+	names, err := h.users.NamesByIDs(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
+	holidays, isHoliday, ok := h.monthHolidays(c, t)
+	if !ok {
+		return
+	}
 	out := make([]gin.H, 0, len(items))
 	for _, it := range items {
+		bd := it.BusinessDate(clock.Location()).Format("2006-01-02")
+		if inTeam != nil && !inTeam[it.UserID] {
+			continue
+		}
 		out = append(out, gin.H{
 			"id": it.ID, "user_id": it.UserID,
 			"user_full_name": names[it.UserID],
 			"start_at":       it.StartAt, "end_at": it.EndAt,
 			"channel":    it.Channel,
 			"shift_name": it.ShiftName, "notes": it.Notes,
+			"status":        it.Status,
+			"business_date": bd,
+			"overnight":     it.Overnight(clock.Location()),
+			"holiday":       isHoliday[bd], // shift hari libur (premi)
 		})
 	}
-	c.JSON(http.StatusOK, gin.H{"month": monthStr, "items": out})
+	c.JSON(http.StatusOK, gin.H{"month": monthStr, "holidays": holidays, "items": out})
+}
+
+// GET /schedules/monthly-all/export?month=YYYY-MM&format=xlsx|csv|pdf (&team=mine | &team_id=)
+// matriks agent × hari seperti halaman ScheduleMatrix.
+func (h *ScheduleHandler) ExportMonthly(c *gin.Context) {
+	monthStr := c.DefaultQuery("month", clock.Now().Format("2006-01"))
+	t, err := time.ParseInLocation("2006-01", monthStr, clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+		return
+	}
+	format, err := tabular.FormatFromName(c.DefaultQuery("format", "xlsx"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format harus xlsx, csv atau pdf"})
+		return
+	}
+	teamIDs, ok := teamScope(c, h.teams)
+	if !ok {
+		return
+	}
+	sheet, err := h.svc.MonthlyRoster(t, teamIDs, format == tabular.FormatPDF)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="roster-%s.%s"`, monthStr, format))
+	if err := tabular.WriteSheet(c.Writer, format, sheet); err != nil {
+		c.Error(err)
+	}
+}
+
+// canSeeDrafts: jadwal DRAFT hanya untuk penyusun / pemantau jadwal.
+func canSeeDrafts(c *gin.Context) bool {
+	return middleware.Can(c, domain.PermScheduleWrite) || middleware.Can(c, domain.PermScheduleReadAll)
+}
+
+type publishMonthReq struct {
+	Month string `json:"month" binding:"required"` // YYYY-MM
+}
+
+// POST /schedules/publish — publish seluruh jadwal DRAFT satu bulan.
+func (h *ScheduleHandler) PublishMonth(c *gin.Context) {
+	var req publishMonthReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, err := time.ParseInLocation("2006-01", req.Month, clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+		return
+	}
+	res, err := h.svc.PublishMonth(c.Request.Context(), t)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
 }
 
 func (h *ScheduleHandler) OffDays(c *gin.Context) {
@@ -233,38 +362,15 @@ func (h *ScheduleHandler) OffDays(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
-	monthStr := c.DefaultQuery("month", time.Now().Format("2006-01"))
-	t, err := time.Parse("2006-01", monthStr)
+	t, err := time.ParseInLocation("2006-01", c.DefaultQuery("month", clock.Now().Format("2006-01")), clock.Location())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
 		return
 	}
-
-	// Ambil semua jadwal user tsb di bulan itu
-	u := uint(uid64)
-	items, err := h.svc.ListMonthly(&u, t)
+	res, err := h.svc.OffDays(uint(uid64), t, canSeeDrafts(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// tandai hari yang busy
-	startMonth := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
-	days := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
-	busy := map[string]bool{}
-	for _, it := range items {
-		d := time.Date(it.StartAt.Year(), it.StartAt.Month(), it.StartAt.Day(), 0, 0, 0, 0, time.Local)
-		key := d.Format("2006-01-02")
-		busy[key] = true
-	}
-	// susun off list (hari yang tidak busy)
-	off := []string{}
-	for i := 0; i < days; i++ {
-		d := startMonth.AddDate(0, 0, i)
-		key := d.Format("2006-01-02")
-		if !busy[key] {
-			off = append(off, key)
-		}
-	}
-	c.JSON(http.StatusOK, gin.H{"user_id": u, "month": monthStr, "off_dates": off})
+	c.JSON(http.StatusOK, res)
 }
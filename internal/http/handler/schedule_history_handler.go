@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func scheduleVersionItem(v domain.ScheduleVersion) gin.H {
+	return gin.H{
+		"id": v.ID, "schedule_id": v.ScheduleID, "op": v.Op, "source": v.Source, "reason": v.Reason,
+		"actor_id": v.ActorID, "created_at": v.CreatedAt,
+		"user_id": v.UserID, "start_at": v.StartAt, "end_at": v.EndAt, "channel": v.Channel,
+		"shift_name": v.ShiftName, "notes": v.Notes, "status": v.Status,
+	}
+}
+
+// GET /schedules/:id/history — semua versi jadwal (termasuk yang sudah dihapus).
+func (h *ScheduleHandler) History(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	items, err := h.svc.History(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for _, v := range items {
+		out = append(out, scheduleVersionItem(v))
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule_id": id, "items": out})
+}
+
+// GET /schedules/changes?month=YYYY-MM&from=RFC3339&to=RFC3339
+// apa yang berubah di roster bulan itu sejak from (default: 7 hari lalu) sampai to (default: sekarang).
+func (h *ScheduleHandler) Changes(c *gin.Context) {
+	month, err := time.ParseInLocation("2006-01", c.Query("month"), clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+		return
+	}
+	to := time.Now()
+	if q := c.Query("to"); q != "" {
+		if to, err = time.Parse(time.RFC3339, q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+	}
+	from := to.AddDate(0, 0, -7)
+	if q := c.Query("from"); q != "" {
+		if from, err = time.Parse(time.RFC3339, q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+	}
+	diff, err := h.svc.MonthDiff(month, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+type restoreScheduleReq struct {
+	VersionID *uint  `json:"version_id"` // kosong = kondisi sebelum perubahan terakhir
+	Reason    string `json:"reason"`
+}
+
+// POST /schedules/:id/restore
+func (h *ScheduleHandler) Restore(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req restoreScheduleReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	m, err := h.svc.Restore(c.Request.Context(), uint(id), req.VersionID, req.Reason)
+	if err != nil {
+		writeServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id": m.ID, "user_id": m.UserID, "start_at": m.StartAt, "end_at": m.EndAt,
+		"channel": m.Channel, "shift_name": m.ShiftName, "notes": m.Notes, "status": m.Status,
+	})
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/repository"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityHandler struct{ guard *service.LoginGuardService }
+
+func NewSecurityHandler(g *service.LoginGuardService) *SecurityHandler {
+	return &SecurityHandler{guard: g}
+}
+
+// GET /security/locked-accounts
+func (h *SecurityHandler) ListLocked(c *gin.Context) {
+	rows, err := h.guard.ListLocked()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(rows))
+	for _, l := range rows {
+		out = append(out, gin.H{
+			"email": l.Email, "failed_count": l.FailedCount,
+			"last_failed_at": l.LastFailedAt, "locked_until": l.LockedUntil,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+type unlockReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// POST /security/locked-accounts/unlock
+func (h *SecurityHandler) Unlock(c *gin.Context) {
+	var req unlockReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.guard.Unlock(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "unlocked"})
+}
+
+// GET /security/login-attempts?email=&ip=&success=&from=&to=&page=&size=
+func (h *SecurityHandler) ListAttempts(c *gin.Context) {
+	q := c.Request.URL.Query()
+	f := repository.LoginAttemptFilter{Email: q.Get("email"), IP: q.Get("ip")}
+	if v := q.Get("success"); v != "" {
+		b := v == "true"
+		f.Success = &b
+	}
+	if v := q.Get("from"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			f.From = &t
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			f.To = &t
+		}
+	}
+	f.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	f.Size, _ = strconv.Atoi(c.DefaultQuery("size", "50"))
+
+	rows, total, err := h.guard.ListAttempts(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(rows))
+	for _, a := range rows {
+		out = append(out, gin.H{
+			"id": a.ID, "email": a.Email, "ip": a.IP, "user_id": a.UserID,
+			"success": a.Success, "reason": a.Reason, "user_agent": a.UserAgent, "created_at": a.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"page": f.Page, "size": f.Size, "total": total, "items": out})
+}
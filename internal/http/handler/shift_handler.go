@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+	"bjb-backoffice/internal/tabular"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShiftHandler struct {
+	svc *service.ShiftService
+}
+
+func NewShiftHandler(s *service.ShiftService) *ShiftHandler { return &ShiftHandler{svc: s} }
+
+type shiftTemplateReq struct {
+	Name      string             `json:"name"`
+	Code      *string            `json:"code"`       // kode di roster spreadsheet
+	StartTime string             `json:"start_time"` // HH:MM
+	EndTime   string             `json:"end_time"`   // HH:MM; <= start_time = lewat tengah malam
+	Channel   domain.WorkChannel `json:"channel"`
+	Active    *bool              `json:"active"`
+}
+
+func (r shiftTemplateReq) input() service.ShiftTemplateInput {
+	return service.ShiftTemplateInput{Name: r.Name, Code: r.Code, StartTime: r.StartTime, EndTime: r.EndTime, Channel: r.Channel, Active: r.Active}
+}
+
+func shiftTemplateJSON(t *domain.ShiftTemplate) gin.H {
+	return gin.H{
+		"id": t.ID, "name": t.Name, "code": t.Code, "start_time": t.StartTime, "end_time": t.EndTime,
+		"channel": t.Channel, "active": t.Active,
+	}
+}
+
+// GET /shift-templates?active=true
+func (h *ShiftHandler) ListTemplates(c *gin.Context) {
+	items, err := h.svc.ListTemplates(c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, shiftTemplateJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+func (h *ShiftHandler) CreateTemplate(c *gin.Context) {
+	var req shiftTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, err := h.svc.CreateTemplate(c.Request.Context(), req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, shiftTemplateJSON(t))
+}
+
+func (h *ShiftHandler) UpdateTemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req shiftTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, err := h.svc.UpdateTemplate(c.Request.Context(), uint(id), req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shiftTemplateJSON(t))
+}
+
+func (h *ShiftHandler) DeleteTemplate(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.svc.DeleteTemplate(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+type rotationPatternReq struct {
+	Name   string `json:"name"`
+	Weeks  int    `json:"weeks"`
+	Active *bool  `json:"active"`
+	// index 0 = Senin minggu pertama; hari yang tidak disebut = OFF
+	Days []struct {
+		DayIndex        int  `json:"day_index"`
+		ShiftTemplateID uint `json:"shift_template_id"`
+	} `json:"days"`
+}
+
+func (r rotationPatternReq) input() service.RotationPatternInput {
+	in := service.RotationPatternInput{Name: r.Name, Weeks: r.Weeks, Active: r.Active}
+	if r.Days != nil {
+		in.Days = make([]service.RotationDayInput, 0, len(r.Days))
+		for _, d := range r.Days {
+			in.Days = append(in.Days, service.RotationDayInput{DayIndex: d.DayIndex, ShiftTemplateID: d.ShiftTemplateID})
+		}
+	}
+	return in
+}
+
+func rotationPatternJSON(p *domain.RotationPattern) gin.H {
+	days := make([]gin.H, 0, len(p.Slots))
+	for i := range p.Slots {
+		sl := &p.Slots[i]
+		days = append(days, gin.H{
+			"day_index": sl.DayIndex, "shift_template_id": sl.ShiftTemplateID,
+			"shift_name": sl.ShiftTemplate.Name,
+		})
+	}
+	return gin.H{"id": p.ID, "name": p.Name, "weeks": p.Weeks, "active": p.Active, "days": days}
+}
+
+// GET /rotation-patterns
+func (h *ShiftHandler) ListPatterns(c *gin.Context) {
+	items, err := h.svc.ListPatterns()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, rotationPatternJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+func (h *ShiftHandler) GetPattern(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	p, err := h.svc.GetPattern(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rotation pattern not found"})
+		return
+	}
+	c.JSON(http.StatusOK, rotationPatternJSON(p))
+}
+
+func (h *ShiftHandler) CreatePattern(c *gin.Context) {
+	var req rotationPatternReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.CreatePattern(c.Request.Context(), req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, rotationPatternJSON(p))
+}
+
+func (h *ShiftHandler) UpdatePattern(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req rotationPatternReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.UpdatePattern(c.Request.Context(), uint(id), req.input())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rotationPatternJSON(p))
+}
+
+func (h *ShiftHandler) DeletePattern(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.svc.DeletePattern(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+type applyRotationReq struct {
+	PatternID     uint   `json:"pattern_id" binding:"required"`
+	UserIDs       []uint `json:"user_ids" binding:"required"`
+	From          string `json:"from" binding:"required"` // YYYY-MM-DD
+	To            string `json:"to" binding:"required"`   // YYYY-MM-DD (inklusif)
+	StaggerWeeks  int    `json:"stagger_weeks"`
+	SkipConflicts bool   `json:"skip_conflicts"`
+}
+
+// POST /schedules/apply-rotation?dry_run=true
+// dry_run → preview + laporan bentrok per hari tanpa menyimpan.
+// Ada bentrok tanpa skip_conflicts → 409 berisi laporan yang sama.
+func (h *ShiftHandler) ApplyRotation(c *gin.Context) {
+	var req applyRotationReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err := time.ParseInLocation("2006-01-02", req.From, clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", req.To, clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	res, err := h.svc.ApplyRotation(c.Request.Context(), service.ApplyRotationInput{
+		PatternID: req.PatternID, UserIDs: req.UserIDs, From: from, To: to,
+		StaggerWeeks: req.StaggerWeeks, SkipConflicts: req.SkipConflicts,
+		DryRun: c.Query("dry_run") == "true",
+	})
+	switch {
+	case errors.Is(err, service.ErrRotationConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "result": res})
+		return
+	case errors.As(err, new(*service.LaborRuleError)):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "violations": res.LaborViolations, "result": res})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if !res.DryRun && res.Created > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, res)
+}
+
+// POST /schedules/roster/import?month=YYYY-MM&mode=merge|replace&dry_run=true
+// multipart field "file" (.csv / .xlsx): baris = agent (kolom email), kolom = tanggal.
+func (h *ShiftHandler) ImportRoster(c *gin.Context) {
+	month, err := time.ParseInLocation("2006-01", c.Query("month"), clock.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month (YYYY-MM)"})
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if file.Size > 5*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file too large (max 5MB)"})
+		return
+	}
+	format, err := tabular.FormatFromName(file.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file"})
+		return
+	}
+	defer f.Close()
+	rows, err := tabular.Read(f, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+	mode := service.RosterMode(c.DefaultQuery("mode", string(service.RosterMerge)))
+	res, err := h.svc.ImportRoster(c.Request.Context(), rows, month, mode, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if !dryRun && res.Invalid > 0 {
+		status = http.StatusUnprocessableEntity // tidak ada yang disimpan
+	} else if res.Created > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, res)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StaffingHandler struct {
+	svc *service.StaffingService
+}
+
+func NewStaffingHandler(s *service.StaffingService) *StaffingHandler { return &StaffingHandler{svc: s} }
+
+func staffingRuleJSON(m *domain.StaffingRule) gin.H {
+	return gin.H{
+		"id": m.ID, "channel": m.Channel, "min_headcount": m.MinHeadcount,
+		"mode": m.Mode, "active": m.Active, "updated_at": m.UpdatedAt,
+	}
+}
+
+// GET /staffing/rules
+func (h *StaffingHandler) List(c *gin.Context) {
+	items, err := h.svc.ListRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, staffingRuleJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+type staffingRuleReq struct {
+	MinHeadcount int                 `json:"min_headcount" binding:"required"`
+	Mode         domain.StaffingMode `json:"mode"` // WARN (default) | BLOCK
+	Active       *bool               `json:"active"`
+}
+
+// PUT /staffing/rules/:channel
+func (h *StaffingHandler) Set(c *gin.Context) {
+	var req staffingRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	m, err := h.svc.SetRule(c.Request.Context(), domain.WorkChannel(c.Param("channel")), service.StaffingRuleInput{
+		MinHeadcount: req.MinHeadcount, Mode: req.Mode, Active: req.Active,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, staffingRuleJSON(m))
+}
+
+// DELETE /staffing/rules/:channel
+func (h *StaffingHandler) Delete(c *gin.Context) {
+	if err := h.svc.DeleteRule(c.Request.Context(), domain.WorkChannel(c.Param("channel"))); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// overrideRequested: ?override=true (atau field form override=true).
+func overrideRequested(c *gin.Context) bool {
+	return c.Query("override") == "true" || c.PostForm("override") == "true"
+}
+
+// writeServiceError: pelanggaran minimum staffing → 409 beserta headcount-nya,
+// pelanggaran aturan jam kerja → 422 beserta daftar pelanggarannya,
+// error lain → 400.
+func writeServiceError(c *gin.Context, err error) {
+	var blocked *service.StaffingBlockedError
+	if errors.As(err, &blocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "staffing": blocked.Check})
+		return
+	}
+	var labor *service.LaborRuleError
+	if errors.As(err, &labor) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "violations": labor.Violations})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
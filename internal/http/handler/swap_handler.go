@@ -4,13 +4,13 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type SwapHandler struct {
@@ -30,10 +30,11 @@ type createSwapReq struct {
 }
 
 func (h *SwapHandler) Create(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	requester := uint(idf)
+	me, ok := principal(c)
+	if !ok {
+		return
+	}
+	requester := me.ID
 
 	var req createSwapReq
 	if err := c.ShouldBindJSON(&req); err != nil || req.StartAt == "" {
@@ -42,7 +43,7 @@ func (h *SwapHandler) Create(c *gin.Context) {
 		return
 	}
 
-	m, e := h.svc.CreateFromRFC3339(requester, req.StartAt, req.Reason, req.TargetUserID)
+	m, e := h.svc.CreateFromRFC3339(c.Request.Context(), req.StartAt, req.Reason, req.TargetUserID)
 	if e != nil {
 		log.Printf("[swap-create] failed uid=%d start_at=%s reason=%q target=%v err=%v",
 			requester, req.StartAt, req.Reason, req.TargetUserID, e)
@@ -67,10 +68,11 @@ type acceptSwapReq struct {
 }
 
 func (h *SwapHandler) Accept(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
+	p, ok := principal(c)
+	if !ok {
+		return
+	}
+	me := p.ID
 
 	id, _ := strconv.Atoi(c.Param("id"))
 
@@ -81,16 +83,16 @@ func (h *SwapHandler) Accept(c *gin.Context) {
 		return
 	}
 
-	m, err := h.svc.Accept(uint(id), me, body.CounterpartyScheduleID)
+	m, check, err := h.svc.Accept(c.Request.Context(), uint(id), body.CounterpartyScheduleID, overrideRequested(c))
 	if err != nil {
 		log.Printf("[swap-accept] failed swapID=%d by uid=%d cpt_sch_id=%d err=%v",
 			id, me, body.CounterpartyScheduleID, err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeServiceError(c, err)
 		return
 	}
 
 	log.Printf("[swap-accept] ok swapID=%d by uid=%d status=%s", m.ID, me, m.Status)
-	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status})
+	c.JSON(http.StatusOK, gin.H{"id": m.ID, "status": m.Status, "staffing": check})
 }
 
 // --- helpers ---
@@ -120,38 +122,34 @@ func (h *SwapHandler) List(c *gin.Context) {
 	}
 
 	// siapa yang request & apa rolenya
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	me := uint(idf)
-
-	rolesRaw, _ := claims["roles"].([]interface{})
-	isBackoffice := false
-	for _, r := range rolesRaw {
-		s, _ := r.(string)
-		s = strings.ToUpper(strings.TrimSpace(s))
-		if s == "BACKOFFICE" || s == "ADMIN" {
-			isBackoffice = true
-			break
-		}
+	p, ok := principal(c)
+	if !ok {
+		return
 	}
+	me := p.ID
 
-	// cache nama
-	nameCache := map[uint]string{}
-	getName := func(uid uint) string {
-		if uid == 0 {
-			return ""
+	isBackoffice := middleware.Can(c, domain.PermSwapReadAll)
+
+	// nama sekaligus, termasuk user yang nonaktif/diarsipkan
+	ids := make([]uint, 0, 3*len(swaps))
+	for _, s := range swaps {
+		ids = append(ids, s.RequesterID)
+		if s.CounterpartyID != nil {
+			ids = append(ids, *s.CounterpartyID)
 		}
-		if v, ok := nameCache[uid]; ok {
-			return v
+		if s.TargetUserID != nil {
+			ids = append(ids, *s.TargetUserID)
 		}
-		u, err := h.userSvc.GetByID(uid)
-		if err != nil || u == nil {
-			nameCache[uid] = ""
+	}
+	names, ok := userNames(c, h.userSvc, ids)
+	if !ok {
+		return
+	}
+	nameOf := func(uid *uint) string {
+		if uid == nil {
 			return ""
 		}
-		nameCache[uid] = u.FullName
-		return u.FullName
+		return names[*uid]
 	}
 
 	out := make([]gin.H, 0, len(swaps))
@@ -183,24 +181,20 @@ func (h *SwapHandler) List(c *gin.Context) {
 		}
 
 		out = append(out, gin.H{
-			"id":              s.ID,
-			"requester_id":    s.RequesterID,
-			"requester_name":  getName(s.RequesterID),
-			"counterparty_id": s.CounterpartyID,
-			"counterparty_name": func() string {
-				if s.CounterpartyID == nil {
-					return ""
-				}
-				return getName(*s.CounterpartyID)
-			}(),
-			"target_user_id": s.TargetUserID, // ← penting utk FE guard
-			"start_at":       s.StartAt,
-			"end_at":         s.EndAt,
-			"reason":         s.Reason,
-			"status":         s.Status,
-			"channel":        ch,
-			"created_at":     s.CreatedAt,
-			"updated_at":     s.UpdatedAt,
+			"id":                s.ID,
+			"requester_id":      s.RequesterID,
+			"requester_name":    names[s.RequesterID],
+			"counterparty_id":   s.CounterpartyID,
+			"counterparty_name": nameOf(s.CounterpartyID),
+			"target_user_id":    s.TargetUserID, // ← penting utk FE guard
+			"target_user_name":  nameOf(s.TargetUserID),
+			"start_at":          s.StartAt,
+			"end_at":            s.EndAt,
+			"reason":            s.Reason,
+			"status":            s.Status,
+			"channel":           ch,
+			"created_at":        s.CreatedAt,
+			"updated_at":        s.UpdatedAt,
 		})
 	}
 
@@ -211,13 +205,14 @@ func (h *SwapHandler) List(c *gin.Context) {
 }
 
 func (h *SwapHandler) Cancel(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, _ := claims["sub"].(float64)
-	requester := uint(idf)
+	me, ok := principal(c)
+	if !ok {
+		return
+	}
+	requester := me.ID
 
 	id, _ := strconv.Atoi(c.Param("id"))
-	m, err := h.svc.Cancel(uint(id), requester)
+	m, err := h.svc.Cancel(c.Request.Context(), uint(id))
 	if err != nil {
 		log.Printf("[swap-cancel] failed swapID=%d by uid=%d err=%v", id, requester, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
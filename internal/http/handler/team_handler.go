@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TeamHandler struct {
+	svc   *service.TeamService
+	users *service.UserService
+}
+
+func NewTeamHandler(s *service.TeamService, users *service.UserService) *TeamHandler {
+	return &TeamHandler{svc: s, users: users}
+}
+
+type teamReq struct {
+	Name         string `json:"name"`
+	LeaderID     *uint  `json:"leader_id"`     // TL
+	SupervisorID *uint  `json:"supervisor_id"` // SPV
+	Active       *bool  `json:"active"`
+}
+
+func (h *TeamHandler) teamJSON(t *domain.Team) gin.H {
+	name := func(id *uint) any {
+		if id == nil {
+			return nil
+		}
+		return h.users.DisplayName(*id)
+	}
+	return gin.H{
+		"id": t.ID, "name": t.Name, "active": t.Active,
+		"leader_id": t.LeaderID, "leader_name": name(t.LeaderID),
+		"supervisor_id": t.SupervisorID, "supervisor_name": name(t.SupervisorID),
+	}
+}
+
+// GET /teams
+func (h *TeamHandler) List(c *gin.Context) {
+	items, err := h.svc.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	out := make([]gin.H, 0, len(items))
+	for i := range items {
+		out = append(out, h.teamJSON(&items[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+// GET /teams/:id?date=YYYY-MM-DD — detail + anggota yang berlaku pada tanggal tsb
+func (h *TeamHandler) Get(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	t, err := h.svc.Get(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+		return
+	}
+	day := clock.Now()
+	if v := c.Query("date"); v != "" {
+		if day, err = time.ParseInLocation("2006-01-02", v, clock.Location()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+			return
+		}
+	}
+	members, err := h.svc.ListMembers(t.ID, day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ms := make([]gin.H, 0, len(members))
+	for _, m := range members {
+		ms = append(ms, memberJSON(m, h.users.DisplayName(m.UserID)))
+	}
+	history := make([]gin.H, 0, len(t.Members))
+	for _, m := range t.Members {
+		history = append(history, memberJSON(m, h.users.DisplayName(m.UserID)))
+	}
+	out := h.teamJSON(t)
+	out["members"] = ms
+	out["history"] = history
+	c.JSON(http.StatusOK, out)
+}
+
+func memberJSON(m domain.TeamMember, name string) gin.H {
+	var to any
+	if m.EffectiveTo != nil {
+		to = m.EffectiveTo.Format("2006-01-02")
+	}
+	return gin.H{
+		"user_id": m.UserID, "full_name": name,
+		"effective_from": m.EffectiveFrom.Format("2006-01-02"), "effective_to": to,
+	}
+}
+
+// POST /teams
+func (h *TeamHandler) Create(c *gin.Context) {
+	var req teamReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, err := h.svc.Create(c.Request.Context(), service.TeamInput{Name: req.Name, LeaderID: req.LeaderID, SupervisorID: req.SupervisorID, Active: req.Active})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, h.teamJSON(t))
+}
+
+// PUT /teams/:id — leader_id/supervisor_id = 0 untuk mengosongkan
+func (h *TeamHandler) Update(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req teamReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t, err := h.svc.Update(c.Request.Context(), uint(id), service.TeamInput{Name: req.Name, LeaderID: req.LeaderID, SupervisorID: req.SupervisorID, Active: req.Active})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.teamJSON(t))
+}
+
+// DELETE /teams/:id
+func (h *TeamHandler) Delete(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+type teamMemberReq struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Date   string `json:"date"` // YYYY-MM-DD; default hari ini
+}
+
+func (r teamMemberReq) day() (time.Time, error) {
+	if r.Date == "" {
+		return clock.Now(), nil
+	}
+	return time.ParseInLocation("2006-01-02", r.Date, clock.Location())
+}
+
+// POST /teams/:id/members — masuk tim mulai date (keanggotaan lama ditutup)
+func (h *TeamHandler) AddMember(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req teamMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	day, err := req.day()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	m, err := h.svc.AddMember(c.Request.Context(), uint(id), req.UserID, day)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, memberJSON(*m, h.users.DisplayName(m.UserID)))
+}
+
+// POST /teams/:id/members/remove — akhiri keanggotaan per date (inklusif)
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var req teamMemberReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	day, err := req.day()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+	if err := h.svc.RemoveMember(c.Request.Context(), uint(id), req.UserID, day); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "removed"})
+}
+
+// teamScope membaca ?team=mine / ?team_id= dan mengembalikan user_id anggota.
+// nil = tanpa filter tim. Bila ok=false response error sudah ditulis.
+func teamScope(c *gin.Context, teams *service.TeamService) ([]uint, bool) {
+	if teams == nil {
+		return nil, true
+	}
+	me, ok := principal(c)
+	if !ok {
+		return nil, false
+	}
+	ids, err := teams.ScopeUserIDs(me.ID, c.Query("team"), c.Query("team_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return ids, true
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -10,11 +11,13 @@ import (
 	"strings"
 	"time"
 
+	"bjb-backoffice/internal/auth"
 	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/http/middleware"
 	"bjb-backoffice/internal/service"
+	"bjb-backoffice/internal/tabular"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -23,15 +26,11 @@ type UserHandler struct{ svc *service.UserService }
 func NewUserHandler(s *service.UserService) *UserHandler { return &UserHandler{svc: s} }
 
 func (h *UserHandler) Me(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-
-	idf, ok := claims["sub"].(float64)
+	me, ok := principal(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid subject"})
 		return
 	}
-	uid := uint(idf)
+	uid := me.ID
 
 	u, err := h.svc.GetByID(uid)
 	if err != nil {
@@ -47,6 +46,7 @@ func (h *UserHandler) Me(c *gin.Context) {
 		"roles":     userRolesToStrings(u),
 		"active":    u.Active,
 		"photo_url": u.PhotoURL, // ⬅️ penting untuk Topbar & Profile
+		"team_id":   me.TeamID,
 	})
 }
 
@@ -95,7 +95,7 @@ func (h *UserHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	u, err := h.svc.CreateUser(service.CreateUserInput{
+	u, err := h.svc.CreateUser(c.Request.Context(), service.CreateUserInput{
 		FullName: req.FullName,
 		Email:    req.Email,
 		Password: req.Password,
@@ -129,7 +129,7 @@ func (h *UserHandler) AssignRoles(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.svc.AssignRoles(uint(id), req.Roles); err != nil {
+	if err := h.svc.AssignRoles(c.Request.Context(), uint(id), req.Roles); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -152,14 +152,11 @@ type updateMeReq struct {
 }
 
 func (h *UserHandler) UpdateMe(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, ok := claims["sub"].(float64)
+	me, ok := principal(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid subject"})
 		return
 	}
-	uid := uint(idf)
+	uid := me.ID
 
 	var req updateMeReq
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -189,7 +186,7 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 		req.Email = nil
 	}
 
-	updated, err := h.svc.UpdateSelf(uid, service.UpdateUserInput{
+	updated, err := h.svc.UpdateSelf(c.Request.Context(), service.UpdateUserInput{
 		FullName: req.FullName,
 		Email:    req.Email,    // nil jika tidak berubah
 		PhotoURL: req.PhotoURL, // boleh nil
@@ -214,22 +211,13 @@ type changePasswordReq struct {
 }
 
 func (h *UserHandler) ChangePassword(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, ok := claims["sub"].(float64)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid subject"})
-		return
-	}
-	uid := uint(idf)
-
 	var req changePasswordReq
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.svc.ChangePassword(uid, req.Current, req.New); err != nil {
+	if err := h.svc.ChangePassword(c.Request.Context(), req.Current, req.New); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -265,7 +253,8 @@ func (h *UserHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	u, err := h.svc.UpdateUser(service.UpdateUserInput{
+
+	u, err := h.svc.UpdateUser(c.Request.Context(), service.UpdateUserInput{
 		ID:       uint(id),
 		FullName: req.FullName,
 		Email:    req.Email,
@@ -284,14 +273,158 @@ func (h *UserHandler) Update(c *gin.Context) {
 	})
 }
 
-// ----- DELETE /users/:id -----
+// ----- POST /users/:id/deactivate & /users/:id/reactivate -----
+func (h *UserHandler) Deactivate(c *gin.Context) { h.setActive(c, false) }
+func (h *UserHandler) Reactivate(c *gin.Context) { h.setActive(c, true) }
+
+func (h *UserHandler) setActive(c *gin.Context, active bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var (
+		u   *domain.User
+		err error
+	)
+	if active {
+		u, err = h.svc.Reactivate(c.Request.Context(), uint(id))
+	} else {
+		u, err = h.svc.Deactivate(c.Request.Context(), uint(id))
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id": u.ID, "active": u.Active,
+		"deactivated_at": u.DeactivatedAt, "deactivated_by": u.DeactivatedBy,
+		"reactivated_at": u.ReactivatedAt, "reactivated_by": u.ReactivatedBy,
+	})
+}
+
+// ----- DELETE /users/:id ----- (arsip / soft delete)
 func (h *UserHandler) Delete(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	if err := h.svc.DeleteUser(uint(id)); err != nil {
+	if err := h.svc.DeleteUser(c.Request.Context(), uint(id)); err != nil {
+		var blocked *service.ArchiveBlockedError
+		if errors.As(err, &blocked) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "blockers": blocked.Report})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "archived"})
+}
+
+// ----- GET /users/:id/archive-check -----
+func (h *UserHandler) ArchiveCheck(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	rep, err := h.svc.ArchiveCheck(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"can_archive": !rep.Blocked(), "blockers": rep})
+}
+
+// ----- POST /users/:id/restore -----
+func (h *UserHandler) Restore(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	u, err := h.svc.RestoreUser(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id": u.ID, "uuid": u.UUID, "full_name": u.FullName, "email": u.Email,
+		"roles": userRolesToStrings(u), "active": u.Active,
+	})
+}
+
+// ----- GET /users/archived -----
+func (h *UserHandler) ListArchived(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 10
+	}
+	users, total, err := h.svc.ListArchived(page, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]gin.H, 0, len(users))
+	for i := range users {
+		u := &users[i]
+		items = append(items, gin.H{
+			"id": u.ID, "uuid": u.UUID, "full_name": u.FullName, "email": u.Email,
+			"roles": userRolesToStrings(u), "archived_at": u.DeletedAt.Time, "archived_by": u.ArchivedBy,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"page": page, "size": size, "total": total, "items": items})
+}
+
+// ----- POST /users/import?dry_run=true (multipart: file .csv/.xlsx) -----
+func (h *UserHandler) Import(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if file.Size > 5*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file too large (max 5MB)"})
+		return
+	}
+	format, err := tabular.FormatFromName(file.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file"})
+		return
+	}
+	defer f.Close()
+	rows, err := tabular.Read(f, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+	res, err := h.svc.ImportUsers(c.Request.Context(), rows, dryRun)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	status := http.StatusOK
+	if !dryRun && res.Invalid > 0 {
+		status = http.StatusUnprocessableEntity // tidak ada yang dibuat
+	} else if res.Created > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, res)
+}
+
+// ----- GET /users/export?format=csv|xlsx -----
+func (h *UserHandler) Export(c *gin.Context) {
+	format, err := tabular.FormatFromName(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	header, rows, err := h.svc.ExportUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().Format("20060102"), format))
+	if err := tabular.Write(c.Writer, format, "Users", header, rows); err != nil {
+		c.Error(err)
+	}
 }
 
 // ====== Upload foto diri sendiri: POST /me/photo ======
@@ -311,14 +444,11 @@ func isImage(fh *multipart.FileHeader) bool {
 }
 
 func (h *UserHandler) UploadMyPhoto(c *gin.Context) {
-	val, _ := c.Get("claims")
-	claims := val.(jwt.MapClaims)
-	idf, ok := claims["sub"].(float64)
+	me, ok := principal(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid subject"})
 		return
 	}
-	uid := uint(idf)
+	uid := me.ID
 
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -353,7 +483,7 @@ func (h *UserHandler) UploadMyPhoto(c *gin.Context) {
 	}
 
 	photoURL := "/" + filepath.ToSlash(dstRel)
-	if _, err := h.svc.UpdateSelf(uid, service.UpdateUserInput{PhotoURL: &photoURL}); err != nil {
+	if _, err := h.svc.UpdateSelf(c.Request.Context(), service.UpdateUserInput{PhotoURL: &photoURL}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user photo"})
 		return
 	}
@@ -363,6 +493,36 @@ func (h *UserHandler) UploadMyPhoto(c *gin.Context) {
 
 // ====== util ======
 
+// principal = user yang login (di-resolve JWTAuth). Kalau tidak ada langsung
+// 401 — tidak ada lagi fallback diam-diam ke user 0.
+func principal(c *gin.Context) (*auth.Principal, bool) {
+	p, ok := middleware.CurrentPrincipal(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+	}
+	return p, ok
+}
+
+// userNames = nama user untuk kolom *_name di response list. Termasuk user
+// nonaktif/arsip (/users/mini hanya berisi user aktif). ID 0 diabaikan.
+// Bila ok=false response error sudah ditulis.
+func userNames(c *gin.Context, users *service.UserService, ids []uint) (map[uint]string, bool) {
+	uniq := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			uniq = append(uniq, id)
+		}
+	}
+	names, err := users.NamesByIDs(uniq)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return names, true
+}
+
 func hasRole(u *domain.User, rn domain.RoleName) bool {
 	for _, r := range u.Roles {
 		if r.Name == rn {
@@ -389,7 +549,7 @@ func (h *UserHandler) ListMini(c *gin.Context) {
 	// default: hanya agent
 	onlyAgents := c.DefaultQuery("only_agents", "true") == "true"
 
-	users, _, err := h.svc.List(page, size) // preload roles di repo agar Roles terisi
+	users, _, err := h.svc.ListActive(page, size) // preload roles di repo agar Roles terisi; hanya user aktif
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -4,11 +4,22 @@ import (
 	"net/http"
 	"strings"
 
+	"bjb-backoffice/internal/auth"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTAuth(secret []byte) gin.HandlerFunc {
+// PrincipalResolver memastikan session (sid) di access token masih aktif lalu
+// membangun principal (role & tim terbaru dari DB). Diimplementasikan oleh
+// service.AuthService.
+type PrincipalResolver interface {
+	ResolvePrincipal(sessionID, userID uint) (*auth.Principal, error)
+}
+
+const principalKey = "principal"
+
+func JWTAuth(secret []byte, resolver PrincipalResolver) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		h := c.GetHeader("Authorization")
 		if h == "" || !strings.HasPrefix(h, "Bearer ") {
@@ -19,7 +30,7 @@ func JWTAuth(secret []byte) gin.HandlerFunc {
 
 		tok, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 			return secret, nil
-		})
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 		if err != nil || !tok.Valid {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
@@ -29,7 +40,33 @@ func JWTAuth(secret []byte) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
 			return
 		}
-		c.Set("claims", claims)
+
+		// token tanpa sid (format lama) atau session yang sudah dicabut → tolak
+		sub, okSub := claims["sub"].(float64)
+		sid, okSid := claims["sid"].(float64)
+		if !okSub || !okSid || sub < 1 || sid < 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
+			return
+		}
+		p, err := resolver.ResolvePrincipal(uint(sid), uint(sub))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(principalKey, p)
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
 		c.Next()
 	}
 }
+
+// CurrentPrincipal mengembalikan user yang login; ok=false kalau route tidak
+// dipasang di belakang JWTAuth.
+func CurrentPrincipal(c *gin.Context) (*auth.Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*auth.Principal)
+	return p, ok && p != nil
+}
@@ -3,62 +3,63 @@ package middleware
 import (
 	"net/http"
 
+	"bjb-backoffice/internal/domain"
+
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-func RequireRoles(allowed ...string) gin.HandlerFunc {
-	allowedSet := make(map[string]struct{}, len(allowed))
-	for _, a := range allowed {
-		allowedSet[a] = struct{}{}
-	}
+// PermissionChecker memetakan role → permission. Diimplementasikan oleh
+// service.PermissionService (cache dari tabel role_permissions).
+type PermissionChecker interface {
+	Has(roles []string, perm domain.Permission) bool
+}
 
-	return func(c *gin.Context) {
-		val, exists := c.Get("claims")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "no claims"})
-			return
-		}
+const permCheckerKey = "perm_checker"
 
-		claims, ok := val.(jwt.MapClaims) // <- WAJIB jwt.MapClaims
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid claims type"})
-			return
-		}
+// Permissions menaruh checker di context supaya RequirePermission & Can bisa dipakai
+// di route maupun di dalam handler. Dipasang setelah JWTAuth.
+func Permissions(checker PermissionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(permCheckerKey, checker)
+		c.Next()
+	}
+}
 
-		raw, ok := claims["roles"]
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "no roles"})
+// RequirePermission lolos bila user punya SALAH SATU permission yang diminta.
+func RequirePermission(perms ...domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentPrincipal(c); !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
 			return
 		}
-
-		switch rs := raw.(type) {
-		case []interface{}:
-			for _, r := range rs {
-				if s, ok := r.(string); ok {
-					if _, ok := allowedSet[s]; ok {
-						c.Next()
-						return
-					}
-				}
-			}
-		case []string:
-			for _, s := range rs {
-				if _, ok := allowedSet[s]; ok {
-					c.Next()
-					return
-				}
-			}
-		case string:
-			if _, ok := allowedSet[rs]; ok {
+		for _, p := range perms {
+			if Can(c, p) {
 				c.Next()
 				return
 			}
-		default:
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "roles malformed"})
-			return
 		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden", "required": perms})
+	}
+}
+
+// Can dipakai handler untuk cek visibilitas (mis. lihat data semua user vs milik sendiri).
+func Can(c *gin.Context, perm domain.Permission) bool {
+	v, ok := c.Get(permCheckerKey)
+	if !ok {
+		return false
+	}
+	checker, ok := v.(PermissionChecker)
+	if !ok {
+		return false
+	}
+	return checker.Has(Roles(c), perm)
+}
 
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+// Roles = role milik principal yang sedang login.
+func Roles(c *gin.Context) []string {
+	p, ok := CurrentPrincipal(c)
+	if !ok {
+		return nil
 	}
+	return p.Roles
 }
@@ -21,63 +21,176 @@ func Setup(
 	notifH *handler.NotificationHandler,
 	holidayH *handler.HolidaySwapHandler,
 	cwcH *handler.CWCHandler,
+	securityH *handler.SecurityHandler,
+	teamH *handler.TeamHandler,
+	permH *handler.PermissionHandler,
+	auditH *handler.AuditHandler,
+	shiftH *handler.ShiftHandler,
+	calH *handler.CalendarHandler,
+	covH *handler.CoverageHandler,
+	staffH *handler.StaffingHandler,
+	laborH *handler.LaborHandler,
+	rosterH *handler.RosterHandler,
+	publicHolidayH *handler.PublicHolidayHandler,
 	jwtSecret []byte,
+	principals middleware.PrincipalResolver,
+	perms middleware.PermissionChecker,
 ) {
 	r.SetTrustedProxies(nil)
 	api := r.Group("/api/v1")
 	api.POST("/auth/login", authH.Login)
+	api.POST("/auth/refresh", authH.Refresh)
+	api.POST("/auth/logout", authH.Logout)
+	api.POST("/auth/forgot-password", authH.ForgotPassword)
+	api.POST("/auth/reset-password", authH.ResetPassword)
+	// langganan kalender: otentikasi lewat token di URL
+	api.GET("/calendar/:feed", calH.Feed)
 
 	secured := api.Group("/")
-	secured.Use(middleware.JWTAuth(jwtSecret))
+	secured.Use(middleware.JWTAuth(jwtSecret, principals), middleware.Permissions(perms))
 
 	// self-service
 	secured.GET("/me", userH.Me)
 	secured.PUT("/me", userH.UpdateMe)
 	secured.PUT("/me/password", userH.ChangePassword)
 	secured.POST("/me/photo", userH.UploadMyPhoto)
+	secured.GET("/me/calendar-feed", calH.Mine)
+	secured.POST("/me/calendar-feed/regenerate", calH.Regenerate)
+	secured.DELETE("/me/calendar-feed", calH.Revoke)
 
 	// users list: backoffice
-	secured.GET("/users",
-		middleware.RequireRoles(
-			string(domain.RoleSuperAdmin),
-			string(domain.RoleSPV),
-			string(domain.RoleQC),
-			string(domain.RoleTL),
-			string(domain.RoleHRAdmin),
-		),
-		userH.List,
-	)
-
-	// users CRUD: SUPER_ADMIN
-	adminOnly := secured.Group("/")
-	adminOnly.Use(middleware.RequireRoles(string(domain.RoleSuperAdmin)))
-	adminOnly.POST("/users", userH.Create)
-	adminOnly.GET("/users/:id", userH.GetByID)
-	adminOnly.PUT("/users/:id", userH.Update)
-	adminOnly.PATCH("/users/:id/roles", userH.AssignRoles)
-	adminOnly.DELETE("/users/:id", userH.Delete)
+	secured.GET("/users", middleware.RequirePermission(domain.PermUsersRead), userH.List)
+
+	// users CRUD
+	userAdmin := secured.Group("/")
+	userAdmin.Use(middleware.RequirePermission(domain.PermUsersManage))
+	userAdmin.POST("/users", userH.Create)
+	userAdmin.GET("/users/:id", userH.GetByID)
+	userAdmin.PUT("/users/:id", userH.Update)
+	userAdmin.PATCH("/users/:id/roles", userH.AssignRoles)
+	userAdmin.POST("/users/:id/deactivate", userH.Deactivate)
+	userAdmin.POST("/users/:id/reactivate", userH.Reactivate)
+	userAdmin.DELETE("/users/:id", userH.Delete)
+	userAdmin.GET("/users/archived", userH.ListArchived)
+	userAdmin.POST("/users/import", userH.Import)
+	userAdmin.GET("/users/export", userH.Export)
+	userAdmin.GET("/users/:id/archive-check", userH.ArchiveCheck)
+	userAdmin.POST("/users/:id/restore", userH.Restore)
+
+	// login security: lockout & audit percobaan login
+	secAdmin := secured.Group("/security")
+	secAdmin.Use(middleware.RequirePermission(domain.PermSecurityManage))
+	secAdmin.GET("/locked-accounts", securityH.ListLocked)
+	secAdmin.POST("/locked-accounts/unlock", securityH.Unlock)
+	secAdmin.GET("/login-attempts", securityH.ListAttempts)
+
+	// permission registry & grant role → permission
+	secured.GET("/me/permissions", permH.Mine)
+	permAdmin := secured.Group("/permissions")
+	permAdmin.Use(middleware.RequirePermission(domain.PermPermissionsManage))
+	permAdmin.GET("", permH.Registry)
+	permAdmin.GET("/roles", permH.Grants)
+	permAdmin.PUT("/roles/:role", permH.SetRoleGrants)
+
+	// audit log (read-only)
+	secured.GET("/audit", middleware.RequirePermission(domain.PermAuditRead), auditH.List)
+
+	// teams / squad
+	secured.GET("/teams", middleware.RequirePermission(domain.PermTeamsRead), teamH.List)
+	secured.GET("/teams/:id", middleware.RequirePermission(domain.PermTeamsRead), teamH.Get)
+	teamAdmin := secured.Group("/teams")
+	teamAdmin.Use(middleware.RequirePermission(domain.PermTeamsManage))
+	teamAdmin.POST("", teamH.Create)
+	teamAdmin.PUT("/:id", teamH.Update)
+	teamAdmin.DELETE("/:id", teamH.Delete)
+	teamAdmin.POST("/:id/members", teamH.AddMember)
+	teamAdmin.POST("/:id/members/remove", teamH.RemoveMember)
 
 	// USERS (mini) – boleh diakses semua yang login
 	secured.GET("/users/mini", userH.ListMini)
 
 	// === SCHEDULES ===
-	// GET (lihat jadwal) – semua login; agent dibatasi di handler
+	// GET (lihat jadwal) – semua login; tanpa schedule:read_all dibatasi miliknya di handler
 	secured.GET("/schedules/monthly", schedH.ListMonthly)
 	secured.GET("/schedules/monthly-all", schedH.ListMonthlyAll)
+	secured.GET("/schedules/monthly-all/export", middleware.RequirePermission(domain.PermScheduleReadAll), schedH.ExportMonthly)
 	secured.GET("/users/:id/off-days", schedH.OffDays)
+	secured.GET("/schedules/validation", middleware.RequirePermission(domain.PermScheduleReadAll), laborH.Report)
+	secured.GET("/schedules/changes", middleware.RequirePermission(domain.PermScheduleReadAll), schedH.Changes)
+	secured.GET("/schedules/:id/history", middleware.RequirePermission(domain.PermScheduleReadAll), schedH.History)
 
-	// Create/Update/Delete – role tertentu saja
+	// Create/Update/Delete
 	schedAdmin := secured.Group("/schedules")
-	schedAdmin.Use(middleware.RequireRoles(string(domain.RoleHRAdmin), string(domain.RoleTL), string(domain.RoleSuperAdmin)))
+	schedAdmin.Use(middleware.RequirePermission(domain.PermScheduleWrite))
 	schedAdmin.POST("", schedH.Create)
+	schedAdmin.POST("/publish", schedH.PublishMonth)
 	schedAdmin.PUT("/:id", schedH.Update)
 	schedAdmin.DELETE("/:id", schedH.Delete)
+	schedAdmin.POST("/bulk-update", schedH.BulkUpdate)
+	schedAdmin.POST("/bulk-delete", schedH.BulkDelete)
+	schedAdmin.POST("/:id/restore", schedH.Restore)
+	schedAdmin.POST("/apply-rotation", shiftH.ApplyRotation)
+	schedAdmin.POST("/roster/import", shiftH.ImportRoster)
+	schedAdmin.POST("/roster/generate", rosterH.Generate)
+	schedAdmin.POST("/clone", rosterH.Clone)
+
+	// draft hasil generator roster: ditinjau TL lalu di-apply / dibuang
+	drafts := secured.Group("/roster-drafts")
+	drafts.Use(middleware.RequirePermission(domain.PermScheduleWrite))
+	drafts.GET("", rosterH.List)
+	drafts.GET("/:id", rosterH.Get)
+	drafts.DELETE("/:id/items/:itemId", rosterH.RemoveItem)
+	drafts.POST("/:id/apply", rosterH.Apply)
+	drafts.POST("/:id/discard", rosterH.Discard)
+
+	// shift template & pola rotasi: baca untuk penyusun jadwal, tulis khusus shifts:manage
+	shiftRead := middleware.RequirePermission(domain.PermShiftsManage, domain.PermScheduleWrite)
+	secured.GET("/shift-templates", shiftRead, shiftH.ListTemplates)
+	secured.GET("/rotation-patterns", shiftRead, shiftH.ListPatterns)
+	secured.GET("/rotation-patterns/:id", shiftRead, shiftH.GetPattern)
+	shiftAdmin := secured.Group("/")
+	shiftAdmin.Use(middleware.RequirePermission(domain.PermShiftsManage))
+	shiftAdmin.POST("/shift-templates", shiftH.CreateTemplate)
+	shiftAdmin.PUT("/shift-templates/:id", shiftH.UpdateTemplate)
+	shiftAdmin.DELETE("/shift-templates/:id", shiftH.DeleteTemplate)
+	shiftAdmin.POST("/rotation-patterns", shiftH.CreatePattern)
+	shiftAdmin.PUT("/rotation-patterns/:id", shiftH.UpdatePattern)
+	shiftAdmin.DELETE("/rotation-patterns/:id", shiftH.DeletePattern)
+
+	// coverage: kebutuhan headcount per channel & laporan kurang/lebih staf
+	covRead := middleware.RequirePermission(domain.PermCoverageManage, domain.PermScheduleReadAll)
+	secured.GET("/coverage/requirements", covRead, covH.List)
+	secured.GET("/coverage/report", covRead, covH.Report)
+	covAdmin := secured.Group("/coverage/requirements")
+	covAdmin.Use(middleware.RequirePermission(domain.PermCoverageManage))
+	covAdmin.POST("", covH.Create)
+	covAdmin.PUT("/:id", covH.Update)
+	covAdmin.DELETE("/:id", covH.Delete)
+
+	// minimum staffing per channel (dicek saat cuti/swap/tukar libur disetujui)
+	secured.GET("/staffing/rules", covRead, staffH.List)
+	staffAdmin := secured.Group("/staffing/rules")
+	staffAdmin.Use(middleware.RequirePermission(domain.PermCoverageManage))
+	staffAdmin.PUT("/:channel", staffH.Set)
+	staffAdmin.DELETE("/:channel", staffH.Delete)
+
+	// aturan jam kerja (istirahat, hari berturut-turut, jam mingguan, libur, panjang shift)
+	secured.GET("/labor-rules", middleware.RequirePermission(domain.PermLaborRulesManage, domain.PermScheduleReadAll), laborH.Get)
+	secured.PUT("/labor-rules", middleware.RequirePermission(domain.PermLaborRulesManage), laborH.Update)
+
+	// kalender hari libur: semua user bisa lihat, kelola khusus holidays:manage
+	secured.GET("/public-holidays", publicHolidayH.List)
+	secured.GET("/public-holidays/shifts", middleware.RequirePermission(domain.PermScheduleReadAll), publicHolidayH.Shifts)
+	phAdmin := secured.Group("/public-holidays")
+	phAdmin.Use(middleware.RequirePermission(domain.PermHolidaysManage))
+	phAdmin.POST("", publicHolidayH.Create)
+	phAdmin.POST("/import", publicHolidayH.Import)
+	phAdmin.PUT("/:id", publicHolidayH.Update)
+	phAdmin.DELETE("/:id", publicHolidayH.Delete)
 
 	// FINDINGS
 	findingsGroup := secured.Group("/findings")
-	findingsGroup.Use(middleware.RequireRoles(
-		string(domain.RoleQC), string(domain.RoleSPV), string(domain.RoleTL), string(domain.RoleHRAdmin), string(domain.RoleSuperAdmin),
-	))
+	findingsGroup.Use(middleware.RequirePermission(domain.PermFindingsWrite))
 	findingsGroup.POST("", findingH.Create)
 	findingsGroup.DELETE("/:id", findingH.Delete)
 	secured.GET("/findings/count-mine", findingH.CountMine) // NEW
@@ -85,12 +198,7 @@ func Setup(
 
 	// Lateness
 	latGroup := secured.Group("/lateness")
-	latGroup.Use(middleware.RequireRoles(
-		string(domain.RoleHRAdmin),
-		string(domain.RoleSPV),
-		string(domain.RoleTL),
-		string(domain.RoleSuperAdmin),
-	))
+	latGroup.Use(middleware.RequirePermission(domain.PermLatenessWrite))
 	latGroup.POST("", lateH.Create)
 	latGroup.DELETE("/:id", lateH.Delete)
 	secured.GET("/lateness", lateH.List)
@@ -100,13 +208,7 @@ func Setup(
 	secured.GET("/leave-requests", leaveH.List) // NEW: list untuk BO/Agent (handler filter)
 	secured.DELETE("/leave-requests/:id", leaveH.Cancel)
 	leaveAdmin := secured.Group("/leave-requests")
-	leaveAdmin.Use(middleware.RequireRoles(
-		string(domain.RoleHRAdmin),
-		string(domain.RoleSuperAdmin),
-		string(domain.RoleSPV),
-		string(domain.RoleTL),
-		string(domain.RoleQC),
-	))
+	leaveAdmin.Use(middleware.RequirePermission(domain.PermLeaveApprove))
 	leaveAdmin.PATCH("/:id/approve", leaveH.Approve)
 	leaveAdmin.PATCH("/:id/reject", leaveH.Reject)
 
@@ -114,7 +216,7 @@ func Setup(
 	secured.POST("/swaps", swapH.Create)
 	secured.GET("/swaps", swapH.List)
 	swapAgent := secured.Group("/swaps")
-	swapAgent.Use(middleware.RequireRoles(string(domain.RoleAgent)))
+	swapAgent.Use(middleware.RequirePermission(domain.PermSwapRespond))
 	swapAgent.PATCH("/:id/accept", swapH.Accept)
 	swapAgent.PATCH("/:id/cancel", swapH.Cancel)
 
@@ -126,39 +228,26 @@ func Setup(
 	// Create & List – semua user login (visibility di handler)
 	secured.POST("/holiday-swaps", holidayH.Create)
 	secured.GET("/holiday-swaps", holidayH.List)
-	// Agent (target) accept/reject
+	// Agent (target) accept/reject, cancel oleh pengaju
 	holidayAgent := secured.Group("/holiday-swaps")
-	holidayAgent.Use(middleware.RequireRoles(string(domain.RoleAgent)))
+	holidayAgent.Use(middleware.RequirePermission(domain.PermHolidayRespond))
 	holidayAgent.POST("/:id/accept", holidayH.TargetAccept)
 	holidayAgent.POST("/:id/reject", holidayH.TargetReject)
+	holidayAgent.POST("/:id/cancel", holidayH.Cancel)
 
 	// Backoffice approve (buat jadwal)
 	holidayAdmin := secured.Group("/holiday-swaps")
-	holidayAdmin.Use(middleware.RequireRoles(
-		string(domain.RoleHRAdmin),
-		string(domain.RoleTL),
-		string(domain.RoleSuperAdmin),
-		string(domain.RoleSPV), // ⬅️ NEW
-		string(domain.RoleQC),  // ⬅️ NEW
-	))
+	holidayAdmin.Use(middleware.RequirePermission(domain.PermHolidayApprove))
 	holidayAdmin.POST("/:id/bo-approve", holidayH.BOApprove)
 
-	// Cancel oleh pengaju (agent)
-	holidayAgent.POST("/:id/cancel", holidayH.Cancel)
-
-	// --- CWC (akses role backoffice) ---
-	cwcGroup := secured.Group("/cwc")
-	cwcGroup.Use(middleware.RequireRoles(
-		string(domain.RoleSuperAdmin),
-		string(domain.RoleSPV),
-		string(domain.RoleQC),
-		string(domain.RoleTL),
-		string(domain.RoleHRAdmin),
-	))
-	cwcGroup.GET("/categories", cwcH.Categories)
-	cwcGroup.POST("/daily", cwcH.UpsertDaily)
-	cwcGroup.GET("", cwcH.Query)
-	cwcGroup.GET("/daily", cwcH.GetDaily) // NEW
-	cwcGroup.DELETE("/daily", cwcH.DeleteDaily)
-
+	// --- CWC ---
+	cwcRead := secured.Group("/cwc")
+	cwcRead.Use(middleware.RequirePermission(domain.PermCWCRead))
+	cwcRead.GET("/categories", cwcH.Categories)
+	cwcRead.GET("", cwcH.Query)
+	cwcRead.GET("/daily", cwcH.GetDaily) // NEW
+	cwcWrite := secured.Group("/cwc")
+	cwcWrite.Use(middleware.RequirePermission(domain.PermCWCWrite))
+	cwcWrite.POST("/daily", cwcH.UpsertDaily)
+	cwcWrite.DELETE("/daily", cwcH.DeleteDaily)
 }
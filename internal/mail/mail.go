@@ -0,0 +1,73 @@
+// Package mail berisi abstraksi pengirim email. Implementasi SMTP bisa
+// ditambahkan belakangan; untuk dev cukup log atau file.
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NewSender memilih implementasi berdasarkan MAIL_DRIVER ("log" | "file").
+func NewSender(driver, from, dir string) Sender {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "file":
+		return &FileSender{From: from, Dir: dir}
+	default:
+		return &LogSender{From: from}
+	}
+}
+
+// LogSender hanya menulis email ke log server.
+type LogSender struct{ From string }
+
+func (s *LogSender) Send(msg Message) error {
+	log.Printf("[mail] from=%s to=%s subject=%q\n%s", s.From, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender menyimpan setiap email sebagai file .eml di Dir (mudah dibuka di mail client).
+type FileSender struct {
+	From string
+	Dir  string
+}
+
+func (s *FileSender) Send(msg Message) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102_150405.000000000"), sanitize(msg.To))
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		s.From, msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+	path := filepath.Join(s.Dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	log.Printf("[mail] saved to=%s file=%s", msg.To, path)
+	return nil
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		case r == '@':
+			return '_'
+		}
+		return -1
+	}, s)
+}
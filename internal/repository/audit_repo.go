@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type AuditFilter struct {
+	ActorID    *uint
+	Action     string // prefix match, mis. "schedule." atau "leave.approve"
+	EntityType string
+	EntityID   *uint
+	From, To   *time.Time
+	Page, Size int
+}
+
+// AuditRepository sengaja hanya punya Create & List (append-only).
+type AuditRepository interface {
+	Create(l *domain.AuditLog) error
+	List(f AuditFilter) ([]domain.AuditLog, int64, error)
+}
+
+type auditRepository struct{ db *gorm.DB }
+
+func NewAuditRepository(db *gorm.DB) AuditRepository { return &auditRepository{db: db} }
+
+func (r *auditRepository) Create(l *domain.AuditLog) error { return r.db.Create(l).Error }
+
+func (r *auditRepository) List(f AuditFilter) ([]domain.AuditLog, int64, error) {
+	var (
+		items []domain.AuditLog
+		total int64
+	)
+	q := r.db.Model(&domain.AuditLog{})
+	if f.ActorID != nil {
+		q = q.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.Action != "" {
+		q = q.Where("action LIKE ?", f.Action+"%")
+	}
+	if f.EntityType != "" {
+		q = q.Where("entity_type = ?", f.EntityType)
+	}
+	if f.EntityID != nil {
+		q = q.Where("entity_id = ?", *f.EntityID)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	q.Count(&total)
+	err := q.Order("created_at DESC, id DESC").
+		Limit(f.Size).Offset((f.Page - 1) * f.Size).
+		Find(&items).Error
+	return items, total, err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type CalendarRepository interface {
+	// Upsert mengganti token user (satu feed per user).
+	Upsert(userID uint, tokenHash string) (*domain.CalendarFeed, error)
+	FindByUser(userID uint) (*domain.CalendarFeed, error)
+	FindByHash(tokenHash string) (*domain.CalendarFeed, error)
+	DeleteByUser(userID uint) error
+	Touch(id uint, at time.Time) error
+}
+
+type calendarRepository struct{ db *gorm.DB }
+
+func NewCalendarRepository(db *gorm.DB) CalendarRepository { return &calendarRepository{db: db} }
+
+func (r *calendarRepository) Upsert(userID uint, tokenHash string) (*domain.CalendarFeed, error) {
+	var f domain.CalendarFeed
+	err := r.db.Where("user_id = ?", userID).First(&f).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		f = domain.CalendarFeed{UserID: userID, TokenHash: tokenHash}
+		err = r.db.Create(&f).Error
+	case err == nil:
+		f.TokenHash = tokenHash
+		f.LastUsedAt = nil
+		err = r.db.Save(&f).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *calendarRepository) FindByUser(userID uint) (*domain.CalendarFeed, error) {
+	var f domain.CalendarFeed
+	if err := r.db.Where("user_id = ?", userID).First(&f).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *calendarRepository) FindByHash(tokenHash string) (*domain.CalendarFeed, error) {
+	var f domain.CalendarFeed
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&f).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *calendarRepository) DeleteByUser(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.CalendarFeed{}).Error
+}
+
+func (r *calendarRepository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&domain.CalendarFeed{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
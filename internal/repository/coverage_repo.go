@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type CoverageRepository interface {
+	Create(r *domain.CoverageRequirement) error
+	Update(r *domain.CoverageRequirement) error
+	Delete(id uint) error
+	FindByID(id uint) (*domain.CoverageRequirement, error)
+	List(channel *domain.WorkChannel, activeOnly bool) ([]domain.CoverageRequirement, error)
+}
+
+type coverageRepository struct{ db *gorm.DB }
+
+func NewCoverageRepository(db *gorm.DB) CoverageRepository { return &coverageRepository{db: db} }
+
+func (r *coverageRepository) Create(m *domain.CoverageRequirement) error { return r.db.Create(m).Error }
+func (r *coverageRepository) Update(m *domain.CoverageRequirement) error { return r.db.Save(m).Error }
+func (r *coverageRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.CoverageRequirement{}, id).Error
+}
+
+func (r *coverageRepository) FindByID(id uint) (*domain.CoverageRequirement, error) {
+	var m domain.CoverageRequirement
+	if err := r.db.First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *coverageRepository) List(channel *domain.WorkChannel, activeOnly bool) ([]domain.CoverageRequirement, error) {
+	q := r.db.Order("channel ASC, start_time ASC, id ASC")
+	if channel != nil {
+		q = q.Where("channel = ?", *channel)
+	}
+	if activeOnly {
+		q = q.Where("active = ?", true)
+	}
+	var out []domain.CoverageRequirement
+	return out, q.Find(&out).Error
+}
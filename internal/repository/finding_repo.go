@@ -3,6 +3,7 @@ package repository
 import (
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 
 	"gorm.io/gorm"
@@ -11,7 +12,8 @@ import (
 type FindingRepository interface {
 	Create(f *domain.Finding) error
 	Delete(id uint) error
-	ListFiltered(agentID *uint, from, to *time.Time, page, size int) ([]domain.Finding, int64, error)
+	FindByID(id uint) (*domain.Finding, error)
+	ListFiltered(agentID *uint, agentIDs []uint, from, to *time.Time, page, size int) ([]domain.Finding, int64, error)
 	CountForAgentInMonth(agentID uint, month time.Time) (int64, error)
 }
 
@@ -23,7 +25,15 @@ func (r *findingRepository) Create(f *domain.Finding) error { return r.db.Create
 
 func (r *findingRepository) Delete(id uint) error { return r.db.Delete(&domain.Finding{}, id).Error }
 
-func (r *findingRepository) ListFiltered(agentID *uint, from, to *time.Time, page, size int) ([]domain.Finding, int64, error) {
+func (r *findingRepository) FindByID(id uint) (*domain.Finding, error) {
+	var f domain.Finding
+	if err := r.db.First(&f, id).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *findingRepository) ListFiltered(agentID *uint, agentIDs []uint, from, to *time.Time, page, size int) ([]domain.Finding, int64, error) {
 	var (
 		items []domain.Finding
 		total int64
@@ -32,6 +42,9 @@ func (r *findingRepository) ListFiltered(agentID *uint, from, to *time.Time, pag
 	if agentID != nil {
 		q = q.Where("agent_id = ?", *agentID)
 	}
+	if agentIDs != nil { // filter tim; slice kosong = tidak ada anggota
+		q = q.Where("agent_id IN ?", agentIDs)
+	}
 	if from != nil {
 		q = q.Where("issued_at >= ?", *from)
 	}
@@ -48,7 +61,7 @@ func (r *findingRepository) ListFiltered(agentID *uint, from, to *time.Time, pag
 
 func (r *findingRepository) CountForAgentInMonth(agentID uint, month time.Time) (int64, error) {
 	// bulan berjalan: [monthStart, nextMonthStart)
-	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	start := clock.MonthStart(month)
 	end := start.AddDate(0, 1, 0)
 	var n int64
 	err := r.db.Model(&domain.Finding{}).
@@ -2,6 +2,8 @@
 package repository
 
 import (
+	"time"
+
 	"bjb-backoffice/internal/domain"
 
 	"gorm.io/gorm"
@@ -12,6 +14,9 @@ type HolidaySwapRepository interface {
 	Update(m *domain.HolidaySwap) error
 	FindByID(id uint) (*domain.HolidaySwap, error)
 	List(page, size int) ([]domain.HolidaySwap, int64, error)
+	ListPendingByUser(userID uint) ([]domain.HolidaySwap, error)
+	// tukar libur APPROVED yang melibatkan user dengan off_date di [from, to)
+	ListApprovedByUser(userID uint, from, to time.Time) ([]domain.HolidaySwap, error)
 }
 
 type holidaySwapRepository struct{ db *gorm.DB }
@@ -32,6 +37,27 @@ func (r *holidaySwapRepository) FindByID(id uint) (*domain.HolidaySwap, error) {
 	return &m, nil
 }
 
+func (r *holidaySwapRepository) ListPendingByUser(userID uint) ([]domain.HolidaySwap, error) {
+	var rows []domain.HolidaySwap
+	err := r.db.
+		Where("status IN ?", []domain.HolidaySwapStatus{domain.HolidayPendingTarget, domain.HolidayPendingBO}).
+		Where("requester_id = ? OR target_user_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *holidaySwapRepository) ListApprovedByUser(userID uint, from, to time.Time) ([]domain.HolidaySwap, error) {
+	var rows []domain.HolidaySwap
+	err := r.db.
+		Where("status = ?", domain.HolidayApproved).
+		Where("requester_id = ? OR target_user_id = ?", userID, userID).
+		Where("off_date >= ? AND off_date < ?", from, to).
+		Order("off_date ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
 func (r *holidaySwapRepository) List(page, size int) ([]domain.HolidaySwap, int64, error) {
 	if page < 1 {
 		page = 1
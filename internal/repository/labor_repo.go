@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type LaborRuleRepository interface {
+	// Get mengembalikan rules tersimpan; belum ada → nilai kosong (semua aturan off).
+	Get() (*domain.LaborRules, error)
+	Save(m *domain.LaborRules) error
+}
+
+type laborRuleRepository struct{ db *gorm.DB }
+
+func NewLaborRuleRepository(db *gorm.DB) LaborRuleRepository { return &laborRuleRepository{db: db} }
+
+func (r *laborRuleRepository) Get() (*domain.LaborRules, error) {
+	var m domain.LaborRules
+	err := r.db.Order("id ASC").First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &domain.LaborRules{Active: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *laborRuleRepository) Save(m *domain.LaborRules) error { return r.db.Save(m).Error }
@@ -11,10 +11,11 @@ import (
 type LatenessRepository interface {
 	Create(l *domain.Lateness) error
 	Delete(id uint) error
-	List(agentID *uint, from, to *time.Time, page, size int) ([]domain.Lateness, int64, error)
+	FindByID(id uint) (*domain.Lateness, error)
+	List(agentID *uint, agentIDs []uint, from, to *time.Time, page, size int) ([]domain.Lateness, int64, error)
 
 	// Aggregasi: kembalikan pasangan (periode, total_menit)
-	Aggregate(agentID *uint, from, to *time.Time, group string) ([]AggRow, error)
+	Aggregate(agentID *uint, agentIDs []uint, from, to *time.Time, group string) ([]AggRow, error)
 }
 
 type AggRow struct {
@@ -30,7 +31,15 @@ func (r *latenessRepository) Create(l *domain.Lateness) error { return r.db.Crea
 
 func (r *latenessRepository) Delete(id uint) error { return r.db.Delete(&domain.Lateness{}, id).Error }
 
-func (r *latenessRepository) List(agentID *uint, from, to *time.Time, page, size int) ([]domain.Lateness, int64, error) {
+func (r *latenessRepository) FindByID(id uint) (*domain.Lateness, error) {
+	var l domain.Lateness
+	if err := r.db.First(&l, id).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *latenessRepository) List(agentID *uint, agentIDs []uint, from, to *time.Time, page, size int) ([]domain.Lateness, int64, error) {
 	var (
 		items []domain.Lateness
 		total int64
@@ -39,6 +48,9 @@ func (r *latenessRepository) List(agentID *uint, from, to *time.Time, page, size
 	if agentID != nil {
 		q = q.Where("agent_id = ?", *agentID)
 	}
+	if agentIDs != nil {
+		q = q.Where("agent_id IN ?", agentIDs)
+	}
 	if from != nil {
 		q = q.Where("date >= ?", from.Format("2006-01-02"))
 	}
@@ -51,7 +63,7 @@ func (r *latenessRepository) List(agentID *uint, from, to *time.Time, page, size
 	return items, total, err
 }
 
-func (r *latenessRepository) Aggregate(agentID *uint, from, to *time.Time, group string) ([]AggRow, error) {
+func (r *latenessRepository) Aggregate(agentID *uint, agentIDs []uint, from, to *time.Time, group string) ([]AggRow, error) {
 	// Postgres: date_trunc untuk weekly/monthly; daily cukup date
 	var rows []AggRow
 	base := r.db.Table("latenesses") // gorm pluralization default: "latenesses"
@@ -62,6 +74,10 @@ func (r *latenessRepository) Aggregate(agentID *uint, from, to *time.Time, group
 		where += " AND agent_id = ?"
 		args = append(args, *agentID)
 	}
+	if agentIDs != nil {
+		where += " AND agent_id IN ?"
+		args = append(args, agentIDs)
+	}
 	if from != nil {
 		where += " AND date >= ?"
 		args = append(args, from.Format("2006-01-02"))
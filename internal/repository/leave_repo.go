@@ -13,6 +13,8 @@ type LeaveRepository interface {
 	FindByID(id uint) (*domain.LeaveRequest, error)
 	List(requesterID *uint, status *domain.LeaveStatus, from, to *time.Time, page, size int) ([]domain.LeaveRequest, int64, error)
 	Delete(id uint) error
+	// cuti APPROVED yang beririsan dengan tanggal [from, to)
+	ListApprovedBetween(from, to time.Time) ([]domain.LeaveRequest, error)
 }
 
 type leaveRepository struct{ db *gorm.DB }
@@ -52,3 +54,11 @@ func (r *leaveRepository) List(requesterID *uint, status *domain.LeaveStatus, fr
 func (r *leaveRepository) Delete(id uint) error {
 	return r.db.Delete(&domain.LeaveRequest{}, id).Error
 }
+
+func (r *leaveRepository) ListApprovedBetween(from, to time.Time) ([]domain.LeaveRequest, error) {
+	var out []domain.LeaveRequest
+	err := r.db.Where("status = ?", domain.LeaveApproved).
+		Where("start_date < ? AND end_date >= ?", to.Format("2006-01-02"), from.Format("2006-01-02")).
+		Find(&out).Error
+	return out, err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptFilter struct {
+	Email   string
+	IP      string
+	Success *bool
+	From    *time.Time
+	To      *time.Time
+	Page    int
+	Size    int
+}
+
+type LoginAttemptRepository interface {
+	Create(a *domain.LoginAttempt) error
+	List(f LoginAttemptFilter) ([]domain.LoginAttempt, int64, error)
+	// CountFailuresByIP tidak menghitung percobaan yang ditolak guard sendiri
+	// (LOCKED / IP_BLOCKED / THROTTLED), supaya blok IP bisa habis walau klien
+	// terus mencoba.
+	CountFailuresByIP(ip string, since time.Time) (int64, error)
+
+	FindLock(email string) (*domain.AccountLock, error) // nil, nil kalau belum ada
+	SaveLock(l *domain.AccountLock) error
+	// RecordFailure menaikkan counter gagal email secara atomik (satu upsert):
+	// lockout yang sudah lewat mulai dari 1, counter >= maxAttempts (> 0)
+	// mengunci sampai at+lockFor. Mengembalikan baris setelah update.
+	RecordFailure(email string, at time.Time, maxAttempts int, lockFor time.Duration) (*domain.AccountLock, error)
+	ListLocked(now time.Time) ([]domain.AccountLock, error)
+}
+
+type loginAttemptRepository struct{ db *gorm.DB }
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Create(a *domain.LoginAttempt) error { return r.db.Create(a).Error }
+
+func (r *loginAttemptRepository) List(f LoginAttemptFilter) ([]domain.LoginAttempt, int64, error) {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Size < 1 || f.Size > 200 {
+		f.Size = 50
+	}
+	q := r.db.Model(&domain.LoginAttempt{})
+	if f.Email != "" {
+		q = q.Where("email = ?", strings.ToLower(f.Email))
+	}
+	if f.IP != "" {
+		q = q.Where("ip = ?", f.IP)
+	}
+	if f.Success != nil {
+		q = q.Where("success = ?", *f.Success)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", *f.To)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []domain.LoginAttempt
+	err := q.Order("created_at DESC").Limit(f.Size).Offset((f.Page - 1) * f.Size).Find(&out).Error
+	return out, total, err
+}
+
+func (r *loginAttemptRepository) CountFailuresByIP(ip string, since time.Time) (int64, error) {
+	var n int64
+	err := r.db.Model(&domain.LoginAttempt{}).
+		Where("ip = ? AND success = false AND created_at >= ?", ip, since).
+		Where("reason NOT IN ?", []string{domain.LoginReasonLocked, domain.LoginReasonIPBlocked, domain.LoginReasonThrottled}).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *loginAttemptRepository) FindLock(email string) (*domain.AccountLock, error) {
+	var l domain.AccountLock
+	tx := r.db.Where("email = ?", email).Limit(1).Find(&l)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &l, nil
+}
+
+func (r *loginAttemptRepository) SaveLock(l *domain.AccountLock) error { return r.db.Save(l).Error }
+
+const recordFailureSQL = `
+INSERT INTO account_locks (email, failed_count, last_failed_at, locked_until, created_at, updated_at)
+VALUES (@email, 1, @at, @first_until, @at, @at)
+ON CONFLICT (email) DO UPDATE SET
+	failed_count = CASE WHEN account_locks.locked_until <= @at THEN 1 ELSE account_locks.failed_count + 1 END,
+	locked_until = CASE
+		WHEN @max > 0 AND (CASE WHEN account_locks.locked_until <= @at THEN 1 ELSE account_locks.failed_count + 1 END) >= @max THEN @until
+		WHEN account_locks.locked_until <= @at THEN NULL
+		ELSE account_locks.locked_until
+	END,
+	last_failed_at = @at,
+	updated_at = @at
+RETURNING *`
+
+func (r *loginAttemptRepository) RecordFailure(email string, at time.Time, maxAttempts int, lockFor time.Duration) (*domain.AccountLock, error) {
+	until := at.Add(lockFor)
+	var firstUntil *time.Time
+	if maxAttempts > 0 && maxAttempts <= 1 {
+		firstUntil = &until
+	}
+	var l domain.AccountLock
+	err := r.db.Raw(recordFailureSQL, map[string]any{
+		"email": email, "at": at, "first_until": firstUntil, "max": maxAttempts, "until": until,
+	}).Scan(&l).Error
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *loginAttemptRepository) ListLocked(now time.Time) ([]domain.AccountLock, error) {
+	var out []domain.AccountLock
+	err := r.db.Where("locked_until > ?", now).Order("locked_until DESC").Find(&out).Error
+	return out, err
+}
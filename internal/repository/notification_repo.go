@@ -11,7 +11,7 @@ import (
 type NotificationRepository interface {
 	Create(n *domain.Notification) error
 	ListByUser(userID uint, onlyUnread bool, limit int) ([]domain.Notification, error)
-	MarkRead(id uint) error
+	MarkRead(id, userID uint) (bool, error) // false = bukan milik user / tidak ada
 }
 
 type notificationRepository struct{ db *gorm.DB }
@@ -45,6 +45,7 @@ func (r *notificationRepository) ListByUser(userID uint, onlyUnread bool, limit
 	return out, q.Limit(limit).Find(&out).Error
 }
 
-func (r *notificationRepository) MarkRead(id uint) error {
-	return r.db.Model(&domain.Notification{}).Where("id = ?", id).Update("is_read", true).Error
+func (r *notificationRepository) MarkRead(id, userID uint) (bool, error) {
+	res := r.db.Model(&domain.Notification{}).Where("id = ? AND user_id = ?", id, userID).Update("is_read", true)
+	return res.RowsAffected > 0, res.Error
 }
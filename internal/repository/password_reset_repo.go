@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(t *domain.PasswordResetToken) error
+	FindByTokenHash(hash string) (*domain.PasswordResetToken, error)
+	// InvalidateForUser menandai semua token user yang belum terpakai sebagai used.
+	InvalidateForUser(userID uint) error
+	// UseToken menandai token terpakai, mengganti password_hash user dan
+	// menandai token lain milik user sebagai used dalam satu transaksi; token
+	// hanya terpakai kalau password benar-benar berubah. Token yang sudah
+	// pernah dipakai → ErrResetTokenUsed.
+	UseToken(t *domain.PasswordResetToken, passwordHash string) error
+}
+
+var ErrResetTokenUsed = errors.New("reset token already used")
+
+type passwordResetRepository struct{ db *gorm.DB }
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(t *domain.PasswordResetToken) error {
+	return r.db.Create(t).Error
+}
+
+func (r *passwordResetRepository) FindByTokenHash(hash string) (*domain.PasswordResetToken, error) {
+	var t domain.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", hash).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func markResetUsed(db *gorm.DB, id uint) error {
+	res := db.Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrResetTokenUsed
+	}
+	return nil
+}
+
+func (r *passwordResetRepository) InvalidateForUser(userID uint) error {
+	return r.db.Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *passwordResetRepository) UseToken(t *domain.PasswordResetToken, passwordHash string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := markResetUsed(tx, t.ID); err != nil {
+			return err
+		}
+		res := tx.Model(&domain.User{}).Where("id = ?", t.UserID).Update("password_hash", passwordHash)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&domain.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", t.UserID).
+			Update("used_at", time.Now()).Error
+	})
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PermissionRepository interface {
+	ListAll() ([]domain.RolePermission, error)
+	// ReplaceForRole mengganti seluruh grant milik role dalam satu transaksi.
+	ReplaceForRole(role domain.RoleName, perms []domain.Permission) error
+	// SeedNew memberi grant default untuk permission yang belum tercatat di
+	// permission_keys (tabel kosong = semua baru), lalu mencatatnya.
+	SeedNew(keys []domain.Permission, defaults map[domain.RoleName][]domain.Permission) error
+}
+
+type permissionRepository struct{ db *gorm.DB }
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (r *permissionRepository) ListAll() ([]domain.RolePermission, error) {
+	var items []domain.RolePermission
+	err := r.db.Order("role ASC, permission ASC").Find(&items).Error
+	return items, err
+}
+
+func (r *permissionRepository) ReplaceForRole(role domain.RoleName, perms []domain.Permission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role).Delete(&domain.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(perms) == 0 {
+			return nil
+		}
+		rows := make([]domain.RolePermission, 0, len(perms))
+		for _, p := range perms {
+			rows = append(rows, domain.RolePermission{Role: role, Permission: p})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r *permissionRepository) SeedNew(keys []domain.Permission, defaults map[domain.RoleName][]domain.Permission) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var known []domain.Permission
+		if err := tx.Model(&domain.PermissionKey{}).Pluck("key", &known).Error; err != nil {
+			return err
+		}
+		if len(known) == 0 {
+			// DB lama (sebelum permission_keys ada): permission yang sudah punya
+			// grant dianggap sudah di-seed; jangan timpa hasil edit admin.
+			if err := tx.Model(&domain.RolePermission{}).Distinct().Pluck("permission", &known).Error; err != nil {
+				return err
+			}
+		}
+		seen := make(map[domain.Permission]bool, len(known))
+		for _, k := range known {
+			seen[k] = true
+		}
+		for _, k := range keys {
+			if !seen[k] {
+				for role, perms := range defaults {
+					for _, p := range perms {
+						if p != k {
+							continue
+						}
+						if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+							Create(&domain.RolePermission{Role: role, Permission: p}).Error; err != nil {
+							return err
+						}
+					}
+				}
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&domain.PermissionKey{Key: k}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
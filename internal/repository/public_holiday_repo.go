@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type PublicHolidayRepository interface {
+	Create(h *domain.PublicHoliday) error
+	Update(h *domain.PublicHoliday) error
+	Delete(id uint) error
+	FindByID(id uint) (*domain.PublicHoliday, error)
+	// hari libur di [from, to) urut tanggal
+	ListBetween(from, to time.Time) ([]domain.PublicHoliday, error)
+	// Upsert per tanggal dalam satu transaksi (import)
+	Upsert(items []domain.PublicHoliday) error
+}
+
+type publicHolidayRepository struct{ db *gorm.DB }
+
+func NewPublicHolidayRepository(db *gorm.DB) PublicHolidayRepository {
+	return &publicHolidayRepository{db: db}
+}
+
+func (r *publicHolidayRepository) Create(h *domain.PublicHoliday) error { return r.db.Create(h).Error }
+func (r *publicHolidayRepository) Update(h *domain.PublicHoliday) error { return r.db.Save(h).Error }
+func (r *publicHolidayRepository) Delete(id uint) error {
+	return r.db.Delete(&domain.PublicHoliday{}, id).Error
+}
+
+func (r *publicHolidayRepository) FindByID(id uint) (*domain.PublicHoliday, error) {
+	var h domain.PublicHoliday
+	if err := r.db.First(&h, id).Error; err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (r *publicHolidayRepository) ListBetween(from, to time.Time) ([]domain.PublicHoliday, error) {
+	var out []domain.PublicHoliday
+	err := r.db.Where("date >= ? AND date < ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("date ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *publicHolidayRepository) Upsert(items []domain.PublicHoliday) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, it := range items {
+			var cur domain.PublicHoliday
+			err := tx.Where("date = ?", it.Date.Format("2006-01-02")).First(&cur).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				h := it
+				if err := tx.Create(&h).Error; err != nil {
+					return err
+				}
+			case err != nil:
+				return err
+			default:
+				cur.Name, cur.Kind = it.Name, it.Kind
+				if err := tx.Save(&cur).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
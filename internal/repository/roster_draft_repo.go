@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type RosterDraftRepository interface {
+	// Create menyimpan draft beserta item-nya.
+	Create(d *domain.RosterDraft) error
+	// Save hanya menyimpan header draft (status, unfilled, dst).
+	Save(d *domain.RosterDraft) error
+	FindByID(id uint) (*domain.RosterDraft, error)
+	List(month *time.Time) ([]domain.RosterDraft, error)
+	DeleteItem(draftID, itemID uint) (bool, error)
+	Tx(fn func(tx *gorm.DB) error) error
+}
+
+type rosterDraftRepository struct{ db *gorm.DB }
+
+func NewRosterDraftRepository(db *gorm.DB) RosterDraftRepository {
+	return &rosterDraftRepository{db: db}
+}
+
+func (r *rosterDraftRepository) Create(d *domain.RosterDraft) error { return r.db.Create(d).Error }
+
+func (r *rosterDraftRepository) Save(d *domain.RosterDraft) error {
+	return r.db.Omit("Items").Save(d).Error
+}
+
+func (r *rosterDraftRepository) FindByID(id uint) (*domain.RosterDraft, error) {
+	var d domain.RosterDraft
+	err := r.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("start_at ASC, user_id ASC") }).
+		First(&d, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// List tanpa item; month nil = semua bulan.
+func (r *rosterDraftRepository) List(month *time.Time) ([]domain.RosterDraft, error) {
+	q := r.db.Order("created_at DESC")
+	if month != nil {
+		q = q.Where("month = ?", month.Format("2006-01-02"))
+	}
+	var out []domain.RosterDraft
+	return out, q.Find(&out).Error
+}
+
+func (r *rosterDraftRepository) DeleteItem(draftID, itemID uint) (bool, error) {
+	res := r.db.Where("draft_id = ? AND id = ?", draftID, itemID).Delete(&domain.RosterDraftItem{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *rosterDraftRepository) Tx(fn func(tx *gorm.DB) error) error { return r.db.Transaction(fn) }
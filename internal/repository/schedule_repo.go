@@ -1,20 +1,33 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrScheduleOverlap = errors.New("schedule overlaps existing slot")
+
 type ScheduleRepository interface {
-	Create(s *domain.Schedule) error
-	Update(s *domain.Schedule) error
-	Delete(id uint) error
+	// CreateBatch membuat banyak jadwal dalam satu transaksi; overlap (dengan
+	// data lama maupun sesama batch) membatalkan semuanya dengan ErrScheduleOverlap.
+	CreateBatch(items []*domain.Schedule) error
 	FindByID(id uint) (*domain.Schedule, error)
+	// jadwal yang tanggal bisnisnya (tanggal mulai) jatuh di bulan tsb
 	ListMonthly(userID *uint, month time.Time) ([]domain.Schedule, error)
 	ExistsOverlap(userID uint, start, end time.Time, excludeID *uint) (bool, error)
+	// ExistsOverlapTx = ExistsOverlap di dalam transaksi tx (nil = di luar transaksi).
+	ExistsOverlapTx(tx *gorm.DB, userID uint, start, end time.Time, excludeID *uint) (bool, error)
+	ListByUserRange(userID uint, from, to time.Time) ([]domain.Schedule, error)
+	// jadwal user AKTIF yang overlap [from, to), opsional per channel
+	ListActiveRange(from, to time.Time, channel *domain.WorkChannel) ([]domain.Schedule, error)
+	ListUpcomingByUser(userID uint, from time.Time) ([]domain.Schedule, error)
 
 	// LOOKUP utk channel:
 	FindByUserAndOverlap(userID uint, start, end time.Time) (*domain.Schedule, error) // robust
@@ -23,6 +36,10 @@ type ScheduleRepository interface {
 
 	ListUserIDsOverlapSameChannel(start, end time.Time, channel domain.WorkChannel, excludeUserID uint) ([]uint, error)
 
+	// PublishMonth mengubah semua jadwal DRAFT yang mulai di [from, to) menjadi
+	// PUBLISHED dalam satu transaksi dan mengembalikan baris yang berubah.
+	PublishMonth(from, to, at time.Time) ([]domain.Schedule, error)
+
 	Tx(fn func(tx *gorm.DB) error) error
 }
 
@@ -30,9 +47,23 @@ type scheduleRepository struct{ db *gorm.DB }
 
 func NewScheduleRepository(db *gorm.DB) ScheduleRepository { return &scheduleRepository{db: db} }
 
-func (r *scheduleRepository) Create(s *domain.Schedule) error { return r.db.Create(s).Error }
-func (r *scheduleRepository) Update(s *domain.Schedule) error { return r.db.Save(s).Error }
-func (r *scheduleRepository) Delete(id uint) error            { return r.db.Delete(&domain.Schedule{}, id).Error }
+func (r *scheduleRepository) CreateBatch(items []*domain.Schedule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range items {
+			ok, err := r.ExistsOverlapTx(tx, s.UserID, s.StartAt, s.EndAt, nil)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return fmt.Errorf("%w: user #%d %s", ErrScheduleOverlap, s.UserID, s.StartAt.Format("2006-01-02 15:04"))
+			}
+			if err := tx.Create(s).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
 func (r *scheduleRepository) FindByID(id uint) (*domain.Schedule, error) {
 	var s domain.Schedule
@@ -43,9 +74,10 @@ func (r *scheduleRepository) FindByID(id uint) (*domain.Schedule, error) {
 }
 
 func (r *scheduleRepository) ListMonthly(userID *uint, month time.Time) ([]domain.Schedule, error) {
-	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	start := clock.MonthStart(month)
 	end := start.AddDate(0, 1, 0)
-	q := r.db.Where("start_at < ? AND end_at >= ?", end, start)
+	// shift malam tgl terakhir bulan lalu milik bulan lalu, walau selesai di bulan ini
+	q := r.db.Where("start_at >= ? AND start_at < ?", start, end)
 	if userID != nil {
 		q = q.Where("user_id = ?", *userID)
 	}
@@ -54,7 +86,14 @@ func (r *scheduleRepository) ListMonthly(userID *uint, month time.Time) ([]domai
 }
 
 func (r *scheduleRepository) ExistsOverlap(userID uint, start, end time.Time, excludeID *uint) (bool, error) {
-	q := r.db.Model(&domain.Schedule{}).
+	return r.ExistsOverlapTx(nil, userID, start, end, excludeID)
+}
+
+func (r *scheduleRepository) ExistsOverlapTx(tx *gorm.DB, userID uint, start, end time.Time, excludeID *uint) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+	q := tx.Model(&domain.Schedule{}).
 		Where("user_id = ?", userID).
 		Where("NOT (end_at <= ? OR start_at >= ?)", start, end)
 	if excludeID != nil {
@@ -67,6 +106,31 @@ func (r *scheduleRepository) ExistsOverlap(userID uint, start, end time.Time, ex
 	return cnt > 0, nil
 }
 
+// jadwal user yang overlap [from, to)
+func (r *scheduleRepository) ListByUserRange(userID uint, from, to time.Time) ([]domain.Schedule, error) {
+	var out []domain.Schedule
+	err := r.db.Where("user_id = ? AND start_at < ? AND end_at > ?", userID, to, from).Order("start_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *scheduleRepository) ListActiveRange(from, to time.Time, channel *domain.WorkChannel) ([]domain.Schedule, error) {
+	q := r.db.Model(&domain.Schedule{}).
+		Joins("JOIN users u ON u.id = schedules.user_id AND u.active = ? AND u.deleted_at IS NULL", true).
+		Where("schedules.start_at < ? AND schedules.end_at > ?", to, from)
+	if channel != nil {
+		q = q.Where("schedules.channel = ?", *channel)
+	}
+	var out []domain.Schedule
+	return out, q.Order("schedules.start_at ASC").Find(&out).Error
+}
+
+// jadwal user yang belum selesai per waktu from
+func (r *scheduleRepository) ListUpcomingByUser(userID uint, from time.Time) ([]domain.Schedule, error) {
+	var out []domain.Schedule
+	err := r.db.Where("user_id = ? AND end_at > ?", userID, from).Order("start_at ASC").Find(&out).Error
+	return out, err
+}
+
 // Overlap (robust)
 func (r *scheduleRepository) FindByUserAndOverlap(userID uint, start, end time.Time) (*domain.Schedule, error) {
 	var s domain.Schedule
@@ -111,20 +175,42 @@ func (r *scheduleRepository) FindByUserAndSameDay(userID uint, dayStart, dayEnd
 }
 
 func (r *scheduleRepository) ListUserIDsOverlapSameChannel(start, end time.Time, channel domain.WorkChannel, excludeUserID uint) ([]uint, error) {
-	// Ambil user yang punya jadwal overlap (start_at < end && end_at > start) dengan channel sama
+	// Ambil user AKTIF yang punya jadwal overlap (start_at < end && end_at > start) dengan channel sama
 	q := r.db.Model(&domain.Schedule{}).
-		Where("start_at < ? AND end_at > ?", end, start).
-		Where("channel = ?", channel)
+		Joins("JOIN users u ON u.id = schedules.user_id AND u.active = ?", true).
+		Where("schedules.start_at < ? AND schedules.end_at > ?", end, start).
+		Where("schedules.channel = ?", channel)
 	if excludeUserID != 0 {
-		q = q.Where("user_id <> ?", excludeUserID)
+		q = q.Where("schedules.user_id <> ?", excludeUserID)
 	}
 	var ids []uint
-	if err := q.Distinct("user_id").Pluck("user_id", &ids).Error; err != nil {
+	if err := q.Distinct("schedules.user_id").Pluck("schedules.user_id", &ids).Error; err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
+func (r *scheduleRepository) PublishMonth(from, to, at time.Time) ([]domain.Schedule, error) {
+	var out []domain.Schedule
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND start_at >= ? AND start_at < ?", domain.ScheduleDraft, from, to).
+			Order("start_at ASC").Find(&out).Error
+		if err != nil || len(out) == 0 {
+			return err
+		}
+		ids := make([]uint, len(out))
+		for i := range out {
+			ids[i] = out[i].ID
+			out[i].Status = domain.SchedulePublished
+			out[i].PublishedAt = &at
+		}
+		return tx.Model(&domain.Schedule{}).Where("id IN ?", ids).
+			Updates(map[string]any{"status": domain.SchedulePublished, "published_at": at}).Error
+	})
+	return out, err
+}
+
 func (r *scheduleRepository) Tx(fn func(tx *gorm.DB) error) error {
 	return r.db.Transaction(fn)
 }
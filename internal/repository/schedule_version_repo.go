@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type ScheduleVersionRepository interface {
+	// Append menyimpan versi baru. baseline = kondisi sebelum perubahan; hanya
+	// disimpan untuk jadwal yang belum punya versi sama sekali (data lama).
+	// tx nil = di luar transaksi.
+	Append(tx *gorm.DB, baseline, items []domain.ScheduleVersion) error
+	FindByID(id uint) (*domain.ScheduleVersion, error)
+	ListBySchedule(scheduleID uint) ([]domain.ScheduleVersion, error)
+	// ListForMonth: versi (dibuat <= until) milik jadwal yang pernah mulai di [from, to).
+	ListForMonth(from, to, until time.Time) ([]domain.ScheduleVersion, error)
+}
+
+type scheduleVersionRepository struct{ db *gorm.DB }
+
+func NewScheduleVersionRepository(db *gorm.DB) ScheduleVersionRepository {
+	return &scheduleVersionRepository{db: db}
+}
+
+func (r *scheduleVersionRepository) Append(tx *gorm.DB, baseline, items []domain.ScheduleVersion) error {
+	if tx == nil {
+		return r.db.Transaction(func(tx *gorm.DB) error { return r.Append(tx, baseline, items) })
+	}
+	if len(baseline) > 0 {
+		ids := make([]uint, len(baseline))
+		for i, b := range baseline {
+			ids[i] = b.ScheduleID
+		}
+		var known []uint
+		if err := tx.Model(&domain.ScheduleVersion{}).Where("schedule_id IN ?", ids).
+			Distinct().Pluck("schedule_id", &known).Error; err != nil {
+			return err
+		}
+		has := make(map[uint]bool, len(known))
+		for _, id := range known {
+			has[id] = true
+		}
+		var missing []domain.ScheduleVersion
+		for _, b := range baseline {
+			if !has[b.ScheduleID] {
+				has[b.ScheduleID] = true
+				missing = append(missing, b)
+			}
+		}
+		if len(missing) > 0 {
+			if err := tx.Create(&missing).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return tx.Create(&items).Error
+}
+
+func (r *scheduleVersionRepository) FindByID(id uint) (*domain.ScheduleVersion, error) {
+	var m domain.ScheduleVersion
+	if err := r.db.First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *scheduleVersionRepository) ListBySchedule(scheduleID uint) ([]domain.ScheduleVersion, error) {
+	var out []domain.ScheduleVersion
+	err := r.db.Where("schedule_id = ?", scheduleID).Order("created_at ASC, id ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *scheduleVersionRepository) ListForMonth(from, to, until time.Time) ([]domain.ScheduleVersion, error) {
+	inMonth := r.db.Model(&domain.ScheduleVersion{}).Select("schedule_id").
+		Where("start_at >= ? AND start_at < ?", from, to)
+	var out []domain.ScheduleVersion
+	err := r.db.Where("schedule_id IN (?) AND created_at <= ?", inMonth, until).
+		Order("schedule_id ASC, created_at ASC, id ASC").Find(&out).Error
+	return out, err
+}
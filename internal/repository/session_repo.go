@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(s *domain.Session) error
+	FindByID(id uint) (*domain.Session, error)
+	FindByTokenHash(hash string) (*domain.Session, error)
+	Revoke(id uint) error
+	RevokeAllForUser(userID uint) error
+	// Rotate mencabut session lama & membuat penggantinya dalam satu transaksi.
+	// Return ErrSessionRevoked kalau session lama ternyata sudah dicabut
+	// (refresh token dipakai dua kali).
+	Rotate(oldID uint, next *domain.Session) error
+}
+
+var ErrSessionRevoked = errors.New("session already revoked")
+
+type sessionRepository struct{ db *gorm.DB }
+
+func NewSessionRepository(db *gorm.DB) SessionRepository { return &sessionRepository{db: db} }
+
+func (r *sessionRepository) Create(s *domain.Session) error { return r.db.Create(s).Error }
+
+func (r *sessionRepository) FindByID(id uint) (*domain.Session, error) {
+	var s domain.Session
+	if err := r.db.First(&s, id).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sessionRepository) FindByTokenHash(hash string) (*domain.Session, error) {
+	var s domain.Session
+	if err := r.db.Where("token_hash = ?", hash).First(&s).Error; err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sessionRepository) Revoke(id uint) error {
+	return r.db.Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) Rotate(oldID uint, next *domain.Session) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.Session{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSessionRevoked
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Session{}).Where("id = ?", oldID).Update("replaced_by_id", next.ID).Error
+	})
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type ShiftRepository interface {
+	CreateTemplate(t *domain.ShiftTemplate) error
+	UpdateTemplate(t *domain.ShiftTemplate) error
+	DeleteTemplate(id uint) error
+	FindTemplate(id uint) (*domain.ShiftTemplate, error)
+	ListTemplates(activeOnly bool) ([]domain.ShiftTemplate, error)
+	TemplateInUse(id uint) (bool, error) // dipakai slot rotasi
+
+	// Pattern disimpan beserta slot-nya; Save mengganti seluruh slot dalam satu transaksi.
+	CreatePattern(p *domain.RotationPattern) error
+	SavePattern(p *domain.RotationPattern) error
+	DeletePattern(id uint) error
+	FindPattern(id uint) (*domain.RotationPattern, error)
+	ListPatterns() ([]domain.RotationPattern, error)
+}
+
+type shiftRepository struct{ db *gorm.DB }
+
+func NewShiftRepository(db *gorm.DB) ShiftRepository { return &shiftRepository{db: db} }
+
+func (r *shiftRepository) CreateTemplate(t *domain.ShiftTemplate) error { return r.db.Create(t).Error }
+func (r *shiftRepository) UpdateTemplate(t *domain.ShiftTemplate) error { return r.db.Save(t).Error }
+func (r *shiftRepository) DeleteTemplate(id uint) error {
+	return r.db.Delete(&domain.ShiftTemplate{}, id).Error
+}
+
+func (r *shiftRepository) FindTemplate(id uint) (*domain.ShiftTemplate, error) {
+	var t domain.ShiftTemplate
+	if err := r.db.First(&t, id).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *shiftRepository) ListTemplates(activeOnly bool) ([]domain.ShiftTemplate, error) {
+	q := r.db.Order("start_time ASC, name ASC")
+	if activeOnly {
+		q = q.Where("active = ?", true)
+	}
+	var out []domain.ShiftTemplate
+	return out, q.Find(&out).Error
+}
+
+func (r *shiftRepository) TemplateInUse(id uint) (bool, error) {
+	var n int64
+	err := r.db.Model(&domain.RotationSlot{}).Where("shift_template_id = ?", id).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *shiftRepository) CreatePattern(p *domain.RotationPattern) error {
+	return r.db.Create(p).Error
+}
+
+func (r *shiftRepository) SavePattern(p *domain.RotationPattern) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Slots").Save(p).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("pattern_id = ?", p.ID).Delete(&domain.RotationSlot{}).Error; err != nil {
+			return err
+		}
+		if len(p.Slots) == 0 {
+			return nil
+		}
+		for i := range p.Slots {
+			p.Slots[i].ID = 0
+			p.Slots[i].PatternID = p.ID
+		}
+		return tx.Omit("ShiftTemplate").Create(&p.Slots).Error
+	})
+}
+
+func (r *shiftRepository) DeletePattern(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("pattern_id = ?", id).Delete(&domain.RotationSlot{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.RotationPattern{}, id).Error
+	})
+}
+
+func (r *shiftRepository) FindPattern(id uint) (*domain.RotationPattern, error) {
+	var p domain.RotationPattern
+	err := r.db.Preload("Slots", func(db *gorm.DB) *gorm.DB { return db.Order("day_index ASC") }).
+		Preload("Slots.ShiftTemplate").
+		First(&p, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *shiftRepository) ListPatterns() ([]domain.RotationPattern, error) {
+	var out []domain.RotationPattern
+	err := r.db.Preload("Slots", func(db *gorm.DB) *gorm.DB { return db.Order("day_index ASC") }).
+		Preload("Slots.ShiftTemplate").
+		Order("name ASC").Find(&out).Error
+	return out, err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type StaffingRepository interface {
+	List() ([]domain.StaffingRule, error)
+	FindByChannel(ch domain.WorkChannel) (*domain.StaffingRule, error)
+	Save(r *domain.StaffingRule) error
+	DeleteByChannel(ch domain.WorkChannel) error
+}
+
+type staffingRepository struct{ db *gorm.DB }
+
+func NewStaffingRepository(db *gorm.DB) StaffingRepository { return &staffingRepository{db: db} }
+
+func (r *staffingRepository) List() ([]domain.StaffingRule, error) {
+	var out []domain.StaffingRule
+	return out, r.db.Order("channel ASC").Find(&out).Error
+}
+
+func (r *staffingRepository) FindByChannel(ch domain.WorkChannel) (*domain.StaffingRule, error) {
+	var m domain.StaffingRule
+	if err := r.db.Where("channel = ?", ch).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *staffingRepository) Save(m *domain.StaffingRule) error { return r.db.Save(m).Error }
+
+func (r *staffingRepository) DeleteByChannel(ch domain.WorkChannel) error {
+	return r.db.Where("channel = ?", ch).Delete(&domain.StaffingRule{}).Error
+}
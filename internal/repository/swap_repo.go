@@ -13,6 +13,7 @@ type SwapRepository interface {
 	Update(sw *domain.SwapRequest) error
 	FindByID(id uint) (*domain.SwapRequest, error)
 	ListAll(page, size int) ([]domain.SwapRequest, int64, error)
+	ListPendingByUser(userID uint) ([]domain.SwapRequest, error)
 }
 
 type swapRepository struct{ db *gorm.DB }
@@ -36,6 +37,17 @@ func (r *swapRepository) FindByID(id uint) (*domain.SwapRequest, error) {
 	return &m, nil
 }
 
+// swap PENDING di mana user terlibat (pengaju atau target langsung)
+func (r *swapRepository) ListPendingByUser(userID uint) ([]domain.SwapRequest, error) {
+	var rows []domain.SwapRequest
+	err := r.db.
+		Where("status = ?", domain.SwapPending).
+		Where("requester_id = ? OR target_user_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
 func (r *swapRepository) ListAll(page, size int) ([]domain.SwapRequest, int64, error) {
 	if page < 1 {
 		page = 1
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+var ErrMembershipOverlap = errors.New("user sudah terdaftar di tim lain setelah tanggal tersebut")
+
+type TeamRepository interface {
+	Create(t *domain.Team) error
+	Update(t *domain.Team) error
+	// Delete mengarsipkan tim (soft delete) dan menutup keanggotaan yang
+	// masih berlaku per sehari sebelum day; yang baru mulai day atau
+	// setelahnya dihapus karena belum pernah berlaku.
+	Delete(id uint, day time.Time) error
+	FindByID(id uint) (*domain.Team, error)
+	List() ([]domain.Team, error)
+	// NameTaken: nama sudah dipakai tim lain yang belum dihapus.
+	NameTaken(name string, excludeID uint) (bool, error)
+
+	// AddMember menutup keanggotaan user yang masih berlaku (sehari sebelum
+	// m.EffectiveFrom) lalu membuat keanggotaan baru, dalam satu transaksi.
+	AddMember(m *domain.TeamMember) error
+	EndMember(teamID, userID uint, to time.Time) error
+	ListMembers(teamID uint, day time.Time) ([]domain.TeamMember, error)
+	FindTeamForUser(userID uint, day time.Time) (*domain.Team, error)
+	MemberIDs(teamIDs []uint, day time.Time) ([]uint, error)
+	ListIDsLedBy(userID uint) ([]uint, error) // tim di mana user = leader/supervisor
+}
+
+type teamRepository struct{ db *gorm.DB }
+
+func NewTeamRepository(db *gorm.DB) TeamRepository { return &teamRepository{db: db} }
+
+func (r *teamRepository) Create(t *domain.Team) error { return r.db.Create(t).Error }
+
+func (r *teamRepository) Update(t *domain.Team) error {
+	return r.db.Omit("Members").Save(t).Error
+}
+
+func (r *teamRepository) Delete(id uint, day time.Time) error {
+	d := day.Format("2006-01-02")
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ? AND effective_from >= ?", id, d).Delete(&domain.TeamMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.TeamMember{}).
+			Where("team_id = ? AND (effective_to IS NULL OR effective_to >= ?)", id, d).
+			Update("effective_to", day.AddDate(0, 0, -1)).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Team{}, id).Error
+	})
+}
+
+func (r *teamRepository) NameTaken(name string, excludeID uint) (bool, error) {
+	var n int64
+	err := r.db.Model(&domain.Team{}).Where("name = ? AND id <> ?", name, excludeID).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *teamRepository) FindByID(id uint) (*domain.Team, error) {
+	var t domain.Team
+	err := r.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("effective_from DESC, id DESC")
+	}).First(&t, id).Error
+	return &t, err
+}
+
+func (r *teamRepository) List() ([]domain.Team, error) {
+	var items []domain.Team
+	err := r.db.Order("name ASC").Find(&items).Error
+	return items, err
+}
+
+func activeOn(q *gorm.DB, day string) *gorm.DB {
+	return q.Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", day, day)
+}
+
+func (r *teamRepository) AddMember(m *domain.TeamMember) error {
+	day := m.EffectiveFrom.Format("2006-01-02")
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var later int64
+		if err := tx.Model(&domain.TeamMember{}).
+			Where("user_id = ? AND effective_from >= ?", m.UserID, day).
+			Count(&later).Error; err != nil {
+			return err
+		}
+		if later > 0 {
+			return ErrMembershipOverlap
+		}
+		prev := m.EffectiveFrom.AddDate(0, 0, -1)
+		if err := activeOn(tx.Model(&domain.TeamMember{}).Where("user_id = ?", m.UserID), day).
+			Update("effective_to", prev).Error; err != nil {
+			return err
+		}
+		return tx.Create(m).Error
+	})
+}
+
+func (r *teamRepository) EndMember(teamID, userID uint, to time.Time) error {
+	res := r.db.Model(&domain.TeamMember{}).
+		Where("team_id = ? AND user_id = ? AND effective_to IS NULL", teamID, userID).
+		Update("effective_to", to)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *teamRepository) ListMembers(teamID uint, day time.Time) ([]domain.TeamMember, error) {
+	var items []domain.TeamMember
+	err := activeOn(r.db.Where("team_id = ?", teamID), day.Format("2006-01-02")).
+		Order("user_id ASC").Find(&items).Error
+	return items, err
+}
+
+func (r *teamRepository) FindTeamForUser(userID uint, day time.Time) (*domain.Team, error) {
+	var m domain.TeamMember
+	err := activeOn(r.db.Where("user_id = ?", userID), day.Format("2006-01-02")).
+		Order("effective_from DESC").First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	var t domain.Team
+	if err := r.db.Where("active = ?", true).First(&t, m.TeamID).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *teamRepository) MemberIDs(teamIDs []uint, day time.Time) ([]uint, error) {
+	ids := []uint{}
+	if len(teamIDs) == 0 {
+		return ids, nil
+	}
+	err := activeOn(r.db.Model(&domain.TeamMember{}).Where("team_id IN ?", teamIDs), day.Format("2006-01-02")).
+		Distinct("user_id").Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *teamRepository) ListIDsLedBy(userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := r.db.Model(&domain.Team{}).
+		Where("active = ? AND (leader_id = ? OR supervisor_id = ?)", true, userID, userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
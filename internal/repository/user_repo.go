@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"bjb-backoffice/internal/domain"
 
 	"gorm.io/gorm"
@@ -8,16 +10,30 @@ import (
 
 type UserRepository interface {
 	Create(u *domain.User) error
+	CreateMany(users []*domain.User) error // satu transaksi (bulk import)
+	ExistingEmails(emails []string) (map[string]bool, error)
+	ListAll() ([]domain.User, error)
 	FindByEmail(email string) (*domain.User, error)
 	FindByID(id uint) (*domain.User, error)
+	FindByIDWithArchived(id uint) (*domain.User, error)
+	FindByEmailWithArchived(email string) (*domain.User, error)
+	NamesByIDs(ids []uint) (map[uint]string, error) // termasuk user yang diarsipkan
+	ListByIDsWithArchived(ids []uint) ([]domain.User, error)
 	List(page, size int) ([]domain.User, int64, error)
+	ListActive(page, size int) ([]domain.User, int64, error)
+	IsActive(id uint) (bool, error)
 	AssignRoles(userID uint, roles []domain.Role) error
 	Update(u *domain.User) error                       // 👈 tetap
 	UpdateFields(id uint, fields map[string]any) error // 👈 tetap
-	Delete(id uint) error
-	ListBackofficeIDs() ([]uint, error)
+	Delete(id uint) error                              // soft delete (arsip)
+	Archive(id, by uint) error
+	Restore(id, by uint) error
+	ListArchived(page, size int) ([]domain.User, int64, error)
+
+	// ID user AKTIF yang punya salah satu role tsb (fan-out notifikasi)
+	ListActiveIDsByRoles(roles ...domain.RoleName) ([]uint, error)
 
-	// 👇 NEW: dipakai untuk broadcast notif ke semua user
+	// 👇 NEW: dipakai untuk broadcast notif ke semua user (hanya yang aktif)
 	ListAllIDs() ([]uint, error)
 }
 
@@ -27,6 +43,40 @@ func NewUserRepository(db *gorm.DB) UserRepository { return &userRepository{db:
 
 func (r *userRepository) Create(u *domain.User) error { return r.db.Create(u).Error }
 
+func (r *userRepository) CreateMany(users []*domain.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, u := range users {
+			if err := tx.Create(u).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExistingEmails mengembalikan email (lowercase) yang sudah dipakai, termasuk user arsip.
+func (r *userRepository) ExistingEmails(emails []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	if len(emails) == 0 {
+		return out, nil
+	}
+	var found []string
+	if err := r.db.Unscoped().Model(&domain.User{}).
+		Where("LOWER(email) IN ?", emails).Pluck("LOWER(email)", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range found {
+		out[e] = true
+	}
+	return out, nil
+}
+
+func (r *userRepository) ListAll() ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Preload("Roles").Order("full_name ASC").Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
 	var u domain.User
 	err := r.db.Preload("Roles").Where("email = ?", email).First(&u).Error
@@ -39,6 +89,45 @@ func (r *userRepository) FindByID(id uint) (*domain.User, error) {
 	return &u, err
 }
 
+func (r *userRepository) FindByIDWithArchived(id uint) (*domain.User, error) {
+	var u domain.User
+	err := r.db.Unscoped().Preload("Roles").First(&u, id).Error
+	return &u, err
+}
+
+func (r *userRepository) ListByIDsWithArchived(ids []uint) ([]domain.User, error) {
+	var out []domain.User
+	if len(ids) == 0 {
+		return out, nil
+	}
+	err := r.db.Unscoped().Preload("Roles").Where("id IN ?", ids).Find(&out).Error
+	return out, err
+}
+
+func (r *userRepository) FindByEmailWithArchived(email string) (*domain.User, error) {
+	var u domain.User
+	err := r.db.Unscoped().Where("email = ?", email).First(&u).Error
+	return &u, err
+}
+
+func (r *userRepository) NamesByIDs(ids []uint) (map[uint]string, error) {
+	out := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		ID       uint
+		FullName string
+	}
+	if err := r.db.Unscoped().Model(&domain.User{}).Select("id, full_name").Where("id IN ?", ids).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.ID] = row.FullName
+	}
+	return out, nil
+}
+
 func (r *userRepository) List(page, size int) ([]domain.User, int64, error) {
 	var (
 		users []domain.User
@@ -52,6 +141,26 @@ func (r *userRepository) List(page, size int) ([]domain.User, int64, error) {
 	return users, total, err
 }
 
+func (r *userRepository) ListActive(page, size int) ([]domain.User, int64, error) {
+	var (
+		users []domain.User
+		total int64
+	)
+	q := r.db.Model(&domain.User{}).Where("active = ?", true)
+	q.Count(&total)
+	err := q.Preload("Roles").
+		Limit(size).Offset((page - 1) * size).
+		Order("id DESC").
+		Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepository) IsActive(id uint) (bool, error) {
+	var active bool
+	err := r.db.Model(&domain.User{}).Select("active").Where("id = ?", id).Scan(&active).Error
+	return active, err
+}
+
 func (r *userRepository) AssignRoles(userID uint, roles []domain.Role) error {
 	var user domain.User
 	if err := r.db.First(&user, userID).Error; err != nil {
@@ -64,6 +173,55 @@ func (r *userRepository) Delete(id uint) error {
 	return r.db.Delete(&domain.User{}, id).Error
 }
 
+// Archive menonaktifkan & meng-soft-delete user dalam satu transaksi.
+func (r *userRepository) Archive(id, by uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]any{
+			"active":         false,
+			"deactivated_at": now,
+			"deactivated_by": by,
+			"archived_by":    by,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.User{}, id).Error
+	})
+}
+
+func (r *userRepository) Restore(id, by uint) error {
+	res := r.db.Unscoped().Model(&domain.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]any{
+			"deleted_at":     nil,
+			"archived_by":    nil,
+			"active":         true,
+			"reactivated_at": time.Now(),
+			"reactivated_by": by,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) ListArchived(page, size int) ([]domain.User, int64, error) {
+	var (
+		users []domain.User
+		total int64
+	)
+	q := r.db.Unscoped().Model(&domain.User{}).Where("deleted_at IS NOT NULL")
+	q.Count(&total)
+	err := q.Preload("Roles").
+		Limit(size).Offset((page - 1) * size).
+		Order("deleted_at DESC").
+		Find(&users).Error
+	return users, total, err
+}
+
 func (r *userRepository) Update(u *domain.User) error {
 	return r.db.Save(u).Error
 }
@@ -72,31 +230,28 @@ func (r *userRepository) UpdateFields(id uint, fields map[string]any) error {
 	return r.db.Model(&domain.User{}).Where("id = ?", id).Updates(fields).Error
 }
 
-// 👇 NEW: ambil semua user.id (tidak peduli role) untuk broadcast
+// 👇 NEW: ambil semua user.id aktif (tidak peduli role) untuk broadcast
 func (r *userRepository) ListAllIDs() ([]uint, error) {
 	var ids []uint
-	if err := r.db.Model(&domain.User{}).Pluck("id", &ids).Error; err != nil {
+	if err := r.db.Model(&domain.User{}).Where("active = ?", true).Pluck("id", &ids).Error; err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
-func (r *userRepository) ListBackofficeIDs() ([]uint, error) {
-	type row struct{ ID uint }
-	var rows []row
-	// ambil user yg punya role BACKOFFICE
+func (r *userRepository) ListActiveIDsByRoles(roles ...domain.RoleName) ([]uint, error) {
+	if len(roles) == 0 {
+		return []uint{}, nil
+	}
+	var ids []uint
 	err := r.db.Table("users").
-		Select("users.id").
+		Distinct("users.id").
 		Joins("JOIN user_roles ur ON ur.user_id = users.id").
 		Joins("JOIN roles r ON r.id = ur.role_id").
-		Where("r.name = ?", "BACKOFFICE").
-		Scan(&rows).Error
+		Where("users.active = ? AND r.name IN ?", true, roles).
+		Pluck("users.id", &ids).Error
 	if err != nil {
 		return nil, err
 	}
-	out := make([]uint, 0, len(rows))
-	for _, v := range rows {
-		out = append(out, v.ID)
-	}
-	return out, nil
+	return ids, nil
 }
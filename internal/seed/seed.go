@@ -1,6 +1,7 @@
 package seed
 
 import (
+	"context"
 	"log"
 
 	"bjb-backoffice/internal/domain"
@@ -20,7 +21,7 @@ func Bootstrap(roles repository.RoleRepository, users repository.UserRepository,
 
 	// ensure super admin user
 	if _, err := users.FindByEmail(superAdminEmail); err != nil {
-		_, err2 := userSvc.CreateUser(service.CreateUserInput{
+		_, err2 := userSvc.CreateUser(context.Background(), service.CreateUserInput{
 			FullName: "Super Admin",
 			Email:    superAdminEmail,
 			Password: superAdminPass,
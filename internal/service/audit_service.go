@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+type AuditService struct{ repo repository.AuditRepository }
+
+func NewAuditService(repo repository.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record mencatat satu mutasi; actor diambil dari principal di ctx (tanpa
+// principal = sistem). before/after di-marshal ke JSON (nil = tidak ada).
+// Gagal mencatat hanya di-log supaya operasi bisnis tidak ikut gagal.
+// Aman dipanggil pada *AuditService nil.
+func (s *AuditService) Record(ctx context.Context, action, entityType string, entityID uint, before, after any) {
+	if s == nil {
+		return
+	}
+	actor := auth.ActorID(ctx)
+	entry := &domain.AuditLog{
+		ActorID:    actorOrNil(actor),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     auditJSON(before),
+		After:      auditJSON(after),
+	}
+	if err := s.repo.Create(entry); err != nil {
+		log.Printf("[audit] FAILED action=%s %s#%d actor=%d err=%v", action, entityType, entityID, actor, err)
+	}
+}
+
+func (s *AuditService) List(f repository.AuditFilter) ([]domain.AuditLog, int64, error) {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Size < 1 || f.Size > 200 {
+		f.Size = 50
+	}
+	return s.repo.List(f)
+}
+
+func auditJSON(v any) *string {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+	s := string(b)
+	return &s
+}
@@ -1,9 +1,17 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
+	"sync"
 	"time"
 
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
 
@@ -11,15 +19,72 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidRefresh     = errors.New("invalid refresh token")
+	ErrSessionInvalid     = errors.New("session expired or revoked")
+	ErrAccountInactive    = errors.New("account is inactive")
+)
+
+// dummyPasswordHash = hash pembanding untuk email yang tidak terdaftar (cost sama
+// dengan hash user sungguhan).
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	b, _ := bcrypt.GenerateFromPassword([]byte("bjb-backoffice-dummy"), bcrypt.DefaultCost)
+	return b
+})
+
+// CredentialsError = ErrInvalidCredentials beserta jeda sebelum percobaan
+// berikutnya diterima (delay progresif login guard).
+type CredentialsError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CredentialsError) Error() string        { return ErrInvalidCredentials.Error() }
+func (e *CredentialsError) Is(target error) bool { return target == ErrInvalidCredentials }
+
 type AuthService struct {
-	users     repository.UserRepository
-	jwtSecret []byte
-	issuer    string
-	expHours  int
+	users      repository.UserRepository
+	sessions   repository.SessionRepository
+	teams      repository.TeamRepository
+	guard      *LoginGuardService
+	jwtSecret  []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewAuthService(
+	users repository.UserRepository,
+	sessions repository.SessionRepository,
+	teams repository.TeamRepository,
+	guard *LoginGuardService,
+	jwtSecret, issuer string,
+	accessMinutes, refreshHours int,
+) *AuthService {
+	return &AuthService{
+		users:      users,
+		sessions:   sessions,
+		teams:      teams,
+		guard:      guard,
+		jwtSecret:  []byte(jwtSecret),
+		issuer:     issuer,
+		accessTTL:  time.Duration(accessMinutes) * time.Minute,
+		refreshTTL: time.Duration(refreshHours) * time.Hour,
+	}
 }
 
-func NewAuthService(users repository.UserRepository, jwtSecret, issuer string, expH int) *AuthService {
-	return &AuthService{users: users, jwtSecret: []byte(jwtSecret), issuer: issuer, expHours: expH}
+// SessionMeta = info client yang disimpan bersama session (untuk audit/daftar device).
+type SessionMeta struct {
+	IP        string
+	UserAgent string
+}
+
+// TokenPair dikembalikan saat login & refresh.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
 }
 
 func (a *AuthService) GeneratePasswordHash(pw string) (string, error) {
@@ -27,20 +92,155 @@ func (a *AuthService) GeneratePasswordHash(pw string) (string, error) {
 	return string(b), err
 }
 
-func (a *AuthService) Login(email, password string) (string, *domain.User, error) {
+// Login memverifikasi kredensial. Bila akun/IP sedang dikunci, error-nya *LockedError.
+func (a *AuthService) Login(email, password string, meta SessionMeta) (*TokenPair, *domain.User, error) {
+	if err := a.guard.Check(email, meta); err != nil {
+		return nil, nil, err
+	}
+
 	u, err := a.users.FindByEmail(email)
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		// tetap jalankan bcrypt supaya waktu respon tidak membocorkan email terdaftar
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return nil, nil, &CredentialsError{RetryAfter: a.guard.Fail(email, meta, nil, domain.LoginReasonUnknownEmail)}
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return nil, nil, &CredentialsError{RetryAfter: a.guard.Fail(email, meta, &u.ID, domain.LoginReasonBadPassword)}
+	}
+	if !u.Active {
+		a.guard.Reject(email, meta, u.ID, domain.LoginReasonInactive)
+		return nil, nil, ErrAccountInactive
+	}
+
+	pair, err := a.startSession(u, meta)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.guard.Succeed(email, meta, u.ID)
+	return pair, u, nil
+}
+
+// Refresh menukar refresh token dengan pasangan token baru (rotasi).
+// Refresh token lama langsung dicabut; kalau token yang sudah dicabut dipakai lagi,
+// dianggap bocor → semua session user tsb ikut dicabut.
+func (a *AuthService) Refresh(refreshToken string, meta SessionMeta) (*TokenPair, *domain.User, error) {
+	if refreshToken == "" {
+		return nil, nil, ErrInvalidRefresh
+	}
+	sess, err := a.sessions.FindByTokenHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, nil, ErrInvalidRefresh
+	}
+	if sess.RevokedAt != nil {
+		log.Printf("[auth] refresh token reuse detected session=%d uid=%d; revoking all sessions", sess.ID, sess.UserID)
+		_ = a.sessions.RevokeAllForUser(sess.UserID)
+		return nil, nil, ErrInvalidRefresh
+	}
+	if !sess.Active(time.Now()) {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	// ambil ulang user supaya roles di access token selalu terbaru
+	u, err := a.users.FindByID(sess.UserID)
+	if err != nil || !u.Active {
+		return nil, nil, ErrInvalidRefresh
+	}
+
+	raw, next, err := a.newSession(u.ID, meta)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := a.sessions.Rotate(sess.ID, next); err != nil {
+		if errors.Is(err, repository.ErrSessionRevoked) {
+			return nil, nil, ErrInvalidRefresh
+		}
+		return nil, nil, err
+	}
+	pair, err := a.issuePair(u, next, raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, u, nil
+}
+
+// Logout mencabut session milik refresh token tsb. Token yang tidak dikenal diabaikan.
+func (a *AuthService) Logout(refreshToken string) error {
+	if refreshToken == "" {
+		return ErrInvalidRefresh
 	}
+	sess, err := a.sessions.FindByTokenHash(hashToken(refreshToken))
+	if err != nil {
+		return nil
+	}
+	return a.sessions.Revoke(sess.ID)
+}
 
+// RevokeUserSessions mencabut semua session aktif user; access token yang masih
+// beredar ikut ditolak oleh JWTAuth karena session-nya sudah tidak aktif.
+func (a *AuthService) RevokeUserSessions(userID uint) error {
+	if err := a.sessions.RevokeAllForUser(userID); err != nil {
+		log.Printf("[auth] revoke sessions FAILED uid=%d err=%v", userID, err)
+		return err
+	}
+	log.Printf("[auth] revoke sessions OK uid=%d", userID)
+	return nil
+}
+
+// ResolvePrincipal dipanggil JWTAuth di setiap request: session harus aktif,
+// pemiliknya belum dinonaktifkan, lalu role & tim dimuat dari DB supaya
+// perubahan role berlaku tanpa menunggu access token kedaluwarsa.
+func (a *AuthService) ResolvePrincipal(sessionID, userID uint) (*auth.Principal, error) {
+	sess, err := a.sessions.FindByID(sessionID)
+	if err != nil || sess.UserID != userID || !sess.Active(time.Now()) {
+		return nil, ErrSessionInvalid
+	}
+	u, err := a.users.FindByID(userID)
+	if err != nil || !u.Active {
+		return nil, ErrAccountInactive
+	}
+	p := &auth.Principal{ID: u.ID, Email: u.Email, Name: u.FullName, SessionID: sessionID}
+	for _, r := range u.Roles {
+		p.Roles = append(p.Roles, string(r.Name))
+	}
+	if t, err := a.teams.FindTeamForUser(u.ID, clock.Now()); err == nil {
+		p.TeamID = &t.ID
+	}
+	return p, nil
+}
+
+func (a *AuthService) startSession(u *domain.User, meta SessionMeta) (*TokenPair, error) {
+	raw, sess, err := a.newSession(u.ID, meta)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.sessions.Create(sess); err != nil {
+		return nil, err
+	}
+	return a.issuePair(u, sess, raw)
+}
+
+func (a *AuthService) newSession(userID uint, meta SessionMeta) (string, *domain.Session, error) {
+	raw, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	return raw, &domain.Session{
+		UserID:    userID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(a.refreshTTL),
+		UserAgent: truncate(meta.UserAgent, 255),
+		IP:        truncate(meta.IP, 64),
+	}, nil
+}
+
+func (a *AuthService) issuePair(u *domain.User, sess *domain.Session, refreshRaw string) (*TokenPair, error) {
+	exp := time.Now().Add(a.accessTTL)
 	claims := jwt.MapClaims{
 		"sub": u.ID,
+		"sid": sess.ID,
 		"iss": a.issuer,
-		"exp": time.Now().Add(time.Duration(a.expHours) * time.Hour).Unix(),
+		"exp": exp.Unix(),
 		"roles": func() []string {
 			out := make([]string, 0, len(u.Roles))
 			for _, r := range u.Roles {
@@ -54,5 +254,34 @@ func (a *AuthService) Login(email, password string) (string, *domain.User, error
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	ss, err := token.SignedString(a.jwtSecret)
-	return ss, u, err
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:      ss,
+		AccessExpiresAt:  exp,
+		RefreshToken:     refreshRaw,
+		RefreshExpiresAt: sess.ExpiresAt,
+	}, nil
+}
+
+// randomToken menghasilkan token acak URL-safe sepanjang n byte entropi.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
 }
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// Rentang event di feed: sedikit ke belakang supaya riwayat dekat tetap ada.
+const (
+	calendarPastDays   = 60
+	calendarFutureDays = 180
+	calendarProdID     = "-//BJB Backoffice//Jadwal//ID"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+type CalendarService struct {
+	feeds     repository.CalendarRepository
+	schedules repository.ScheduleRepository
+	leaves    repository.LeaveRepository
+	holidays  repository.HolidaySwapRepository
+	users     repository.UserRepository
+	audit     *AuditService
+}
+
+func NewCalendarService(feeds repository.CalendarRepository, schedules repository.ScheduleRepository, leaves repository.LeaveRepository, holidays repository.HolidaySwapRepository, users repository.UserRepository, audit *AuditService) *CalendarService {
+	return &CalendarService{feeds: feeds, schedules: schedules, leaves: leaves, holidays: holidays, users: users, audit: audit}
+}
+
+// Feed = info feed milik user login (nil kalau belum pernah dibuat).
+func (s *CalendarService) Feed(ctx context.Context) (*domain.CalendarFeed, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.feeds.FindByUser(me.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return f, err
+}
+
+// RegenerateToken membuat token baru (token lama langsung tidak berlaku).
+// Token mentah hanya dikembalikan sekali ini.
+func (s *CalendarService) RegenerateToken(ctx context.Context) (string, *domain.CalendarFeed, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	raw, err := randomToken(24)
+	if err != nil {
+		return "", nil, err
+	}
+	f, err := s.feeds.Upsert(me.ID, hashToken(raw))
+	if err != nil {
+		return "", nil, err
+	}
+	s.audit.Record(ctx, "calendar.token_regenerate", domain.AuditUser, me.ID, nil, nil)
+	return raw, f, nil
+}
+
+func (s *CalendarService) Revoke(ctx context.Context) error {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.feeds.DeleteByUser(me.ID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "calendar.token_revoke", domain.AuditUser, me.ID, nil, nil)
+	return nil
+}
+
+// RenderICS menghasilkan isi .ics untuk token feed. User nonaktif / token
+// tidak dikenal → ErrCalendarFeedNotFound.
+func (s *CalendarService) RenderICS(rawToken string) ([]byte, error) {
+	if rawToken == "" {
+		return nil, ErrCalendarFeedNotFound
+	}
+	f, err := s.feeds.FindByHash(hashToken(rawToken))
+	if err != nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+	u, err := s.users.FindByID(f.UserID)
+	if err != nil || !u.Active {
+		return nil, ErrCalendarFeedNotFound
+	}
+	now := time.Now()
+	_ = s.feeds.Touch(f.ID, now)
+
+	from := dateOnly(now).AddDate(0, 0, -calendarPastDays)
+	to := dateOnly(now).AddDate(0, 0, calendarFutureDays)
+
+	schedules, err := s.schedules.ListByUserRange(u.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	approved := domain.LeaveApproved
+	// cuti difilter per start_date; mundur sebulan supaya cuti panjang tetap masuk
+	leaveFrom := from.AddDate(0, -1, 0)
+	leaves, _, err := s.leaves.List(&u.ID, &approved, &leaveFrom, &to, 1, 1000)
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := s.holidays.ListApprovedByUser(u.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	swapBySchedule := map[uint]uint{}
+	for _, h := range swaps {
+		if h.TargetUserID == u.ID && h.CreatedScheduleID != nil {
+			swapBySchedule[*h.CreatedScheduleID] = h.ID
+		}
+	}
+
+	stamp := icsTime(now)
+	var b icsWriter
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:" + calendarProdID)
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:PUBLISH")
+	b.line("X-WR-CALNAME:" + icsEscape("Jadwal "+u.FullName))
+	b.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	b.line("X-PUBLISHED-TTL:PT1H")
+
+	for _, sc := range schedules {
+		if sc.Status == domain.ScheduleDraft {
+			continue // belum dipublish
+		}
+		summary := fmt.Sprintf("Shift %s", sc.Channel)
+		desc := []string{"Channel: " + string(sc.Channel)}
+		if sc.ShiftName != nil && *sc.ShiftName != "" {
+			summary = fmt.Sprintf("%s (%s)", *sc.ShiftName, sc.Channel)
+			desc = append(desc, "Shift: "+*sc.ShiftName)
+		}
+		if sc.Notes != nil && *sc.Notes != "" {
+			desc = append(desc, "Catatan: "+*sc.Notes)
+		}
+		if hid, ok := swapBySchedule[sc.ID]; ok {
+			desc = append(desc, fmt.Sprintf("Dari tukar libur #%d", hid))
+		}
+		b.line("BEGIN:VEVENT")
+		b.line(fmt.Sprintf("UID:schedule-%d@bjb-backoffice", sc.ID))
+		b.line("DTSTAMP:" + stamp)
+		b.line("DTSTART:" + icsTime(sc.StartAt))
+		b.line("DTEND:" + icsTime(sc.EndAt))
+		b.line("LAST-MODIFIED:" + icsTime(sc.UpdatedAt))
+		b.line("SUMMARY:" + icsEscape(summary))
+		b.line("DESCRIPTION:" + icsEscape(strings.Join(desc, "\n")))
+		b.line("CATEGORIES:" + icsEscape(string(sc.Channel)))
+		b.line("END:VEVENT")
+	}
+	for _, l := range leaves {
+		end := clock.FromDate(l.EndDate).AddDate(0, 0, 1) // DTEND all-day eksklusif
+		if !end.After(from) {
+			continue
+		}
+		b.line("BEGIN:VEVENT")
+		b.line(fmt.Sprintf("UID:leave-%d@bjb-backoffice", l.ID))
+		b.line("DTSTAMP:" + stamp)
+		b.line("DTSTART;VALUE=DATE:" + l.StartDate.Format("20060102"))
+		b.line("DTEND;VALUE=DATE:" + end.Format("20060102"))
+		b.line("LAST-MODIFIED:" + icsTime(l.UpdatedAt))
+		b.line("SUMMARY:" + icsEscape("Cuti ("+string(l.Type)+")"))
+		if l.Reason != "" {
+			b.line("DESCRIPTION:" + icsEscape(l.Reason))
+		}
+		b.line("TRANSP:TRANSPARENT")
+		b.line("END:VEVENT")
+	}
+	for _, h := range swaps {
+		if h.RequesterID != u.ID {
+			continue // sisi target sudah tampil sebagai jadwal
+		}
+		day := dateOnly(h.OffDate)
+		b.line("BEGIN:VEVENT")
+		b.line(fmt.Sprintf("UID:holiday-swap-%d@bjb-backoffice", h.ID))
+		b.line("DTSTAMP:" + stamp)
+		b.line("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		b.line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"))
+		b.line("LAST-MODIFIED:" + icsTime(h.UpdatedAt))
+		b.line("SUMMARY:" + icsEscape("Libur (tukar libur)"))
+		if h.Reason != "" {
+			b.line("DESCRIPTION:" + icsEscape(h.Reason))
+		}
+		b.line("TRANSP:TRANSPARENT")
+		b.line("END:VEVENT")
+	}
+	b.line("END:VCALENDAR")
+	return []byte(b.String()), nil
+}
+
+// icsWriter menulis baris iCalendar (CRLF, dilipat per 75 oktet — RFC 5545 §3.1).
+type icsWriter struct{ strings.Builder }
+
+func (w *icsWriter) line(s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(s[cut]) {
+			cut-- // jangan potong di tengah karakter UTF-8
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // spasi lipatan ikut dihitung
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func utf8Start(b byte) bool { return b&0xC0 != 0x80 }
+
+func icsTime(t time.Time) string { return t.UTC().Format("20060102T150405Z") }
+
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
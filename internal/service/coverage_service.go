@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+const maxCoverageDays = 31
+
+type CoverageService struct {
+	reqs      repository.CoverageRepository
+	schedules repository.ScheduleRepository
+	leaves    repository.LeaveRepository
+	holidays  repository.PublicHolidayRepository
+	audit     *AuditService
+}
+
+func NewCoverageService(reqs repository.CoverageRepository, schedules repository.ScheduleRepository, leaves repository.LeaveRepository, holidays repository.PublicHolidayRepository, audit *AuditService) *CoverageService {
+	return &CoverageService{reqs: reqs, schedules: schedules, leaves: leaves, holidays: holidays, audit: audit}
+}
+
+// ===== Requirement CRUD =====
+
+type CoverageInput struct {
+	Channel   domain.WorkChannel
+	Days      []int // 1 = Senin … 7 = Minggu; nil = tidak diubah
+	StartTime string
+	EndTime   string
+	Headcount *int
+	// headcount hari libur: nil = tidak diubah, < 0 = kosongkan (ikut Headcount)
+	HolidayHeadcount *int
+	ValidFrom        *time.Time // zero time = kosongkan
+	ValidTo          *time.Time
+	Note             *string
+	Active           *bool
+}
+
+func (s *CoverageService) List(channel *domain.WorkChannel) ([]domain.CoverageRequirement, error) {
+	return s.reqs.List(channel, false)
+}
+
+func (s *CoverageService) Create(ctx context.Context, in CoverageInput) (*domain.CoverageRequirement, error) {
+	m := &domain.CoverageRequirement{Active: true}
+	if in.Days == nil {
+		return nil, errors.New("days required")
+	}
+	if in.Headcount == nil {
+		return nil, errors.New("headcount required")
+	}
+	if err := applyCoverageInput(m, in); err != nil {
+		return nil, err
+	}
+	if err := s.reqs.Create(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "coverage.create", domain.AuditCoverage, m.ID, nil, m)
+	return m, nil
+}
+
+func (s *CoverageService) Update(ctx context.Context, id uint, in CoverageInput) (*domain.CoverageRequirement, error) {
+	m, err := s.reqs.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *m
+	if err := applyCoverageInput(m, in); err != nil {
+		return nil, err
+	}
+	if err := s.reqs.Update(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "coverage.update", domain.AuditCoverage, m.ID, before, m)
+	return m, nil
+}
+
+func (s *CoverageService) Delete(ctx context.Context, id uint) error {
+	before, err := s.reqs.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.reqs.Delete(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "coverage.delete", domain.AuditCoverage, id, before, nil)
+	return nil
+}
+
+func applyCoverageInput(m *domain.CoverageRequirement, in CoverageInput) error {
+	if in.Channel != "" {
+		m.Channel = in.Channel
+	}
+	if m.Channel != domain.ChannelVoice && m.Channel != domain.ChannelSosmed {
+		return errors.New("channel must be VOICE or SOSMED")
+	}
+	if in.Days != nil {
+		mask := 0
+		for _, d := range in.Days {
+			if d < 1 || d > 7 {
+				return fmt.Errorf("day %d invalid (1=Senin..7=Minggu)", d)
+			}
+			mask |= 1 << (d - 1)
+		}
+		if mask == 0 {
+			return errors.New("minimal satu hari")
+		}
+		m.DayMask = mask
+	}
+	if in.StartTime != "" {
+		m.StartTime = in.StartTime
+	}
+	if in.EndTime != "" {
+		m.EndTime = in.EndTime
+	}
+	if !validHHMM(m.StartTime) || !validHHMM(m.EndTime) || m.StartTime == m.EndTime {
+		return errors.New("start_time/end_time harus HH:MM dan tidak sama")
+	}
+	if in.Headcount != nil {
+		m.Headcount = *in.Headcount
+	}
+	if m.Headcount < 1 {
+		return errors.New("headcount minimal 1")
+	}
+	if in.HolidayHeadcount != nil {
+		m.HolidayHeadcount = in.HolidayHeadcount
+		if *in.HolidayHeadcount < 0 {
+			m.HolidayHeadcount = nil
+		}
+	}
+	if in.ValidFrom != nil {
+		m.ValidFrom = nilIfZeroTime(in.ValidFrom)
+	}
+	if in.ValidTo != nil {
+		m.ValidTo = nilIfZeroTime(in.ValidTo)
+	}
+	if m.ValidFrom != nil && m.ValidTo != nil && m.ValidTo.Before(*m.ValidFrom) {
+		return errors.New("valid_to harus >= valid_from")
+	}
+	if in.Note != nil {
+		m.Note = *in.Note
+	}
+	if in.Active != nil {
+		m.Active = *in.Active
+	}
+	return nil
+}
+
+func nilIfZeroTime(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// ===== Report =====
+
+type CoverageReportInput struct {
+	From, To        time.Time // tanggal, inklusif
+	Channel         *domain.WorkChannel
+	IntervalMinutes int
+}
+
+type CoverageInterval struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Required  int       `json:"required"`
+	Scheduled int       `json:"scheduled"`
+	Diff      int       `json:"diff"`   // scheduled - required
+	Status    string    `json:"status"` // UNDER | OVER | OK
+}
+
+// CoverageGap = interval UNDER berurutan yang digabung.
+type CoverageGap struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	MaxShort int       `json:"max_short"` // kekurangan terbesar di rentang ini
+}
+
+type CoverageDay struct {
+	Date       string             `json:"date"`
+	Channel    domain.WorkChannel `json:"channel"`
+	Holiday    string             `json:"holiday,omitempty"` // nama hari libur (headcount hari libur berlaku)
+	UnderSlots int                `json:"under_slots"`
+	OverSlots  int                `json:"over_slots"`
+	Gaps       []CoverageGap      `json:"gaps"`
+	Intervals  []CoverageInterval `json:"intervals"` // hanya slot yang ada kebutuhan / jadwal
+}
+
+type CoverageSummary struct {
+	Channel    domain.WorkChannel `json:"channel"`
+	UnderSlots int                `json:"under_slots"`
+	OverSlots  int                `json:"over_slots"`
+	OKSlots    int                `json:"ok_slots"`
+	WorstShort int                `json:"worst_short"`
+}
+
+type CoverageReport struct {
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	IntervalMinutes int               `json:"interval_minutes"`
+	Summary         []CoverageSummary `json:"summary"`
+	Days            []CoverageDay     `json:"days"`
+}
+
+// Report membandingkan kebutuhan headcount dengan jadwal (user aktif, dikurangi
+// yang cuti APPROVED pada tanggal shift-nya) per slot interval. Agent dihitung
+// pada slot yang tertutup penuh oleh shift-nya.
+func (s *CoverageService) Report(in CoverageReportInput) (*CoverageReport, error) {
+	if in.IntervalMinutes == 0 {
+		in.IntervalMinutes = 30
+	}
+	if in.IntervalMinutes < 15 || 1440%in.IntervalMinutes != 0 {
+		return nil, errors.New("interval harus >= 15 menit dan membagi habis 24 jam")
+	}
+	from, to := dateOnly(in.From), dateOnly(in.To)
+	if to.Before(from) {
+		return nil, errors.New("to harus >= from")
+	}
+	if daysBetween(from, to)+1 > maxCoverageDays {
+		return nil, fmt.Errorf("rentang maksimal %d hari", maxCoverageDays)
+	}
+	channels := []domain.WorkChannel{domain.ChannelVoice, domain.ChannelSosmed}
+	if in.Channel != nil {
+		channels = []domain.WorkChannel{*in.Channel}
+	}
+	end := to.AddDate(0, 0, 1)
+
+	reqs, err := s.reqs.List(in.Channel, true)
+	if err != nil {
+		return nil, err
+	}
+	// shift/requirement malam dari hari sebelumnya ikut dihitung
+	schedules, err := s.schedules.ListActiveRange(from.AddDate(0, 0, -1), end, in.Channel)
+	if err != nil {
+		return nil, err
+	}
+	onLeave, err := s.leaveDays(from.AddDate(0, 0, -1), end)
+	if err != nil {
+		return nil, err
+	}
+	offDays, err := holidayDays(s.holidays, from.AddDate(0, 0, -1), end)
+	if err != nil {
+		return nil, err
+	}
+
+	step := time.Duration(in.IntervalMinutes) * time.Minute
+	rep := &CoverageReport{
+		From: from.Format("2006-01-02"), To: to.Format("2006-01-02"),
+		IntervalMinutes: in.IntervalMinutes, Days: []CoverageDay{},
+	}
+	for _, ch := range channels {
+		windows, err := requirementWindows(reqs, ch, from.AddDate(0, 0, -1), end, offDays)
+		if err != nil {
+			return nil, err
+		}
+		var shifts []domain.Schedule
+		for _, sc := range schedules {
+			if sc.Channel == ch && !onLeave[leaveKey(sc.UserID, sc.StartAt)] {
+				shifts = append(shifts, sc)
+			}
+		}
+		sum := CoverageSummary{Channel: ch}
+		for day := from; day.Before(end); day = day.AddDate(0, 0, 1) {
+			cd := CoverageDay{Date: day.Format("2006-01-02"), Channel: ch, Gaps: []CoverageGap{}, Intervals: []CoverageInterval{}}
+			if h, ok := offDays[holidayKey(day)]; ok {
+				cd.Holiday = h.Name
+			}
+			next := day.AddDate(0, 0, 1)
+			var gap *CoverageGap
+			for t := day; t.Before(next); t = t.Add(step) {
+				slotEnd := t.Add(step)
+				iv := CoverageInterval{Start: t, End: slotEnd}
+				for _, w := range windows {
+					if !w.start.After(t) && w.end.After(t) {
+						iv.Required += w.headcount
+					}
+				}
+				seen := map[uint]bool{}
+				for _, sc := range shifts {
+					if !sc.StartAt.After(t) && !sc.EndAt.Before(slotEnd) && !seen[sc.UserID] {
+						seen[sc.UserID] = true
+						iv.Scheduled++
+					}
+				}
+				if iv.Required == 0 && iv.Scheduled == 0 {
+					gap = nil
+					continue
+				}
+				iv.Diff = iv.Scheduled - iv.Required
+				switch {
+				case iv.Diff < 0:
+					iv.Status = "UNDER"
+					cd.UnderSlots++
+					if gap == nil {
+						cd.Gaps = append(cd.Gaps, CoverageGap{Start: t})
+						gap = &cd.Gaps[len(cd.Gaps)-1]
+					}
+					gap.End = slotEnd
+					if -iv.Diff > gap.MaxShort {
+						gap.MaxShort = -iv.Diff
+					}
+					if -iv.Diff > sum.WorstShort {
+						sum.WorstShort = -iv.Diff
+					}
+				case iv.Diff > 0 && iv.Required > 0:
+					iv.Status = "OVER"
+					cd.OverSlots++
+					gap = nil
+				default:
+					iv.Status = "OK"
+					sum.OKSlots++
+					gap = nil
+				}
+				cd.Intervals = append(cd.Intervals, iv)
+			}
+			sum.UnderSlots += cd.UnderSlots
+			sum.OverSlots += cd.OverSlots
+			rep.Days = append(rep.Days, cd)
+		}
+		rep.Summary = append(rep.Summary, sum)
+	}
+	return rep, nil
+}
+
+type reqWindow struct {
+	start, end time.Time
+	headcount  int
+}
+
+// requirementWindows menjabarkan requirement channel ch ke rentang waktu
+// konkret untuk setiap tanggal di [from, to). Tanggal mulai yang ada di
+// holidays memakai headcount hari libur.
+func requirementWindows(reqs []domain.CoverageRequirement, ch domain.WorkChannel, from, to time.Time, holidays map[string]domain.PublicHoliday) ([]reqWindow, error) {
+	var out []reqWindow
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, r := range reqs {
+			if r.Channel != ch || !r.AppliesOn(day) {
+				continue
+			}
+			st, en, err := r.Window(day, clock.Location())
+			if err != nil {
+				return nil, err
+			}
+			_, holiday := holidays[holidayKey(day)]
+			if n := r.HeadcountOn(holiday); n > 0 {
+				out = append(out, reqWindow{start: st, end: en, headcount: n})
+			}
+		}
+	}
+	return out, nil
+}
+
+// leaveDays = set "user|tanggal" untuk cuti APPROVED di [from, to).
+func (s *CoverageService) leaveDays(from, to time.Time) (map[string]bool, error) {
+	return approvedLeaveDays(s.leaves, from, to)
+}
+
+func approvedLeaveDays(repo repository.LeaveRepository, from, to time.Time) (map[string]bool, error) {
+	leaves, err := repo.ListApprovedBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]bool{}
+	for _, l := range leaves {
+		for d := clock.FromDate(l.StartDate); !d.After(clock.FromDate(l.EndDate)); d = d.AddDate(0, 0, 1) {
+			out[leaveKey(l.RequesterID, d)] = true
+		}
+	}
+	return out, nil
+}
+
+func leaveKey(userID uint, t time.Time) string {
+	return fmt.Sprintf("%d|%s", userID, t.In(clock.Location()).Format("2006-01-02"))
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -8,9 +9,14 @@ import (
 	"bjb-backoffice/internal/repository"
 )
 
-type CWCService struct{ repo repository.CWCRepository }
+type CWCService struct {
+	repo  repository.CWCRepository
+	audit *AuditService
+}
 
-func NewCWCService(repo repository.CWCRepository) *CWCService { return &CWCService{repo: repo} }
+func NewCWCService(repo repository.CWCRepository, audit *AuditService) *CWCService {
+	return &CWCService{repo: repo, audit: audit}
+}
 
 const (
 	CatComplaint = "COMPLAINT"
@@ -90,7 +96,7 @@ func (s *CWCService) CategoriesDef() map[string][]string {
 	}
 }
 
-func (s *CWCService) UpsertDaily(in UpsertDailyInput) error {
+func (s *CWCService) UpsertDaily(ctx context.Context, in UpsertDailyInput) error {
 	// validasi panjang sesuai urutan
 	if len(in.Complaint) != len(ComplaintOrder) {
 		return errors.New("len complaint tidak sesuai definisi")
@@ -113,7 +119,14 @@ func (s *CWCService) UpsertDaily(in UpsertDailyInput) error {
 		batch = append(batch, domain.CWCEntry{Category: CatInfo, SubKey: sub, Count: in.Info[i]})
 	}
 
-	return s.repo.UpsertBatch(in.Date, batch)
+	before, _ := s.GetDaily(in.Date)
+	if err := s.repo.UpsertBatch(in.Date, batch); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "cwc.upsert", domain.AuditCWC, 0,
+		map[string]any{"date": in.Date.Format("2006-01-02"), "counts": before},
+		map[string]any{"date": in.Date.Format("2006-01-02"), "counts": map[string][]int{CatComplaint: in.Complaint, CatRequest: in.Request, CatInfo: in.Info}})
+	return nil
 }
 
 type CWCRow struct {
@@ -200,6 +213,11 @@ func (s *CWCService) GetDaily(date time.Time) (map[string][]int, error) {
 	return out, nil
 }
 
-func (s *CWCService) DeleteDaily(date time.Time) error {
-	return s.repo.DeleteByDate(date)
+func (s *CWCService) DeleteDaily(ctx context.Context, date time.Time) error {
+	before, _ := s.GetDaily(date)
+	if err := s.repo.DeleteByDate(date); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "cwc.delete", domain.AuditCWC, 0, map[string]any{"date": date.Format("2006-01-02"), "counts": before}, nil)
+	return nil
 }
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
 )
@@ -11,34 +14,36 @@ import (
 type FindingService struct {
 	findings repository.FindingRepository
 	users    repository.UserRepository
+	audit    *AuditService
 }
 
-func NewFindingService(findings repository.FindingRepository, users repository.UserRepository) *FindingService {
-	return &FindingService{findings: findings, users: users}
+func NewFindingService(findings repository.FindingRepository, users repository.UserRepository, audit *AuditService) *FindingService {
+	return &FindingService{findings: findings, users: users, audit: audit}
 }
 
 type CreateFindingInput struct {
 	AgentID     uint
-	IssuedByID  uint
 	Description string
 	IssuedAt    *time.Time // optional; default now
 }
 
-func (s *FindingService) Create(in CreateFindingInput) (*domain.Finding, error) {
-	if in.AgentID == 0 || in.IssuedByID == 0 || in.Description == "" {
-		return nil, errors.New("agent_id/issued_by/description required")
+// Create: issuer = user yang login (QC), diambil dari ctx.
+func (s *FindingService) Create(ctx context.Context, in CreateFindingInput) (*domain.Finding, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if in.AgentID == 0 || in.Description == "" {
+		return nil, errors.New("agent_id/description required")
 	}
-	// validasi agent & qc eksis (optional: cek role mereka)
+	// validasi agent eksis (optional: cek role)
 	if _, err := s.users.FindByID(in.AgentID); err != nil {
 		return nil, errors.New("agent not found")
 	}
-	if _, err := s.users.FindByID(in.IssuedByID); err != nil {
-		return nil, errors.New("issuer not found")
-	}
 
 	f := &domain.Finding{
 		AgentID:     in.AgentID,
-		IssuedByID:  in.IssuedByID,
+		IssuedByID:  me.ID,
 		Description: in.Description,
 	}
 	if in.IssuedAt != nil {
@@ -48,24 +53,33 @@ func (s *FindingService) Create(in CreateFindingInput) (*domain.Finding, error)
 	if err := s.findings.Create(f); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "finding.create", domain.AuditFinding, f.ID, nil, f)
 	return f, nil
 }
 
-func (s *FindingService) Delete(id uint) error { return s.findings.Delete(id) }
+func (s *FindingService) Delete(ctx context.Context, id uint) error {
+	before, _ := s.findings.FindByID(id)
+	if err := s.findings.Delete(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "finding.delete", domain.AuditFinding, id, before, nil)
+	return nil
+}
 
 type ListFindingsFilter struct {
-	AgentID *uint
-	Month   *time.Time // jika diisi → override from/to
-	From    *time.Time
-	To      *time.Time
-	Page    int
-	Size    int
+	AgentID  *uint
+	AgentIDs []uint     // filter tim (nil = semua)
+	Month    *time.Time // jika diisi → override from/to
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	Size     int
 }
 
 func (s *FindingService) ListFiltered(f ListFindingsFilter) ([]domain.Finding, int64, error) {
 	from, to := f.From, f.To
 	if f.Month != nil {
-		start := time.Date(f.Month.Year(), f.Month.Month(), 1, 0, 0, 0, 0, time.Local)
+		start := clock.MonthStart(*f.Month)
 		next := start.AddDate(0, 1, 0)
 		from, to = &start, &next
 	}
@@ -76,7 +90,7 @@ func (s *FindingService) ListFiltered(f ListFindingsFilter) ([]domain.Finding, i
 		f.Size = 10
 	}
 
-	return s.findings.ListFiltered(f.AgentID, from, to, f.Page, f.Size)
+	return s.findings.ListFiltered(f.AgentID, f.AgentIDs, from, to, f.Page, f.Size)
 }
 
 func (s *FindingService) CountForAgentInMonth(agentID uint, month time.Time) (int64, error) {
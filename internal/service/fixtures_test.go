@@ -0,0 +1,19 @@
+package service
+
+import (
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+)
+
+// fixture bersama untuk table test paket service: semua jam pada
+// Oktober 2026 di zona organisasi.
+
+// octAt = tanggal d Oktober 2026 jam h:m.
+func octAt(d, h, m int) time.Time { return time.Date(2026, 10, d, h, m, 0, 0, clock.Location()) }
+
+// testShift = jadwal user uid di [from, to).
+func testShift(uid uint, from, to time.Time) domain.Schedule {
+	return domain.Schedule{UserID: uid, StartAt: from, EndAt: to}
+}
@@ -1,13 +1,17 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 type HolidaySwapService struct {
@@ -15,6 +19,9 @@ type HolidaySwapService struct {
 	sched *ScheduleService
 	notif *NotificationService
 	users repository.UserRepository
+	teams *TeamService
+	staff *StaffingService
+	audit *AuditService
 }
 
 func NewHolidaySwapService(
@@ -22,75 +29,67 @@ func NewHolidaySwapService(
 	sched *ScheduleService,
 	notif *NotificationService,
 	users repository.UserRepository,
+	teams *TeamService,
+	staff *StaffingService,
+	audit *AuditService,
 ) *HolidaySwapService {
-	return &HolidaySwapService{repo: repo, sched: sched, notif: notif, users: users}
+	return &HolidaySwapService{repo: repo, sched: sched, notif: notif, users: users, teams: teams, staff: staff, audit: audit}
 }
 
-func (s *HolidaySwapService) getName(uid uint) string {
-	if s.users == nil || uid == 0 {
-		return fmt.Sprintf("Agent #%d", uid)
-	}
-	u, err := s.users.FindByID(uid)
-	if err != nil || u == nil || u.FullName == "" {
-		return fmt.Sprintf("Agent #%d", uid)
+// requesterOffDay: jadwal milik requester pada tanggal OFF (yang akan dihapus).
+// Ikut tanggal bisnis: shift malam tanggal OFF ikut terhapus, shift malam hari
+// sebelumnya yang melimpah ke tanggal OFF tidak.
+func (s *HolidaySwapService) requesterOffDay(m *domain.HolidaySwap) []domain.Schedule {
+	items, err := s.sched.OwnedOn(m.RequesterID, m.OffDate)
+	if err != nil {
+		return nil
 	}
-	return u.FullName
+	return items
 }
 
-// roles BO yang dianggap "backoffice"
-func (s *HolidaySwapService) isBO(name string) bool {
-	allowed := map[string]bool{
-		"SUPER_ADMIN": true,
-		"HR_ADMIN":    true,
-		"TL":          true,
-		"SPV":         true,
-		"QC":          true,
-		// opsional bila ada:
-		"BACKOFFICE": true,
-		"ADMIN":      true,
-	}
-	return allowed[strings.ToUpper(strings.TrimSpace(name))]
+func (s *HolidaySwapService) getName(uid uint) string {
+	return displayName(s.users, uid, "Agent")
 }
 
-func (s *HolidaySwapService) getBackofficeIDs() []uint {
-	out := []uint{}
-	if s.users == nil {
-		return out
-	}
-	users, _, err := s.users.List(1, 2000)
-	if err != nil {
-		return out
+// TL/SPV tim requester & target (fallback: semua backoffice aktif)
+func (s *HolidaySwapService) reviewerIDs(uids ...uint) []uint {
+	if s.teams == nil {
+		return []uint{}
 	}
-	for _, u := range users {
-		for _, r := range u.Roles {
-			if s.isBO(string(r.Name)) {
-				out = append(out, u.ID)
-				break
-			}
-		}
-	}
-	return out
+	return s.teams.ReviewerIDsFor(uids...)
 }
 
-// helper: apakah dua waktu berada pada tanggal lokal yang sama
-func sameLocalDay(a, b time.Time) bool {
-	al := a.In(time.Local)
-	bl := b.In(time.Local)
-	return al.Year() == bl.Year() && al.Month() == bl.Month() && al.Day() == bl.Day()
+func (s *HolidaySwapService) ensureActive(uid uint, who string) error {
+	if s.users == nil {
+		return nil
+	}
+	if ok, err := s.users.IsActive(uid); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%s tidak aktif", who)
+	}
+	return nil
 }
 
-// Create request: requester ambil OFF-nya target di tanggal offDate (format: YYYY-MM-DD, local)
-func (s *HolidaySwapService) Create(requester, target uint, offDate time.Time, reason string) (*domain.HolidaySwap, error) {
-	if requester == 0 || target == 0 || requester == target {
+// Create request: requester (user yang login) ambil OFF-nya target di tanggal offDate (format: YYYY-MM-DD, local)
+func (s *HolidaySwapService) Create(ctx context.Context, target uint, offDate time.Time, reason string) (*domain.HolidaySwap, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requester := p.ID
+	if target == 0 || requester == target {
 		return nil, errors.New("invalid requester/target")
 	}
-	dayStart := time.Date(offDate.Year(), offDate.Month(), offDate.Day(), 0, 0, 0, 0, offDate.Location())
-	dayEnd := dayStart.Add(24 * time.Hour)
+	if err := s.ensureActive(target, "target"); err != nil {
+		return nil, err
+	}
+	dayStart := clock.DayOf(offDate)
 
-	// target harus OFF di hari tsb
-	if ok, err := s.sched.ExistsOverlap(target, dayStart, dayEnd, nil); err != nil {
+	// target harus OFF di hari tsb (limpahan shift malam kemarin tidak dihitung)
+	if items, err := s.sched.OwnedOn(target, dayStart); err != nil {
 		return nil, err
-	} else if ok {
+	} else if len(items) > 0 {
 		return nil, errors.New("target tidak OFF pada tanggal tersebut")
 	}
 
@@ -104,6 +103,7 @@ func (s *HolidaySwapService) Create(requester, target uint, offDate time.Time, r
 	if err := s.repo.Create(m); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "holiday_swap.create", domain.AuditHoliday, m.ID, nil, m)
 
 	// Notif: A, B, BO
 	if s.notif != nil {
@@ -113,7 +113,7 @@ func (s *HolidaySwapService) Create(requester, target uint, offDate time.Time, r
 			s.getName(requester), s.getName(target), dayStart.Format("02 Jan 2006"))
 		_ = s.notif.Notify(requester, title, body, "HOLIDAY_SWAP", &ref)
 		_ = s.notif.Notify(target, title, body, "HOLIDAY_SWAP", &ref)
-		for _, bid := range s.getBackofficeIDs() {
+		for _, bid := range s.reviewerIDs(m.RequesterID, m.TargetUserID) {
 			if bid != requester && bid != target {
 				_ = s.notif.Notify(bid, title, body, "HOLIDAY_SWAP", &ref)
 			}
@@ -122,7 +122,12 @@ func (s *HolidaySwapService) Create(requester, target uint, offDate time.Time, r
 	return m, nil
 }
 
-func (s *HolidaySwapService) TargetAccept(id uint, me uint) (*domain.HolidaySwap, error) {
+func (s *HolidaySwapService) TargetAccept(ctx context.Context, id uint) (*domain.HolidaySwap, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	me := p.ID
 	m, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -130,26 +135,33 @@ func (s *HolidaySwapService) TargetAccept(id uint, me uint) (*domain.HolidaySwap
 	if m.Status != domain.HolidayPendingTarget || m.TargetUserID != me {
 		return nil, errors.New("tidak berhak atau status bukan PENDING_TARGET")
 	}
+	before := *m
 	m.Status = domain.HolidayPendingBO
 	if err := s.repo.Update(m); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "holiday_swap.target_accept", domain.AuditHoliday, m.ID, before, m)
 
 	if s.notif != nil {
 		ref := m.ID
 		title := "Tukar Libur • Disetujui Target"
 		body := fmt.Sprintf("Target %s menyetujui permintaan tukar libur pada %s. Menunggu persetujuan Backoffice.",
-			s.getName(me), m.OffDate.Format("02 Jan 2006"))
+			s.getName(me), m.OffDate.In(clock.Location()).Format("02 Jan 2006"))
 		_ = s.notif.Notify(m.RequesterID, title, body, "HOLIDAY_SWAP", &ref)
 		_ = s.notif.Notify(m.TargetUserID, title, body, "HOLIDAY_SWAP", &ref)
-		for _, bid := range s.getBackofficeIDs() {
+		for _, bid := range s.reviewerIDs(m.RequesterID, m.TargetUserID) {
 			_ = s.notif.Notify(bid, title, body, "HOLIDAY_SWAP", &ref)
 		}
 	}
 	return m, nil
 }
 
-func (s *HolidaySwapService) TargetReject(id uint, me uint) (*domain.HolidaySwap, error) {
+func (s *HolidaySwapService) TargetReject(ctx context.Context, id uint) (*domain.HolidaySwap, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	me := p.ID
 	m, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -157,19 +169,21 @@ func (s *HolidaySwapService) TargetReject(id uint, me uint) (*domain.HolidaySwap
 	if m.Status != domain.HolidayPendingTarget || m.TargetUserID != me {
 		return nil, errors.New("tidak berhak atau status bukan PENDING_TARGET")
 	}
+	before := *m
 	m.Status = domain.HolidayRejected
 	if err := s.repo.Update(m); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "holiday_swap.target_reject", domain.AuditHoliday, m.ID, before, m)
 
 	if s.notif != nil {
 		ref := m.ID
 		title := "Tukar Libur • Ditolak Target"
 		body := fmt.Sprintf("Permintaan tukar libur pada %s ditolak oleh %s.",
-			m.OffDate.Format("02 Jan 2006"), s.getName(me))
+			m.OffDate.In(clock.Location()).Format("02 Jan 2006"), s.getName(me))
 		_ = s.notif.Notify(m.RequesterID, title, body, "HOLIDAY_SWAP", &ref)
 		_ = s.notif.Notify(m.TargetUserID, title, body, "HOLIDAY_SWAP", &ref)
-		for _, bid := range s.getBackofficeIDs() {
+		for _, bid := range s.reviewerIDs(m.RequesterID, m.TargetUserID) {
 			_ = s.notif.Notify(bid, title, body, "HOLIDAY_SWAP", &ref)
 		}
 	}
@@ -182,62 +196,75 @@ type BOApproveInput struct {
 	Channel   domain.WorkChannel
 	ShiftName *string
 	Notes     *string
+	Override  bool // lewati rule BLOCK minimum staffing (staffing:override)
 }
 
-// Versi sederhana: input hanya jam (HH:mm), end = start + 8 jam
+// Versi sederhana: input hanya jam (HH:mm). EndTime kosong → durasi shift
+// requester di tanggal OFF (default 8 jam); EndTime <= StartTime = selesai besok.
 type BOApproveSimpleInput struct {
 	StartTime string             // "HH:mm"
+	EndTime   string             // "HH:mm", opsional
 	Channel   domain.WorkChannel // "VOICE"|"SOSMED"
 	ShiftName *string
 	Notes     *string
+	Override  bool // lewati rule BLOCK minimum staffing (staffing:override)
 }
 
-func (s *HolidaySwapService) BOApprove(id uint, approver uint, in BOApproveInput) (*domain.HolidaySwap, error) {
+func (s *HolidaySwapService) BOApprove(ctx context.Context, id uint, in BOApproveInput) (*domain.HolidaySwap, *StaffingCheck, error) {
+	if _, err := auth.Require(ctx); err != nil {
+		return nil, nil, err
+	}
 	m, err := s.repo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if m.Status != domain.HolidayPendingBO {
-		return nil, errors.New("status bukan PENDING_BO")
+		return nil, nil, errors.New("status bukan PENDING_BO")
+	}
+	if err := s.ensureActive(m.TargetUserID, "target"); err != nil {
+		return nil, nil, err
+	}
+	// shift target harus milik tanggal OFF (boleh selesai keesokan hari)
+	if !clock.SameDay(in.StartAt, m.OffDate) {
+		return nil, nil, errors.New("start_at tidak sesuai tanggal OFF")
 	}
-	if in.StartAt.Before(m.OffDate) || in.StartAt.After(m.OffDate.Add(24*time.Hour)) {
-		return nil, errors.New("start_at tidak sesuai tanggal OFF")
+	if !in.EndAt.After(in.StartAt) {
+		return nil, nil, errors.New("end_at harus setelah start_at")
 	}
 	if ok, err := s.sched.ExistsOverlap(m.TargetUserID, in.StartAt, in.EndAt, nil); err != nil {
-		return nil, err
+		return nil, nil, err
 	} else if ok {
-		return nil, errors.New("target sudah memiliki jadwal/overlap di jam itu")
+		return nil, nil, errors.New("target sudah memiliki jadwal/overlap di jam itu")
 	}
 
-	// 1) Buat jadwal untuk TARGET
-	created, err := s.sched.Create(CreateScheduleInput{
-		UserID: m.TargetUserID, StartAt: in.StartAt, EndAt: in.EndAt,
-		Channel: in.Channel, ShiftName: in.ShiftName, Notes: in.Notes,
-	})
+	// cek minimum staffing: jadwal requester hilang, target masuk di jam baru
+	offItems := s.requesterOffDay(m)
+	check, err := s.staff.Guard(ctx, offItems, []domain.Schedule{{
+		UserID: m.TargetUserID, StartAt: in.StartAt, EndAt: in.EndAt, Channel: in.Channel,
+	}}, in.Override)
 	if err != nil {
-		return nil, err
+		return nil, check, err
 	}
 
-	// 2) Hapus jadwal milik REQUESTER pada tanggal tersebut (requester jadi OFF)
-	reqItems, err2 := s.sched.ListMonthly(&m.RequesterID, m.OffDate)
-	if err2 == nil {
-		for _, it := range reqItems {
-			if sameLocalDay(it.StartAt, m.OffDate) {
-				_ = s.sched.Delete(it.ID) // abaikan error per item
-			}
-		}
-	}
-
-	// 3) Update status
+	before := *m
+	ctx = WithScheduleChange(ctx, domain.ScheduleSourceHoliday, fmt.Sprintf("tukar libur #%d", m.ID))
+	// jadwal TARGET dibuat, jadwal REQUESTER di tanggal OFF dihapus (requester
+	// jadi OFF) dan status disimpan dalam satu transaksi
 	now := time.Now()
 	m.Status = domain.HolidayApproved
 	m.ApprovedAt = &now
-	if created != nil {
+	if _, err := s.sched.ApplyApproval(ctx, &CreateScheduleInput{
+		UserID: m.TargetUserID, StartAt: in.StartAt, EndAt: in.EndAt,
+		Channel: in.Channel, ShiftName: in.ShiftName, Notes: in.Notes,
+		Status: domain.SchedulePublished, // hasil approval langsung berlaku
+	}, offItems, func(tx *gorm.DB, created *domain.Schedule) error {
 		m.CreatedScheduleID = &created.ID
+		return tx.Save(m).Error
+	}); err != nil {
+		return nil, nil, err
 	}
-	if err := s.repo.Update(m); err != nil {
-		return nil, err
-	}
+	s.audit.Record(ctx, "holiday_swap.bo_approve", domain.AuditHoliday, m.ID, before, m)
+	s.staff.RecordOverride(ctx, "holiday_swap.bo_approve", domain.AuditHoliday, m.ID, check)
 
 	// 4) Notifikasi
 	if s.notif != nil {
@@ -245,79 +272,93 @@ func (s *HolidaySwapService) BOApprove(id uint, approver uint, in BOApproveInput
 		title := "Tukar Libur • Disetujui Backoffice"
 		body := fmt.Sprintf(
 			"Backoffice menyetujui permintaan tukar libur %s. Jadwal untuk %s telah dibuat (%s–%s, %s). Jadwal milik %s pada tanggal tersebut telah dihapus.",
-			m.OffDate.Format("02 Jan 2006"),
+			m.OffDate.In(clock.Location()).Format("02 Jan 2006"),
 			s.getName(m.TargetUserID),
-			in.StartAt.Format("02 Jan 06 15:04"),
-			in.EndAt.Format("15:04"),
+			in.StartAt.In(clock.Location()).Format("02 Jan 06 15:04"),
+			in.EndAt.In(clock.Location()).Format("15:04"),
 			in.Channel,
 			s.getName(m.RequesterID),
 		)
 		_ = s.notif.Notify(m.RequesterID, title, body, "HOLIDAY_SWAP", &ref)
 		_ = s.notif.Notify(m.TargetUserID, title, body, "HOLIDAY_SWAP", &ref)
-		for _, bid := range s.getBackofficeIDs() {
+		for _, bid := range s.reviewerIDs(m.RequesterID, m.TargetUserID) {
 			_ = s.notif.Notify(bid, title, body, "HOLIDAY_SWAP", &ref)
 		}
 	}
-	return m, nil
+	return m, check, nil
 }
 
-func (s *HolidaySwapService) BOApproveSimple(id uint, approver uint, in BOApproveSimpleInput) (*domain.HolidaySwap, error) {
+func (s *HolidaySwapService) BOApproveSimple(ctx context.Context, id uint, in BOApproveSimpleInput) (*domain.HolidaySwap, *StaffingCheck, error) {
+	if _, err := auth.Require(ctx); err != nil {
+		return nil, nil, err
+	}
 	m, err := s.repo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if m.Status != domain.HolidayPendingBO {
-		return nil, errors.New("status bukan PENDING_BO")
+		return nil, nil, errors.New("status bukan PENDING_BO")
+	}
+	if err := s.ensureActive(m.TargetUserID, "target"); err != nil {
+		return nil, nil, err
 	}
 
 	// parse "HH:mm"
 	t, err := time.Parse("15:04", in.StartTime)
 	if err != nil {
-		return nil, errors.New("start_time invalid (HH:mm)")
+		return nil, nil, errors.New("start_time invalid (HH:mm)")
 	}
-	loc := time.Local
+	loc := clock.Location()
 	day := m.OffDate.In(loc)
 	startAt := time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	offItems := s.requesterOffDay(m)
 	endAt := startAt.Add(8 * time.Hour)
-
-	if startAt.Before(m.OffDate.In(loc)) || startAt.After(m.OffDate.In(loc).Add(24*time.Hour)) {
-		return nil, errors.New("start_time tidak sesuai tanggal OFF")
+	if in.EndTime != "" {
+		e, err := time.Parse("15:04", in.EndTime)
+		if err != nil {
+			return nil, nil, errors.New("end_time invalid (HH:mm)")
+		}
+		endAt = time.Date(day.Year(), day.Month(), day.Day(), e.Hour(), e.Minute(), 0, 0, loc)
+		if !endAt.After(startAt) {
+			endAt = endAt.AddDate(0, 0, 1) // shift malam, selesai besok
+		}
+	} else if len(offItems) > 0 {
+		endAt = startAt.Add(offItems[0].EndAt.Sub(offItems[0].StartAt))
 	}
+
 	if ok, err := s.sched.ExistsOverlap(m.TargetUserID, startAt, endAt, nil); err != nil {
-		return nil, err
+		return nil, nil, err
 	} else if ok {
-		return nil, errors.New("target sudah memiliki jadwal/overlap di jam itu")
+		return nil, nil, errors.New("target sudah memiliki jadwal/overlap di jam itu")
 	}
 
-	// 1) Buat jadwal untuk TARGET
-	created, err := s.sched.Create(CreateScheduleInput{
-		UserID: m.TargetUserID, StartAt: startAt, EndAt: endAt,
-		Channel: in.Channel, ShiftName: in.ShiftName, Notes: in.Notes,
-	})
+	// cek minimum staffing: jadwal requester hilang, target masuk di jam baru
+	check, err := s.staff.Guard(ctx, offItems, []domain.Schedule{{
+		UserID: m.TargetUserID, StartAt: startAt, EndAt: endAt, Channel: in.Channel,
+	}}, in.Override)
 	if err != nil {
-		return nil, err
+		return nil, check, err
 	}
 
-	// 2) Hapus jadwal milik REQUESTER pada tanggal tersebut (requester jadi OFF)
-	reqItems, err2 := s.sched.ListMonthly(&m.RequesterID, m.OffDate)
-	if err2 == nil {
-		for _, it := range reqItems {
-			if sameLocalDay(it.StartAt, m.OffDate) {
-				_ = s.sched.Delete(it.ID)
-			}
-		}
-	}
-
-	// 3) Update status
+	before := *m
+	ctx = WithScheduleChange(ctx, domain.ScheduleSourceHoliday, fmt.Sprintf("tukar libur #%d", m.ID))
+	// jadwal TARGET dibuat, jadwal REQUESTER di tanggal OFF dihapus (requester
+	// jadi OFF) dan status disimpan dalam satu transaksi
 	now := time.Now()
 	m.Status = domain.HolidayApproved
 	m.ApprovedAt = &now
-	if created != nil {
+	if _, err := s.sched.ApplyApproval(ctx, &CreateScheduleInput{
+		UserID: m.TargetUserID, StartAt: startAt, EndAt: endAt,
+		Channel: in.Channel, ShiftName: in.ShiftName, Notes: in.Notes,
+		Status: domain.SchedulePublished,
+	}, offItems, func(tx *gorm.DB, created *domain.Schedule) error {
 		m.CreatedScheduleID = &created.ID
+		return tx.Save(m).Error
+	}); err != nil {
+		return nil, nil, err
 	}
-	if err := s.repo.Update(m); err != nil {
-		return nil, err
-	}
+	s.audit.Record(ctx, "holiday_swap.bo_approve", domain.AuditHoliday, m.ID, before, m)
+	s.staff.RecordOverride(ctx, "holiday_swap.bo_approve", domain.AuditHoliday, m.ID, check)
 
 	// 4) Notifikasi
 	if s.notif != nil {
@@ -325,7 +366,7 @@ func (s *HolidaySwapService) BOApproveSimple(id uint, approver uint, in BOApprov
 		title := "Tukar Libur • Disetujui Backoffice"
 		body := fmt.Sprintf(
 			"Backoffice menyetujui permintaan tukar libur %s. Jadwal untuk %s telah dibuat (%s–%s, %s). Jadwal milik %s pada tanggal tersebut telah dihapus.",
-			m.OffDate.Format("02 Jan 2006"),
+			m.OffDate.In(clock.Location()).Format("02 Jan 2006"),
 			s.getName(m.TargetUserID),
 			startAt.Format("02 Jan 06 15:04"),
 			endAt.Format("15:04"),
@@ -334,14 +375,19 @@ func (s *HolidaySwapService) BOApproveSimple(id uint, approver uint, in BOApprov
 		)
 		_ = s.notif.Notify(m.RequesterID, title, body, "HOLIDAY_SWAP", &ref)
 		_ = s.notif.Notify(m.TargetUserID, title, body, "HOLIDAY_SWAP", &ref)
-		for _, bid := range s.getBackofficeIDs() {
+		for _, bid := range s.reviewerIDs(m.RequesterID, m.TargetUserID) {
 			_ = s.notif.Notify(bid, title, body, "HOLIDAY_SWAP", &ref)
 		}
 	}
-	return m, nil
+	return m, check, nil
 }
 
-func (s *HolidaySwapService) Cancel(id uint, by uint) (*domain.HolidaySwap, error) {
+func (s *HolidaySwapService) Cancel(ctx context.Context, id uint) (*domain.HolidaySwap, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	by := p.ID
 	m, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -352,10 +398,12 @@ func (s *HolidaySwapService) Cancel(id uint, by uint) (*domain.HolidaySwap, erro
 	if m.Status != domain.HolidayPendingTarget && m.Status != domain.HolidayPendingBO {
 		return nil, errors.New("hanya bisa cancel saat menunggu persetujuan")
 	}
+	before := *m
 	m.Status = domain.HolidayCancelled
 	if err := s.repo.Update(m); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "holiday_swap.cancel", domain.AuditHoliday, m.ID, before, m)
 	if s.notif != nil {
 		ref := m.ID
 		_ = s.notif.Notify(m.RequesterID, "Tukar Libur • Dibatalkan", "Permintaan dibatalkan oleh pengaju.", "HOLIDAY_SWAP", &ref)
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+type LaborService struct {
+	rules     repository.LaborRuleRepository
+	schedules repository.ScheduleRepository
+	users     repository.UserRepository
+	audit     *AuditService
+}
+
+func NewLaborService(rules repository.LaborRuleRepository, schedules repository.ScheduleRepository, users repository.UserRepository, audit *AuditService) *LaborService {
+	return &LaborService{rules: rules, schedules: schedules, users: users, audit: audit}
+}
+
+// ===== Rules =====
+
+func (s *LaborService) Rules() (*domain.LaborRules, error) { return s.rules.Get() }
+
+type LaborRulesInput struct {
+	MinRestHours       int
+	MaxConsecutiveDays int
+	MaxWeeklyHours     int
+	MinDaysOffPerMonth int
+	MaxShiftHours      int
+	Active             *bool
+}
+
+func (s *LaborService) SetRules(ctx context.Context, in LaborRulesInput) (*domain.LaborRules, error) {
+	if in.MinRestHours < 0 || in.MaxConsecutiveDays < 0 || in.MaxWeeklyHours < 0 ||
+		in.MinDaysOffPerMonth < 0 || in.MaxShiftHours < 0 {
+		return nil, errors.New("nilai aturan tidak boleh negatif")
+	}
+	if in.MinRestHours > 48 || in.MaxShiftHours > 24 || in.MaxWeeklyHours > 168 ||
+		in.MaxConsecutiveDays > 31 || in.MinDaysOffPerMonth > 28 {
+		return nil, errors.New("nilai aturan di luar batas wajar")
+	}
+	m, err := s.rules.Get()
+	if err != nil {
+		return nil, err
+	}
+	var before any
+	if m.ID != 0 {
+		before = *m
+	}
+	m.MinRestHours = in.MinRestHours
+	m.MaxConsecutiveDays = in.MaxConsecutiveDays
+	m.MaxWeeklyHours = in.MaxWeeklyHours
+	m.MinDaysOffPerMonth = in.MinDaysOffPerMonth
+	m.MaxShiftHours = in.MaxShiftHours
+	if in.Active != nil {
+		m.Active = *in.Active
+	}
+	if err := s.rules.Save(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "labor_rules.update", domain.AuditLaborRules, m.ID, before, m)
+	return m, nil
+}
+
+// ===== Check =====
+
+type LaborViolation struct {
+	UserID   uint                 `json:"user_id"`
+	UserName string               `json:"user_name,omitempty"`
+	Rule     domain.LaborRuleCode `json:"rule"`
+	From     time.Time            `json:"from"` // rentang yang melanggar
+	To       time.Time            `json:"to"`
+	Value    float64              `json:"value"` // jam / hari aktual
+	Limit    int                  `json:"limit"`
+	Message  string               `json:"message"`
+}
+
+// LaborRuleError = perubahan jadwal ditolak karena melanggar aturan jam kerja.
+type LaborRuleError struct{ Violations []LaborViolation }
+
+func (e *LaborRuleError) Error() string {
+	if len(e.Violations) == 0 {
+		return "jadwal melanggar aturan jam kerja"
+	}
+	return e.Violations[0].Message
+}
+
+// Guard mengecek jadwal user setelah jadwal removed (by ID) diganti added.
+// Yang dilaporkan hanya pelanggaran yang menyentuh jadwal added, supaya
+// pelanggaran lama tidak memblok perubahan lain. Nil-safe.
+func (s *LaborService) Guard(userID uint, removed []uint, added []domain.Schedule) error {
+	out, err := s.Check(userID, removed, added)
+	if err != nil {
+		return err
+	}
+	if len(out) > 0 {
+		return &LaborRuleError{Violations: out}
+	}
+	return nil
+}
+
+// CheckBatch = Check per agent untuk jadwal massal (rotasi, import, clone,
+// draft roster); added dikelompokkan per UserID, removed = jadwal yang diganti.
+func (s *LaborService) CheckBatch(removed map[uint][]uint, added []domain.Schedule) ([]LaborViolation, error) {
+	byUser := map[uint][]domain.Schedule{}
+	var users []uint
+	for _, a := range added {
+		if _, ok := byUser[a.UserID]; !ok {
+			users = append(users, a.UserID)
+		}
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+	var all []LaborViolation
+	for _, uid := range users {
+		out, err := s.Check(uid, removed[uid], byUser[uid])
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, out...)
+	}
+	return all, nil
+}
+
+// Check = Guard yang mengembalikan daftar pelanggaran (untuk preview/dry-run).
+func (s *LaborService) Check(userID uint, removed []uint, added []domain.Schedule) ([]LaborViolation, error) {
+	if s == nil || len(added) == 0 {
+		return nil, nil
+	}
+	rules, err := s.rules.Get()
+	if err != nil {
+		return nil, err
+	}
+	if !rules.Active {
+		return nil, nil
+	}
+	from, to := added[0].StartAt, added[0].EndAt
+	for _, a := range added[1:] {
+		if a.StartAt.Before(from) {
+			from = a.StartAt
+		}
+		if a.EndAt.After(to) {
+			to = a.EndAt
+		}
+	}
+	// konteks cukup untuk run hari berturut-turut & satu bulan penuh
+	monthFrom := clock.MonthStart(from)
+	monthTo := clock.MonthStart(to).AddDate(0, 1, 0)
+	current, err := s.schedules.ListByUserRange(userID, monthFrom.AddDate(0, 0, -31), monthTo.AddDate(0, 0, 31))
+	if err != nil {
+		return nil, err
+	}
+	gone := make(map[uint]bool, len(removed))
+	for _, id := range removed {
+		gone[id] = true
+	}
+	items := make([]domain.Schedule, 0, len(current)+len(added))
+	for _, it := range current {
+		if !gone[it.ID] {
+			items = append(items, it)
+		}
+	}
+	for _, a := range added {
+		a.UserID = userID
+		items = append(items, a)
+	}
+
+	var months []time.Time
+	for m := monthFrom; m.Before(monthTo); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+	return touchingViolations(evaluateLabor(rules, userID, items, months), added), nil
+}
+
+// touchingViolations menyaring pelanggaran yang rentangnya menyentuh jadwal added.
+func touchingViolations(all []LaborViolation, added []domain.Schedule) []LaborViolation {
+	var out []LaborViolation
+	for _, v := range all {
+		for _, a := range added {
+			if !v.To.Before(a.StartAt) && !v.From.After(a.EndAt) {
+				out = append(out, v)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// evaluateLabor menjalankan semua aturan aktif atas jadwal satu user.
+// Aturan hari libur per bulan hanya dihitung untuk bulan di months.
+func evaluateLabor(r *domain.LaborRules, userID uint, items []domain.Schedule, months []time.Time) []LaborViolation {
+	sort.Slice(items, func(i, j int) bool { return items[i].StartAt.Before(items[j].StartAt) })
+	var out []LaborViolation
+	add := func(code domain.LaborRuleCode, from, to time.Time, value float64, limit int, msg string) {
+		out = append(out, LaborViolation{
+			UserID: userID, Rule: code, From: from, To: to, Value: value, Limit: limit, Message: msg,
+		})
+	}
+
+	for i, it := range items {
+		hours := it.EndAt.Sub(it.StartAt).Hours()
+		if r.MaxShiftHours > 0 && hours > float64(r.MaxShiftHours) {
+			add(domain.LaborMaxShiftLength, it.StartAt, it.EndAt, hours, r.MaxShiftHours,
+				fmt.Sprintf("shift %s %.1f jam melebihi maksimal %d jam",
+					it.StartAt.In(clock.Location()).Format("02 Jan 15:04"), hours, r.MaxShiftHours))
+		}
+		if i == 0 || r.MinRestHours == 0 {
+			continue
+		}
+		prev := items[i-1]
+		rest := it.StartAt.Sub(prev.EndAt).Hours()
+		if rest < float64(r.MinRestHours) {
+			add(domain.LaborMinRest, prev.EndAt, it.StartAt, rest, r.MinRestHours,
+				fmt.Sprintf("istirahat %.1f jam sebelum shift %s kurang dari minimal %d jam",
+					rest, it.StartAt.In(clock.Location()).Format("02 Jan 15:04"), r.MinRestHours))
+		}
+	}
+
+	// hari kerja = tanggal mulai shift (waktu lokal)
+	workDays := map[time.Time]bool{}
+	weekHours := map[time.Time]float64{}
+	for _, it := range items {
+		d := dateOnly(it.StartAt)
+		workDays[d] = true
+		weekHours[weekMonday(d)] += it.EndAt.Sub(it.StartAt).Hours()
+	}
+	days := make([]time.Time, 0, len(workDays))
+	for d := range workDays {
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	if r.MaxConsecutiveDays > 0 {
+		for i := 0; i < len(days); {
+			j := i
+			for j+1 < len(days) && daysBetween(days[j], days[j+1]) == 1 {
+				j++
+			}
+			if n := j - i + 1; n > r.MaxConsecutiveDays {
+				add(domain.LaborMaxConsecutive, days[i], days[j].AddDate(0, 0, 1), float64(n), r.MaxConsecutiveDays,
+					fmt.Sprintf("%d hari kerja berturut-turut (%s–%s) melebihi maksimal %d hari",
+						n, days[i].Format("02 Jan"), days[j].Format("02 Jan"), r.MaxConsecutiveDays))
+			}
+			i = j + 1
+		}
+	}
+
+	if r.MaxWeeklyHours > 0 {
+		weeks := make([]time.Time, 0, len(weekHours))
+		for w := range weekHours {
+			weeks = append(weeks, w)
+		}
+		sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+		for _, w := range weeks {
+			if h := weekHours[w]; h > float64(r.MaxWeeklyHours) {
+				add(domain.LaborMaxWeeklyHours, w, w.AddDate(0, 0, 7), h, r.MaxWeeklyHours,
+					fmt.Sprintf("%.1f jam kerja pada minggu %s melebihi maksimal %d jam",
+						h, w.Format("02 Jan 2006"), r.MaxWeeklyHours))
+			}
+		}
+	}
+
+	if r.MinDaysOffPerMonth > 0 {
+		for _, m := range months {
+			end := m.AddDate(0, 1, 0)
+			worked := 0
+			for _, d := range days {
+				if !d.Before(m) && d.Before(end) {
+					worked++
+				}
+			}
+			if off := daysBetween(m, end) - worked; off < r.MinDaysOffPerMonth {
+				add(domain.LaborMinDaysOff, m, end, float64(off), r.MinDaysOffPerMonth,
+					fmt.Sprintf("hanya %d hari libur pada %s, minimal %d hari",
+						off, m.Format("January 2006"), r.MinDaysOffPerMonth))
+			}
+		}
+	}
+	return out
+}
+
+// ===== Report =====
+
+type LaborReport struct {
+	Month      string             `json:"month"`
+	Rules      *domain.LaborRules `json:"rules"`
+	Total      int                `json:"total"`
+	ByRule     map[string]int     `json:"by_rule"`
+	Users      int                `json:"users"` // jumlah user yang melanggar
+	Violations []LaborViolation   `json:"violations"`
+}
+
+// MonthlyReport memvalidasi seluruh jadwal bulan itu terhadap aturan aktif.
+// scope (nil = semua) membatasi ke user tertentu, mis. anggota tim.
+func (s *LaborService) MonthlyReport(month time.Time, scope []uint) (*LaborReport, error) {
+	monthStart := clock.MonthStart(month)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	rules, err := s.rules.Get()
+	if err != nil {
+		return nil, err
+	}
+	rep := &LaborReport{
+		Month: monthStart.Format("2006-01"), Rules: rules,
+		ByRule: map[string]int{}, Violations: []LaborViolation{},
+	}
+	if !rules.Active {
+		return rep, nil
+	}
+	items, err := s.schedules.ListActiveRange(monthStart.AddDate(0, 0, -31), monthEnd.AddDate(0, 0, 7), nil)
+	if err != nil {
+		return nil, err
+	}
+	var inScope map[uint]bool
+	if scope != nil {
+		inScope = make(map[uint]bool, len(scope))
+		for _, id := range scope {
+			inScope[id] = true
+		}
+	}
+	byUser := map[uint][]domain.Schedule{}
+	for _, it := range items {
+		if inScope != nil && !inScope[it.UserID] {
+			continue
+		}
+		byUser[it.UserID] = append(byUser[it.UserID], it)
+	}
+
+	names := map[uint]string{}
+	if users, _, err := s.users.ListActive(1, 5000); err == nil {
+		for _, u := range users {
+			names[u.ID] = u.FullName
+		}
+	}
+	offenders := map[uint]bool{}
+	for uid, list := range byUser {
+		for _, v := range evaluateLabor(rules, uid, list, []time.Time{monthStart}) {
+			if !v.From.Before(monthEnd) || !v.To.After(monthStart) {
+				continue // pelanggaran di luar bulan laporan
+			}
+			v.UserName = names[uid]
+			rep.Violations = append(rep.Violations, v)
+			rep.ByRule[string(v.Rule)]++
+			offenders[uid] = true
+		}
+	}
+	sort.Slice(rep.Violations, func(i, j int) bool {
+		a, b := rep.Violations[i], rep.Violations[j]
+		if a.UserName != b.UserName {
+			return strings.ToLower(a.UserName) < strings.ToLower(b.UserName)
+		}
+		return a.From.Before(b.From)
+	})
+	rep.Total = len(rep.Violations)
+	rep.Users = len(offenders)
+	return rep, nil
+}
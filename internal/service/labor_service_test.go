@@ -0,0 +1,109 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+)
+
+// laborShift = shift user #1 mulai tanggal d (Oktober 2026) jam h selama n jam.
+func laborShift(d, h, n int) domain.Schedule {
+	st := octAt(d, h, 0)
+	return testShift(1, st, st.Add(time.Duration(n)*time.Hour))
+}
+
+func laborRun(from, to, h, n int) []domain.Schedule {
+	var out []domain.Schedule
+	for d := from; d <= to; d++ {
+		out = append(out, laborShift(d, h, n))
+	}
+	return out
+}
+
+func TestEvaluateLabor(t *testing.T) {
+	october := clock.Date(2026, time.October, 1)
+
+	tests := []struct {
+		name   string
+		rules  domain.LaborRules
+		items  []domain.Schedule
+		months []time.Time
+		want   []domain.LaborRuleCode
+		value  float64 // Value pelanggaran pertama
+	}{
+		{
+			name:  "tanpa aturan",
+			rules: domain.LaborRules{},
+			items: laborRun(1, 31, 8, 12),
+		},
+		{
+			name:  "shift terlalu panjang",
+			rules: domain.LaborRules{MaxShiftHours: 8},
+			items: []domain.Schedule{laborShift(5, 8, 8), laborShift(6, 8, 10)},
+			want:  []domain.LaborRuleCode{domain.LaborMaxShiftLength},
+			value: 10,
+		},
+		{
+			name:  "istirahat kurang",
+			rules: domain.LaborRules{MinRestHours: 11},
+			items: []domain.Schedule{laborShift(11, 6, 8), laborShift(10, 14, 8)}, // belum urut
+			want:  []domain.LaborRuleCode{domain.LaborMinRest},
+			value: 8,
+		},
+		{
+			name:  "istirahat cukup",
+			rules: domain.LaborRules{MinRestHours: 11},
+			items: []domain.Schedule{laborShift(10, 14, 8), laborShift(11, 14, 8)},
+		},
+		{
+			name:  "hari kerja berturut-turut",
+			rules: domain.LaborRules{MaxConsecutiveDays: 5},
+			items: append(laborRun(5, 10, 8, 8), laborRun(12, 14, 8, 8)...),
+			want:  []domain.LaborRuleCode{domain.LaborMaxConsecutive},
+			value: 6,
+		},
+		{
+			name:  "jam mingguan (Senin–Minggu)",
+			rules: domain.LaborRules{MaxWeeklyHours: 40},
+			items: append(laborRun(5, 9, 8, 9), laborRun(12, 15, 8, 9)...), // 45 jam, lalu 36 jam
+			want:  []domain.LaborRuleCode{domain.LaborMaxWeeklyHours},
+			value: 45,
+		},
+		{
+			name:   "hari libur per bulan",
+			rules:  domain.LaborRules{MinDaysOffPerMonth: 8},
+			items:  laborRun(1, 25, 8, 8),
+			months: []time.Time{october},
+			want:   []domain.LaborRuleCode{domain.LaborMinDaysOff},
+			value:  6,
+		},
+		{
+			name:  "hari libur tidak dicek di luar months",
+			rules: domain.LaborRules{MinDaysOffPerMonth: 8},
+			items: laborRun(1, 25, 8, 8),
+		},
+		{
+			name:  "shift malam dihitung di tanggal mulai",
+			rules: domain.LaborRules{MaxConsecutiveDays: 2},
+			items: []domain.Schedule{laborShift(5, 22, 8), laborShift(6, 22, 8)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateLabor(&tt.rules, 1, tt.items, tt.months)
+			if len(got) != len(tt.want) {
+				t.Fatalf("evaluateLabor() = %d pelanggaran %+v, want %v", len(got), got, tt.want)
+			}
+			for i, v := range got {
+				if v.Rule != tt.want[i] || v.UserID != 1 {
+					t.Errorf("violation[%d] = %s user #%d, want %s user #1", i, v.Rule, v.UserID, tt.want[i])
+				}
+			}
+			if len(got) > 0 && got[0].Value != tt.value {
+				t.Errorf("Value = %v, want %v", got[0].Value, tt.value)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"time"
 
+	"bjb-backoffice/internal/auth"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
 )
@@ -11,22 +13,27 @@ import (
 type LatenessService struct {
 	lates repository.LatenessRepository
 	users repository.UserRepository
+	audit *AuditService
 }
 
-func NewLatenessService(lates repository.LatenessRepository, users repository.UserRepository) *LatenessService {
-	return &LatenessService{lates: lates, users: users}
+func NewLatenessService(lates repository.LatenessRepository, users repository.UserRepository, audit *AuditService) *LatenessService {
+	return &LatenessService{lates: lates, users: users, audit: audit}
 }
 
 type CreateLatenessInput struct {
-	AgentID   uint
-	Date      time.Time // date-only (ignored time part)
-	Minutes   int
-	NotedByID uint
+	AgentID uint
+	Date    time.Time // date-only (ignored time part)
+	Minutes int
 }
 
-func (s *LatenessService) Create(in CreateLatenessInput) (*domain.Lateness, error) {
-	if in.AgentID == 0 || in.NotedByID == 0 {
-		return nil, errors.New("agent_id/noted_by required")
+// Create: noted_by = user yang login, diambil dari ctx.
+func (s *LatenessService) Create(ctx context.Context, in CreateLatenessInput) (*domain.Lateness, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if in.AgentID == 0 {
+		return nil, errors.New("agent_id required")
 	}
 	if in.Minutes < 0 {
 		return nil, errors.New("minutes must be >= 0")
@@ -34,9 +41,6 @@ func (s *LatenessService) Create(in CreateLatenessInput) (*domain.Lateness, erro
 	if _, err := s.users.FindByID(in.AgentID); err != nil {
 		return nil, errors.New("agent not found")
 	}
-	if _, err := s.users.FindByID(in.NotedByID); err != nil {
-		return nil, errors.New("noted_by not found")
-	}
 
 	// normalisasi ke date 00:00 lokal
 	d := time.Date(in.Date.Year(), in.Date.Month(), in.Date.Day(), 0, 0, 0, 0, in.Date.Location())
@@ -44,22 +48,31 @@ func (s *LatenessService) Create(in CreateLatenessInput) (*domain.Lateness, erro
 		AgentID:   in.AgentID,
 		Date:      d,
 		Minutes:   in.Minutes,
-		NotedByID: in.NotedByID,
+		NotedByID: me.ID,
 	}
 	if err := s.lates.Create(L); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "lateness.create", domain.AuditLateness, L.ID, nil, L)
 	return L, nil
 }
 
-func (s *LatenessService) Delete(id uint) error { return s.lates.Delete(id) }
+func (s *LatenessService) Delete(ctx context.Context, id uint) error {
+	before, _ := s.lates.FindByID(id)
+	if err := s.lates.Delete(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "lateness.delete", domain.AuditLateness, id, before, nil)
+	return nil
+}
 
 type ListLateFilter struct {
-	AgentID *uint
-	From    *time.Time
-	To      *time.Time
-	Page    int
-	Size    int
+	AgentID  *uint
+	AgentIDs []uint // filter tim (nil = semua)
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	Size     int
 }
 
 func (s *LatenessService) List(f ListLateFilter) ([]domain.Lateness, int64, error) {
@@ -69,9 +82,9 @@ func (s *LatenessService) List(f ListLateFilter) ([]domain.Lateness, int64, erro
 	if f.Size < 1 || f.Size > 100 {
 		f.Size = 10
 	}
-	return s.lates.List(f.AgentID, f.From, f.To, f.Page, f.Size)
+	return s.lates.List(f.AgentID, f.AgentIDs, f.From, f.To, f.Page, f.Size)
 }
 
-func (s *LatenessService) Aggregate(agentID *uint, from, to *time.Time, group string) ([]repository.AggRow, error) {
-	return s.lates.Aggregate(agentID, from, to, group)
+func (s *LatenessService) Aggregate(agentID *uint, agentIDs []uint, from, to *time.Time, group string) ([]repository.AggRow, error) {
+	return s.lates.Aggregate(agentID, agentIDs, from, to, group)
 }
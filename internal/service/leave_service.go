@@ -1,12 +1,17 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 type LeaveService struct {
@@ -15,6 +20,11 @@ type LeaveService struct {
 	notif  *NotificationService
 	find   *FindingService
 	sched  *ScheduleService // NEW
+	teams  *TeamService
+	staff  *StaffingService
+	// hari libur tidak dihitung sebagai hari cuti
+	holidays repository.PublicHolidayRepository
+	audit    *AuditService
 }
 
 func NewLeaveService(
@@ -23,123 +33,150 @@ func NewLeaveService(
 	notif *NotificationService,
 	find *FindingService,
 	sched *ScheduleService, // NEW
+	teams *TeamService,
+	staff *StaffingService,
+	holidays repository.PublicHolidayRepository,
+	audit *AuditService,
 ) *LeaveService {
-	return &LeaveService{leaves: leaves, users: users, notif: notif, find: find, sched: sched}
+	return &LeaveService{leaves: leaves, users: users, notif: notif, find: find, sched: sched, teams: teams, staff: staff, holidays: holidays, audit: audit}
 }
 
 type CreateLeaveInput struct {
-	RequesterID uint
-	Type        domain.LeaveType
-	StartDate   time.Time // 00:00 lokal
-	EndDate     time.Time // 00:00 lokal (inklusif)
-	Reason      string
-	FileURL     *string // NEW
+	Type      domain.LeaveType
+	StartDate time.Time // 00:00 lokal
+	EndDate   time.Time // 00:00 lokal (inklusif)
+	Reason    string
+	FileURL   *string // NEW
+	Override  bool    // loloskan rule minimum staffing BLOCK (butuh staffing:override)
 }
 
-func (s *LeaveService) Create(in CreateLeaveInput) (*domain.LeaveRequest, error) {
-	if in.RequesterID == 0 || in.Type == "" {
-		return nil, errors.New("invalid input")
+// Create: requester = user yang login. Minimum staffing dicek dengan asumsi
+// cuti disetujui; hasil pengecekan dikembalikan sebagai peringatan, bersama
+// jumlah hari cuti (tanpa hari libur).
+func (s *LeaveService) Create(ctx context.Context, in CreateLeaveInput) (*domain.LeaveRequest, int, *StaffingCheck, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if in.Type == "" {
+		return nil, 0, nil, errors.New("invalid input")
 	}
 	if in.EndDate.Before(in.StartDate) {
-		return nil, errors.New("end before start")
+		return nil, 0, nil, errors.New("end before start")
+	}
+	days, err := s.LeaveDays(in.StartDate, in.EndDate)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if days == 0 {
+		return nil, 0, nil, errors.New("rentang cuti hanya berisi hari libur")
 	}
 
 	// Rule: blokir cuti jika temuan bulan berjalan >= 5
 	if in.Type == domain.LeaveCuti {
-		now := time.Now()
-		count, err := s.find.CountForAgentInMonth(in.RequesterID, now)
+		count, err := s.find.CountForAgentInMonth(me.ID, clock.Now())
 		if err != nil {
-			return nil, err
+			return nil, 0, nil, err
 		}
 		if count >= 5 {
-			return nil, errors.New("cuti diblokir: temuan bulan berjalan ≥ 5")
+			return nil, 0, nil, errors.New("cuti diblokir: temuan bulan berjalan ≥ 5")
 		}
 	}
 
+	affected, err := s.leaveSchedules(me.ID, in.StartDate, in.EndDate)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	check, err := s.staff.Guard(ctx, affected, nil, in.Override)
+	if err != nil {
+		return nil, 0, check, err
+	}
+
 	m := &domain.LeaveRequest{
-		RequesterID: in.RequesterID,
+		RequesterID: me.ID,
 		Type:        in.Type,
-		StartDate:   time.Date(in.StartDate.Year(), in.StartDate.Month(), in.StartDate.Day(), 0, 0, 0, 0, time.Local),
-		EndDate:     time.Date(in.EndDate.Year(), in.EndDate.Month(), in.EndDate.Day(), 0, 0, 0, 0, time.Local),
+		StartDate:   clock.DayOf(in.StartDate),
+		EndDate:     clock.DayOf(in.EndDate),
 		Reason:      in.Reason,
 		FileURL:     in.FileURL,
 		Status:      domain.LeavePending,
 	}
 	if err := s.leaves.Create(m); err != nil {
-		return nil, err
+		return nil, 0, nil, err
 	}
+	s.audit.Record(ctx, "leave.create", domain.AuditLeave, m.ID, nil, m)
+	s.staff.RecordOverride(ctx, "leave.create", domain.AuditLeave, m.ID, check)
 
-	// Notifikasi ke semua backoffice (SPV,TL,QC,HR,SUPER_ADMIN)
-	users, _, _ := s.users.List(1, 1000)
+	// Notifikasi ke TL/SPV tim requester (fallback: semua backoffice aktif)
+	boIDs := s.teams.ReviewerIDs(me.ID)
 	title := "Pengajuan Cuti Baru"
-	body := fmt.Sprintf("Nama: %s\nTanggal: %s s/d %s",
-		s.getName(in.RequesterID),
+	body := fmt.Sprintf("Nama: %s\nTanggal: %s s/d %s (%d hari cuti)",
+		s.getName(me.ID),
 		m.StartDate.Format("02 Jan 2006"),
 		m.EndDate.Format("02 Jan 2006"),
+		days,
 	)
 	if in.Reason != "" {
 		body += "\nAlasan: " + in.Reason
 	}
-	for _, u := range users {
-		isBackoffice := false
-		for _, r := range u.Roles {
-			switch r.Name {
-			case domain.RoleSPV, domain.RoleTL, domain.RoleQC, domain.RoleHRAdmin, domain.RoleSuperAdmin:
-				isBackoffice = true
-			}
-		}
-		if isBackoffice {
-			_ = s.notif.Notify(u.ID, title, body, "LEAVE", &m.ID)
-		}
+	for _, uid := range boIDs {
+		_ = s.notif.Notify(uid, title, body, "LEAVE", &m.ID)
 	}
-	return m, nil
+	return m, days, check, nil
+}
+
+// leaveSchedules = jadwal user yang bersinggungan dengan tanggal start..end
+// (inklusif): shift milik tanggal cuti, termasuk shift malam di hari terakhir
+// cuti, ditambah shift malam hari sebelum cuti yang melimpah ke hari pertama
+// cuti — agent yang cuti tidak bisa bekerja di jam mana pun pada tanggal itu.
+func (s *LeaveService) leaveSchedules(userID uint, start, end time.Time) ([]domain.Schedule, error) {
+	return s.sched.ListByUserRange(userID, dateOnly(start), dateOnly(end).AddDate(0, 0, 1))
 }
 
 func (s *LeaveService) getName(uid uint) string {
-	u, err := s.users.FindByID(uid)
-	if err != nil || u == nil || u.FullName == "" {
-		return fmt.Sprintf("User #%d", uid)
-	}
-	return u.FullName
+	return displayName(s.users, uid, "User")
 }
 
-func (s *LeaveService) Approve(id uint, approverID uint) (*domain.LeaveRequest, error) {
+// GetNameForLeave dipakai LeaveHandler untuk kolom requester_name.
+func (s *LeaveService) GetNameForLeave(uid uint) string { return s.getName(uid) }
+
+// Approve menghapus jadwal requester di rentang cuti. Minimum staffing dicek
+// ulang; override hanya berlaku untuk pemegang staffing:override.
+func (s *LeaveService) Approve(ctx context.Context, id uint, override bool) (*domain.LeaveRequest, *StaffingCheck, error) {
+	approver, err := auth.Require(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	m, err := s.leaves.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if m.Status != domain.LeavePending {
-		return nil, errors.New("status not pending")
+		return nil, nil, errors.New("status not pending")
 	}
+	before := *m
 
-	// Hapus jadwal requester untuk setiap tanggal dalam rentang cuti (inklusif)
-	if s.sched != nil {
-		start := m.StartDate.In(time.Local)
-		end := m.EndDate.In(time.Local)
-		// iterasi per-hari
-		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
-			// ambil jadwal bulan ini
-			items, err := s.sched.ListMonthly(&m.RequesterID, d)
-			if err == nil {
-				for _, it := range items {
-					isSameDay := it.StartAt.In(time.Local).Year() == d.Year() &&
-						it.StartAt.In(time.Local).Month() == d.Month() &&
-						it.StartAt.In(time.Local).Day() == d.Day()
-					if isSameDay {
-						_ = s.sched.Delete(it.ID) // abaikan error per item
-					}
-				}
-			}
-		}
+	affected, err := s.leaveSchedules(m.RequesterID, clock.FromDate(m.StartDate), clock.FromDate(m.EndDate))
+	if err != nil {
+		return nil, nil, err
+	}
+	check, err := s.staff.Guard(ctx, affected, nil, override)
+	if err != nil {
+		return nil, check, err
 	}
-
 	now := time.Now()
 	m.Status = domain.LeaveApproved
-	m.ReviewedBy = &approverID
+	m.ReviewedBy = &approver.ID
 	m.ReviewedAt = &now
-	if err := s.leaves.Update(m); err != nil {
-		return nil, err
+	// hapus jadwal requester selama cuti + simpan status dalam satu transaksi
+	delCtx := WithScheduleChange(ctx, domain.ScheduleSourceLeave, fmt.Sprintf("cuti #%d", m.ID))
+	if _, err := s.sched.ApplyApproval(delCtx, nil, affected, func(tx *gorm.DB, _ *domain.Schedule) error {
+		return tx.Save(m).Error
+	}); err != nil {
+		return nil, nil, err
 	}
+	s.audit.Record(ctx, "leave.approve", domain.AuditLeave, m.ID, before, m)
+	s.staff.RecordOverride(ctx, "leave.approve", domain.AuditLeave, m.ID, check)
 
 	_ = s.notif.Notify(
 		m.RequesterID,
@@ -147,10 +184,14 @@ func (s *LeaveService) Approve(id uint, approverID uint) (*domain.LeaveRequest,
 		fmt.Sprintf("Pengajuan cuti #%d disetujui (%s–%s).", m.ID, m.StartDate.Format("02 Jan 2006"), m.EndDate.Format("02 Jan 2006")),
 		"LEAVE", &m.ID,
 	)
-	return m, nil
+	return m, check, nil
 }
 
-func (s *LeaveService) Reject(id uint, approverID uint, reason string) (*domain.LeaveRequest, error) {
+func (s *LeaveService) Reject(ctx context.Context, id uint, reason string) (*domain.LeaveRequest, error) {
+	approver, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
 	m, err := s.leaves.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -158,23 +199,76 @@ func (s *LeaveService) Reject(id uint, approverID uint, reason string) (*domain.
 	if m.Status != domain.LeavePending {
 		return nil, errors.New("status not pending")
 	}
+	before := *m
 	now := time.Now()
 	m.Status = domain.LeaveRejected
-	m.ReviewedBy = &approverID
+	m.ReviewedBy = &approver.ID
 	m.ReviewedAt = &now
 	m.Reason = m.Reason + fmt.Sprintf("\n(REJECTED: %s)", reason)
 	if err := s.leaves.Update(m); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "leave.reject", domain.AuditLeave, m.ID, before, m)
 	_ = s.notif.Notify(m.RequesterID, "Cuti Ditolak", fmt.Sprintf("Pengajuan cuti #%d ditolak: %s", m.ID, reason), "LEAVE", &m.ID)
 	return m, nil
 }
 
+// LeaveDays = jumlah hari cuti di start..end (inklusif) tanpa hari libur kalender.
+func (s *LeaveService) LeaveDays(start, end time.Time) (int, error) {
+	from, to := dateOnly(start), dateOnly(end).AddDate(0, 0, 1)
+	off, err := holidayDays(s.holidays, from, to)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if _, ok := off[holidayKey(d)]; !ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// CountDays = LeaveDays per pengajuan (id → hari), dengan satu query hari libur.
+func (s *LeaveService) CountDays(items []domain.LeaveRequest) (map[uint]int, error) {
+	out := make(map[uint]int, len(items))
+	if len(items) == 0 {
+		return out, nil
+	}
+	from, to := clock.FromDate(items[0].StartDate), clock.FromDate(items[0].EndDate)
+	for _, m := range items {
+		if d := clock.FromDate(m.StartDate); d.Before(from) {
+			from = d
+		}
+		if d := clock.FromDate(m.EndDate); d.After(to) {
+			to = d
+		}
+	}
+	off, err := holidayDays(s.holidays, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range items {
+		n := 0
+		for d := clock.FromDate(m.StartDate); !d.After(clock.FromDate(m.EndDate)); d = d.AddDate(0, 0, 1) {
+			if _, ok := off[holidayKey(d)]; !ok {
+				n++
+			}
+		}
+		out[m.ID] = n
+	}
+	return out, nil
+}
+
 func (s *LeaveService) List(requesterID *uint, status *domain.LeaveStatus, from, to *time.Time, page, size int) ([]domain.LeaveRequest, int64, error) {
 	return s.leaves.List(requesterID, status, from, to, page, size)
 }
 
-func (s *LeaveService) Cancel(id uint, by uint) error {
+func (s *LeaveService) Cancel(ctx context.Context, id uint) error {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
 	m, err := s.leaves.FindByID(id)
 	if err != nil {
 		return err
@@ -182,8 +276,12 @@ func (s *LeaveService) Cancel(id uint, by uint) error {
 	if m.Status != domain.LeavePending {
 		return errors.New("hanya bisa membatalkan saat status PENDING")
 	}
-	if m.RequesterID != by {
+	if m.RequesterID != me.ID {
 		return errors.New("hanya pengaju yang dapat membatalkan")
 	}
-	return s.leaves.Delete(id)
+	if err := s.leaves.Delete(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "leave.cancel", domain.AuditLeave, id, m, nil)
+	return nil
 }
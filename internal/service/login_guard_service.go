@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+// LoginGuardConfig mengatur throttle login.
+type LoginGuardConfig struct {
+	MaxAttempts int           // gagal berturut-turut per email sebelum dikunci
+	LockFor     time.Duration // lama lockout
+	IPMax       int           // gagal per IP dalam IPWindow sebelum IP diblok sementara
+	IPWindow    time.Duration
+	BaseDelay   time.Duration // delay progresif: BaseDelay * 2^(gagal-1), maks MaxDelay
+	MaxDelay    time.Duration
+}
+
+// LockedError dikembalikan saat akun/IP sedang dikunci.
+type LockedError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed attempts, try again after %s", e.Until.Format("15:04"))
+}
+
+func (e *LockedError) RetryAfter() time.Duration {
+	d := time.Until(e.Until)
+	if d < time.Second {
+		return time.Second
+	}
+	return d
+}
+
+type LoginGuardService struct {
+	repo  repository.LoginAttemptRepository
+	cfg   LoginGuardConfig
+	audit *AuditService
+}
+
+func NewLoginGuardService(repo repository.LoginAttemptRepository, cfg LoginGuardConfig, audit *AuditService) *LoginGuardService {
+	return &LoginGuardService{repo: repo, cfg: cfg, audit: audit}
+}
+
+func normEmail(email string) string { return strings.ToLower(strings.TrimSpace(email)) }
+
+// Check menolak login lebih awal bila email sedang dikunci atau IP terlalu banyak gagal.
+func (g *LoginGuardService) Check(email string, meta SessionMeta) error {
+	email = normEmail(email)
+	now := time.Now()
+
+	if meta.IP != "" && g.cfg.IPMax > 0 {
+		since := now.Add(-g.cfg.IPWindow)
+		n, err := g.repo.CountFailuresByIP(meta.IP, since)
+		if err != nil {
+			return err
+		}
+		if n >= int64(g.cfg.IPMax) {
+			g.record(email, meta, nil, false, domain.LoginReasonIPBlocked)
+			return &LockedError{Until: now.Add(g.cfg.IPWindow), Reason: domain.LoginReasonIPBlocked}
+		}
+	}
+
+	lock, err := g.repo.FindLock(email)
+	if err != nil {
+		return err
+	}
+	if lock != nil && lock.LockedUntil != nil && lock.LockedUntil.After(now) {
+		g.record(email, meta, nil, false, domain.LoginReasonLocked)
+		return &LockedError{Until: *lock.LockedUntil, Reason: domain.LoginReasonLocked}
+	}
+	// delay progresif: percobaan sebelum jeda habis ditolak
+	if lock != nil && lock.LastFailedAt != nil && lock.FailedCount > 0 {
+		if until := lock.LastFailedAt.Add(g.delayFor(lock.FailedCount)); until.After(now) {
+			g.record(email, meta, nil, false, domain.LoginReasonThrottled)
+			return &LockedError{Until: until, Reason: domain.LoginReasonThrottled}
+		}
+	}
+	return nil
+}
+
+// Fail mencatat kegagalan, menaikkan counter dan mengunci akun bila melewati
+// batas. Hasilnya = delay progresif sebelum percobaan berikutnya diterima
+// (dikirim ke klien sebagai Retry-After, request tidak ditahan).
+func (g *LoginGuardService) Fail(email string, meta SessionMeta, userID *uint, reason string) time.Duration {
+	email = normEmail(email)
+	now := time.Now()
+	g.record(email, meta, userID, false, reason)
+
+	// counter dinaikkan atomik di DB supaya request paralel tidak saling menimpa
+	lock, err := g.repo.RecordFailure(email, now, g.cfg.MaxAttempts, g.cfg.LockFor)
+	if err != nil {
+		log.Printf("[login-guard] record failure FAILED email=%q err=%v", email, err)
+		return 0
+	}
+	if g.cfg.MaxAttempts > 0 && lock.FailedCount == g.cfg.MaxAttempts && lock.LockedUntil != nil {
+		log.Printf("[login-guard] account locked email=%q until=%s", email, lock.LockedUntil.Format(time.RFC3339))
+	}
+	return g.delayFor(lock.FailedCount)
+}
+
+// Reject hanya mencatat percobaan yang ditolak karena alasan selain kredensial
+// (mis. akun nonaktif) tanpa menaikkan counter lockout.
+func (g *LoginGuardService) Reject(email string, meta SessionMeta, userID uint, reason string) {
+	g.record(normEmail(email), meta, &userID, false, reason)
+}
+
+// Succeed mencatat login sukses & me-reset counter gagal.
+func (g *LoginGuardService) Succeed(email string, meta SessionMeta, userID uint) {
+	email = normEmail(email)
+	g.record(email, meta, &userID, true, domain.LoginReasonOK)
+
+	lock, err := g.repo.FindLock(email)
+	if err != nil || lock == nil || lock.FailedCount == 0 {
+		return
+	}
+	lock.FailedCount = 0
+	lock.LockedUntil = nil
+	if err := g.repo.SaveLock(lock); err != nil {
+		log.Printf("[login-guard] reset lock FAILED email=%q err=%v", email, err)
+	}
+}
+
+func (g *LoginGuardService) delayFor(failed int) time.Duration {
+	if failed < 1 || g.cfg.BaseDelay <= 0 {
+		return 0
+	}
+	d := g.cfg.BaseDelay
+	for i := 1; i < failed && d < g.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > g.cfg.MaxDelay {
+		d = g.cfg.MaxDelay
+	}
+	return d
+}
+
+func (g *LoginGuardService) record(email string, meta SessionMeta, userID *uint, ok bool, reason string) {
+	err := g.repo.Create(&domain.LoginAttempt{
+		Email:     email,
+		IP:        truncate(meta.IP, 64),
+		UserID:    userID,
+		Success:   ok,
+		Reason:    reason,
+		UserAgent: truncate(meta.UserAgent, 255),
+	})
+	if err != nil {
+		log.Printf("[login-guard] record attempt FAILED email=%q err=%v", email, err)
+	}
+}
+
+func (g *LoginGuardService) ListLocked() ([]domain.AccountLock, error) {
+	return g.repo.ListLocked(time.Now())
+}
+
+// Unlock membuka lockout sebelum waktunya (SUPER_ADMIN).
+func (g *LoginGuardService) Unlock(ctx context.Context, email string) error {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+	by := p.ID
+	email = normEmail(email)
+	lock, err := g.repo.FindLock(email)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("no lock record for %s", email)
+	}
+	before := *lock
+	now := time.Now()
+	lock.FailedCount = 0
+	lock.LockedUntil = nil
+	lock.UnlockedBy = &by
+	lock.UnlockedAt = &now
+	if err := g.repo.SaveLock(lock); err != nil {
+		return err
+	}
+	g.audit.Record(ctx, "security.unlock", domain.AuditSecurity, lock.ID, before, lock)
+	log.Printf("[login-guard] account unlocked email=%q by uid=%d", email, by)
+	return nil
+}
+
+func (g *LoginGuardService) ListAttempts(f repository.LoginAttemptFilter) ([]domain.LoginAttempt, int64, error) {
+	return g.repo.List(f)
+}
@@ -2,9 +2,13 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"log"
+
+	"bjb-backoffice/internal/auth"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
-	"log"
 )
 
 type NotificationService struct {
@@ -31,7 +35,22 @@ func (n *NotificationService) Notify(userID uint, title, body, refType string, r
 func (n *NotificationService) ListMine(userID uint, unread bool, limit int) ([]domain.Notification, error) {
 	return n.repo.ListByUser(userID, unread, limit)
 }
-func (n *NotificationService) MarkRead(id uint) error { return n.repo.MarkRead(id) }
+
+// MarkRead hanya untuk notifikasi milik user yang login.
+func (n *NotificationService) MarkRead(ctx context.Context, id uint) error {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+	ok, err := n.repo.MarkRead(id, me.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("notification not found")
+	}
+	return nil
+}
 
 func valueOrZero(p *uint) uint {
 	if p == nil {
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/mail"
+	"bjb-backoffice/internal/repository"
+)
+
+var ErrInvalidResetToken = errors.New("reset token invalid or expired")
+
+type PasswordResetService struct {
+	users   repository.UserRepository
+	resets  repository.PasswordResetRepository
+	auth    *AuthService
+	mailer  mail.Sender
+	baseURL string // URL frontend, link reset = baseURL + /reset-password?token=...
+	ttl     time.Duration
+}
+
+func NewPasswordResetService(
+	users repository.UserRepository,
+	resets repository.PasswordResetRepository,
+	auth *AuthService,
+	mailer mail.Sender,
+	baseURL string,
+	ttlMinutes int,
+) *PasswordResetService {
+	return &PasswordResetService{
+		users:   users,
+		resets:  resets,
+		auth:    auth,
+		mailer:  mailer,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		ttl:     time.Duration(ttlMinutes) * time.Minute,
+	}
+}
+
+// ForgotPassword mengirim link reset bila email terdaftar. Sengaja tidak
+// mengembalikan error "email tidak ditemukan" supaya tidak bisa dipakai enumerasi akun.
+func (s *PasswordResetService) ForgotPassword(email string) error {
+	u, err := s.users.FindByEmail(strings.TrimSpace(email))
+	if err != nil || !u.Active {
+		log.Printf("[pw-reset] request for unknown/inactive email=%q ignored", email)
+		return nil
+	}
+
+	// hanya satu token aktif per user
+	if err := s.resets.InvalidateForUser(u.ID); err != nil {
+		return err
+	}
+	raw, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	t := &domain.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.resets.Create(t); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.baseURL, raw)
+	body := fmt.Sprintf(
+		"Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda.\n"+
+			"Buka link berikut untuk membuat password baru (berlaku sampai %s):\n\n%s\n\n"+
+			"Abaikan email ini jika Anda tidak merasa meminta reset password.",
+		u.FullName, t.ExpiresAt.Format("02 Jan 2006 15:04"), link,
+	)
+	if err := s.mailer.Send(mail.Message{To: u.Email, Subject: "Reset Password", Body: body}); err != nil {
+		log.Printf("[pw-reset] send mail FAILED uid=%d err=%v", u.ID, err)
+		return err
+	}
+	log.Printf("[pw-reset] token issued uid=%d token_id=%d", u.ID, t.ID)
+	return nil
+}
+
+// ResetPassword memakai token (sekali pakai) sekaligus mengganti password,
+// lalu mencabut semua session.
+func (s *PasswordResetService) ResetPassword(token, newPass string) error {
+	if len(newPass) < 6 {
+		return errors.New("new password too short")
+	}
+	t, err := s.resets.FindByTokenHash(hashToken(token))
+	if err != nil || t.UsedAt != nil || time.Now().After(t.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	hash, err := s.auth.GeneratePasswordHash(newPass)
+	if err != nil {
+		return err
+	}
+	// token ditandai terpakai bersamaan dengan update password (satu transaksi)
+	if err := s.resets.UseToken(t, hash); err != nil {
+		if errors.Is(err, repository.ErrResetTokenUsed) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	log.Printf("[pw-reset] password reset OK uid=%d token_id=%d", t.UserID, t.ID)
+	return s.auth.RevokeUserSessions(t.UserID)
+}
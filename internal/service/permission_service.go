@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+// PermissionService menyimpan mapping role → permission di memori (cache)
+// dan me-reload setelah admin mengubah grant, jadi tidak perlu redeploy.
+type PermissionService struct {
+	repo  repository.PermissionRepository
+	audit *AuditService
+
+	mu     sync.RWMutex
+	grants map[domain.RoleName]map[domain.Permission]bool
+}
+
+func NewPermissionService(repo repository.PermissionRepository, audit *AuditService) *PermissionService {
+	return &PermissionService{repo: repo, audit: audit, grants: map[domain.RoleName]map[domain.Permission]bool{}}
+}
+
+// Init mengisi grant default untuk permission baru lalu memuat cache.
+func (s *PermissionService) Init() error {
+	keys := make([]domain.Permission, 0, len(domain.PermissionRegistry))
+	for _, d := range domain.PermissionRegistry {
+		keys = append(keys, d.Key)
+	}
+	if err := s.repo.SeedNew(keys, domain.DefaultRolePermissions); err != nil {
+		return err
+	}
+	return s.Reload()
+}
+
+func (s *PermissionService) Reload() error {
+	rows, err := s.repo.ListAll()
+	if err != nil {
+		return err
+	}
+	next := map[domain.RoleName]map[domain.Permission]bool{}
+	for _, r := range rows {
+		if next[r.Role] == nil {
+			next[r.Role] = map[domain.Permission]bool{}
+		}
+		next[r.Role][r.Permission] = true
+	}
+	s.mu.Lock()
+	s.grants = next
+	s.mu.Unlock()
+	return nil
+}
+
+// Has = true bila salah satu role punya permission tsb.
+func (s *PermissionService) Has(roles []string, perm domain.Permission) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, r := range roles {
+		if s.grants[domain.RoleName(r)][perm] {
+			return true
+		}
+	}
+	return false
+}
+
+// For mengembalikan semua permission efektif dari kumpulan role (untuk FE).
+func (s *PermissionService) For(roles []string) []domain.Permission {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set := map[domain.Permission]bool{}
+	for _, r := range roles {
+		for p := range s.grants[domain.RoleName(r)] {
+			set[p] = true
+		}
+	}
+	return sortedPerms(set)
+}
+
+func (s *PermissionService) Registry() []domain.PermissionDef { return domain.PermissionRegistry }
+
+// Grants = mapping lengkap role → permission (semua role, termasuk yang kosong).
+func (s *PermissionService) Grants() map[domain.RoleName][]domain.Permission {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[domain.RoleName][]domain.Permission, len(domain.AllRoles))
+	for _, r := range domain.AllRoles {
+		out[r] = sortedPerms(s.grants[r])
+	}
+	return out
+}
+
+// SetRoleGrants mengganti grant sebuah role. SUPER_ADMIN selalu mempertahankan
+// permissions:manage supaya admin tidak mengunci dirinya sendiri.
+func (s *PermissionService) SetRoleGrants(ctx context.Context, role domain.RoleName, perms []domain.Permission) ([]domain.Permission, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("role tidak dikenal: %s", role)
+	}
+	set := map[domain.Permission]bool{}
+	for _, p := range perms {
+		if !p.Valid() {
+			return nil, fmt.Errorf("permission tidak dikenal: %s", p)
+		}
+		set[p] = true
+	}
+	if role == domain.RoleSuperAdmin && !set[domain.PermPermissionsManage] {
+		return nil, fmt.Errorf("%s tidak boleh dicabut dari %s", domain.PermPermissionsManage, domain.RoleSuperAdmin)
+	}
+	list := sortedPerms(set)
+	before := s.Grants()[role]
+	if err := s.repo.ReplaceForRole(role, list); err != nil {
+		return nil, err
+	}
+	if err := s.Reload(); err != nil {
+		log.Printf("[perm] reload after update FAILED: %v", err)
+		return nil, err
+	}
+	s.audit.Record(ctx, "permission.set_role", domain.AuditPermission, 0,
+		map[string]any{"role": role, "permissions": before}, map[string]any{"role": role, "permissions": list})
+	return list, nil
+}
+
+func sortedPerms(set map[domain.Permission]bool) []domain.Permission {
+	out := make([]domain.Permission, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+	"bjb-backoffice/internal/tabular"
+)
+
+const maxHolidayImportRows = 1000
+
+type PublicHolidayService struct {
+	repo      repository.PublicHolidayRepository
+	schedules repository.ScheduleRepository
+	users     repository.UserRepository
+	audit     *AuditService
+}
+
+func NewPublicHolidayService(repo repository.PublicHolidayRepository, schedules repository.ScheduleRepository, users repository.UserRepository, audit *AuditService) *PublicHolidayService {
+	return &PublicHolidayService{repo: repo, schedules: schedules, users: users, audit: audit}
+}
+
+type PublicHolidayInput struct {
+	Date *time.Time // nil = tidak diubah
+	Name string
+	Kind domain.HolidayKind
+}
+
+func validHolidayKind(k domain.HolidayKind) bool {
+	return k == domain.HolidayNational || k == domain.HolidayCompany
+}
+
+// List = hari libur di tanggal from..to (inklusif).
+func (s *PublicHolidayService) List(from, to time.Time) ([]domain.PublicHoliday, error) {
+	return s.repo.ListBetween(dateOnly(from), dateOnly(to).AddDate(0, 0, 1))
+}
+
+// HolidayDay = ringkasan hari libur untuk respon jadwal/laporan.
+type HolidayDay struct {
+	Date string             `json:"date"`
+	Name string             `json:"name"`
+	Kind domain.HolidayKind `json:"kind"`
+}
+
+func holidayDayList(items []domain.PublicHoliday) []HolidayDay {
+	out := make([]HolidayDay, 0, len(items))
+	for _, h := range items {
+		out = append(out, HolidayDay{Date: holidayKey(clock.FromDate(h.Date)), Name: h.Name, Kind: h.Kind})
+	}
+	return out
+}
+
+// Month = hari libur di bulan month, urut tanggal.
+func (s *PublicHolidayService) Month(month time.Time) ([]HolidayDay, error) {
+	start := clock.MonthStart(month)
+	items, err := s.repo.ListBetween(start, start.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+	return holidayDayList(items), nil
+}
+
+func (s *PublicHolidayService) Create(ctx context.Context, in PublicHolidayInput) (*domain.PublicHoliday, error) {
+	if in.Date == nil {
+		return nil, errors.New("date required")
+	}
+	m := &domain.PublicHoliday{Kind: domain.HolidayNational}
+	if err := applyHolidayInput(m, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "public_holiday.create", domain.AuditPublicHoliday, m.ID, nil, m)
+	return m, nil
+}
+
+func (s *PublicHolidayService) Update(ctx context.Context, id uint, in PublicHolidayInput) (*domain.PublicHoliday, error) {
+	m, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *m
+	if err := applyHolidayInput(m, in); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "public_holiday.update", domain.AuditPublicHoliday, m.ID, before, m)
+	return m, nil
+}
+
+func (s *PublicHolidayService) Delete(ctx context.Context, id uint) error {
+	before, err := s.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "public_holiday.delete", domain.AuditPublicHoliday, id, before, nil)
+	return nil
+}
+
+func applyHolidayInput(m *domain.PublicHoliday, in PublicHolidayInput) error {
+	if in.Date != nil {
+		m.Date = dateOnly(*in.Date)
+	}
+	if n := strings.TrimSpace(in.Name); n != "" {
+		m.Name = n
+	}
+	if m.Name == "" {
+		return errors.New("name required")
+	}
+	if in.Kind != "" {
+		m.Kind = in.Kind
+	}
+	if !validHolidayKind(m.Kind) {
+		return errors.New("kind harus NATIONAL atau COMPANY")
+	}
+	return nil
+}
+
+// ===== Import CSV/XLSX =====
+
+// Kolom file import: date (YYYY-MM-DD), name, kind (opsional, default NATIONAL).
+// Tanggal yang sudah ada diperbarui nama & jenisnya.
+
+type HolidayImportRow struct {
+	Row    int                `json:"row"` // nomor baris di file (header = 1)
+	Date   string             `json:"date"`
+	Name   string             `json:"name"`
+	Kind   domain.HolidayKind `json:"kind"`
+	Status string             `json:"status"` // OK | ERROR | CREATED | UPDATED
+	Errors []string           `json:"errors,omitempty"`
+}
+
+type HolidayImportResult struct {
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Valid   int                `json:"valid"`
+	Invalid int                `json:"invalid"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Rows    []HolidayImportRow `json:"rows"`
+}
+
+// Import memvalidasi semua baris; kalau bukan dry-run dan tidak ada error,
+// semua tanggal disimpan dalam satu transaksi. Satu baris gagal → tidak ada
+// yang disimpan.
+func (s *PublicHolidayService) Import(ctx context.Context, rows [][]string, dryRun bool) (*HolidayImportResult, error) {
+	if len(rows) < 2 {
+		return nil, errors.New("file kosong (minimal header + 1 baris)")
+	}
+	idx := tabular.HeaderIndex(rows[0])
+	for _, col := range []string{"date", "name"} {
+		if _, ok := idx[col]; !ok {
+			return nil, fmt.Errorf("kolom %q tidak ada di header", col)
+		}
+	}
+	data := rows[1:]
+	if len(data) > maxHolidayImportRows {
+		return nil, fmt.Errorf("maksimal %d baris per import", maxHolidayImportRows)
+	}
+
+	res := &HolidayImportResult{DryRun: dryRun, Rows: make([]HolidayImportRow, 0, len(data))}
+	items := make([]domain.PublicHoliday, 0, len(data))
+	seen := map[string]int{}
+	var minDate, maxDate time.Time
+	for i, raw := range data {
+		r := HolidayImportRow{
+			Row:  i + 2,
+			Date: tabular.Cell(raw, idx, "date"),
+			Name: tabular.Cell(raw, idx, "name"),
+			Kind: domain.HolidayKind(strings.ToUpper(tabular.Cell(raw, idx, "kind"))),
+		}
+		if r.Kind == "" {
+			r.Kind = domain.HolidayNational
+		}
+		d, err := time.ParseInLocation("2006-01-02", r.Date, clock.Location())
+		switch {
+		case err != nil:
+			r.Errors = append(r.Errors, "date harus YYYY-MM-DD")
+		case seen[r.Date] > 0:
+			r.Errors = append(r.Errors, fmt.Sprintf("tanggal duplikat dengan baris %d", seen[r.Date]))
+		default:
+			seen[r.Date] = r.Row
+		}
+		if r.Name == "" {
+			r.Errors = append(r.Errors, "name wajib diisi")
+		}
+		if !validHolidayKind(r.Kind) {
+			r.Errors = append(r.Errors, "kind harus NATIONAL atau COMPANY")
+		}
+		if len(r.Errors) > 0 {
+			r.Status = "ERROR"
+			res.Invalid++
+		} else {
+			r.Status = "OK"
+			res.Valid++
+			items = append(items, domain.PublicHoliday{Date: d, Name: r.Name, Kind: r.Kind})
+			if minDate.IsZero() || d.Before(minDate) {
+				minDate = d
+			}
+			if d.After(maxDate) {
+				maxDate = d
+			}
+		}
+		res.Rows = append(res.Rows, r)
+	}
+	res.Total = len(res.Rows)
+	if res.Invalid > 0 || len(items) == 0 {
+		return res, nil
+	}
+
+	if dryRun {
+		return res, nil
+	}
+	existing, err := holidayDays(s.repo, minDate, maxDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Upsert(items); err != nil {
+		return nil, err
+	}
+	for i := range res.Rows {
+		r := &res.Rows[i]
+		if _, ok := existing[r.Date]; ok {
+			r.Status = "UPDATED"
+			res.Updated++
+		} else {
+			r.Status = "CREATED"
+			res.Created++
+		}
+	}
+	s.audit.Record(ctx, "public_holiday.import", domain.AuditPublicHoliday, 0, nil, map[string]any{
+		"from": minDate.Format("2006-01-02"), "to": maxDate.Format("2006-01-02"),
+		"created": res.Created, "updated": res.Updated,
+	})
+	return res, nil
+}
+
+// ===== Shift hari libur (premi) =====
+
+// HolidayShift = shift PUBLISHED yang tanggal bisnisnya hari libur. Shift malam
+// dihitung penuh untuk tanggal mulainya (lihat domain.Schedule.BusinessDate).
+type HolidayShift struct {
+	ScheduleID  uint               `json:"schedule_id"`
+	UserID      uint               `json:"user_id"`
+	Date        string             `json:"date"`
+	HolidayName string             `json:"holiday_name"`
+	HolidayKind domain.HolidayKind `json:"holiday_kind"`
+	StartAt     time.Time          `json:"start_at"`
+	EndAt       time.Time          `json:"end_at"`
+	Channel     domain.WorkChannel `json:"channel"`
+	Hours       float64            `json:"hours"`
+}
+
+type HolidayShiftUser struct {
+	UserID   uint    `json:"user_id"`
+	FullName string  `json:"full_name"`
+	Shifts   int     `json:"shifts"`
+	Hours    float64 `json:"hours"`
+}
+
+type HolidayShiftReport struct {
+	Month    string             `json:"month"`
+	Holidays []HolidayDay       `json:"holidays"`
+	Users    []HolidayShiftUser `json:"users"`
+	Shifts   []HolidayShift     `json:"shifts"`
+}
+
+// ShiftReport merangkum shift hari libur per agent di bulan month untuk
+// perhitungan premi. scope = batas user dari filter tim (nil = semua).
+func (s *PublicHolidayService) ShiftReport(month time.Time, scope []uint) (*HolidayShiftReport, error) {
+	start := clock.MonthStart(month)
+	end := start.AddDate(0, 1, 0)
+	holidays, err := s.repo.ListBetween(start, end)
+	if err != nil {
+		return nil, err
+	}
+	rep := &HolidayShiftReport{Month: start.Format("2006-01"), Holidays: holidayDayList(holidays), Users: []HolidayShiftUser{}, Shifts: []HolidayShift{}}
+	if len(holidays) == 0 {
+		return rep, nil
+	}
+	byDate := make(map[string]domain.PublicHoliday, len(holidays))
+	for _, h := range holidays {
+		byDate[holidayKey(clock.FromDate(h.Date))] = h
+	}
+	var inScope map[uint]bool
+	if scope != nil {
+		inScope = make(map[uint]bool, len(scope))
+		for _, id := range scope {
+			inScope[id] = true
+		}
+	}
+	items, err := s.schedules.ListMonthly(nil, start)
+	if err != nil {
+		return nil, err
+	}
+	perUser := map[uint]*HolidayShiftUser{}
+	for _, it := range items {
+		if it.Status == domain.ScheduleDraft || (inScope != nil && !inScope[it.UserID]) {
+			continue
+		}
+		h, ok := byDate[holidayKey(it.StartAt)]
+		if !ok {
+			continue
+		}
+		hours := it.EndAt.Sub(it.StartAt).Hours()
+		rep.Shifts = append(rep.Shifts, HolidayShift{
+			ScheduleID: it.ID, UserID: it.UserID, Date: holidayKey(it.StartAt),
+			HolidayName: h.Name, HolidayKind: h.Kind,
+			StartAt: it.StartAt, EndAt: it.EndAt, Channel: it.Channel, Hours: hours,
+		})
+		u := perUser[it.UserID]
+		if u == nil {
+			u = &HolidayShiftUser{UserID: it.UserID, FullName: displayName(s.users, it.UserID, "Agent")}
+			perUser[it.UserID] = u
+		}
+		u.Shifts++
+		u.Hours += hours
+	}
+	for _, u := range perUser {
+		rep.Users = append(rep.Users, *u)
+	}
+	sort.Slice(rep.Users, func(i, j int) bool { return rep.Users[i].UserID < rep.Users[j].UserID })
+	return rep, nil
+}
+
+// holidayDays = hari libur di [from, to) per tanggal "YYYY-MM-DD".
+func holidayDays(repo repository.PublicHolidayRepository, from, to time.Time) (map[string]domain.PublicHoliday, error) {
+	out := map[string]domain.PublicHoliday{}
+	if repo == nil {
+		return out, nil
+	}
+	items, err := repo.ListBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range items {
+		out[holidayKey(clock.FromDate(h.Date))] = h
+	}
+	return out, nil
+}
+
+func holidayKey(t time.Time) string { return t.In(clock.Location()).Format("2006-01-02") }
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrCloneConflict = errors.New("clone bentrok dengan jadwal yang ada")
+
+type ClonePeriod string
+
+const (
+	CloneWeek  ClonePeriod = "WEEK"
+	CloneMonth ClonePeriod = "MONTH"
+)
+
+type CloneScheduleInput struct {
+	Period ClonePeriod
+	Source time.Time // tanggal mana pun di minggu/bulan sumber
+	Target time.Time // tanggal mana pun di minggu/bulan tujuan
+	// opsional: hanya agent ini / channel ini
+	UserIDs []uint
+	Channel *domain.WorkChannel
+	// batas tim dari handler (nil = semua)
+	Scope         []uint
+	SkipConflicts bool
+	DryRun        bool
+}
+
+// CloneItem = satu shift hasil salinan. Skip = tidak disalin (cuti) dan tidak
+// dihitung bentrok; Conflict = bentrok yang harus diputuskan sebelum commit.
+type CloneItem struct {
+	SourceScheduleID uint               `json:"source_schedule_id"`
+	UserID           uint               `json:"user_id"`
+	SourceDate       string             `json:"source_date"`
+	Date             string             `json:"date"`
+	StartAt          time.Time          `json:"start_at"`
+	EndAt            time.Time          `json:"end_at"`
+	Channel          domain.WorkChannel `json:"channel"`
+	ShiftName        *string            `json:"shift_name"`
+	Skip             string             `json:"skip,omitempty"`
+	Conflict         string             `json:"conflict,omitempty"`
+	ScheduleID       uint               `json:"schedule_id,omitempty"`
+
+	shiftTemplateID *uint
+	notes           *string
+}
+
+type CloneResult struct {
+	DryRun     bool        `json:"dry_run"`
+	Period     ClonePeriod `json:"period"`
+	SourceFrom string      `json:"source_from"`
+	SourceTo   string      `json:"source_to"` // inklusif
+	TargetFrom string      `json:"target_from"`
+	TargetTo   string      `json:"target_to"` // inklusif
+	Total      int         `json:"total"`
+	Skipped    int         `json:"skipped"`
+	Conflicts  int         `json:"conflicts"`
+	Created    int         `json:"created"`
+	Items      []CloneItem `json:"items"`
+	// pelanggaran aturan jam kerja atas shift yang (akan) disalin
+	LaborViolations []LaborViolation `json:"labor_violations"`
+}
+
+// clonePeriods: [from, to) sumber & tujuan. Minggu = Senin s/d Minggu.
+func clonePeriods(in CloneScheduleInput) (srcFrom, srcTo, dstFrom, dstTo time.Time, err error) {
+	switch in.Period {
+	case CloneWeek:
+		srcFrom, dstFrom = weekMonday(dateOnly(in.Source)), weekMonday(dateOnly(in.Target))
+		return srcFrom, srcFrom.AddDate(0, 0, 7), dstFrom, dstFrom.AddDate(0, 0, 7), nil
+	case CloneMonth:
+		s, d := dateOnly(in.Source), dateOnly(in.Target)
+		srcFrom = clock.MonthStart(s)
+		dstFrom = clock.MonthStart(d)
+		return srcFrom, srcFrom.AddDate(0, 1, 0), dstFrom, dstFrom.AddDate(0, 1, 0), nil
+	}
+	return srcFrom, srcTo, dstFrom, dstTo, errors.New("period harus WEEK atau MONTH")
+}
+
+// cloneSourceDay memetakan tanggal tujuan ke tanggal sumber dengan hari yang
+// sama. Minggu: geser per 7 hari. Bulan: Senin ke-n → Senin ke-n bulan sumber;
+// kalau bulan sumber tidak punya urutan ke-n (mis. Senin ke-5) dipakai yang terakhir.
+func cloneSourceDay(period ClonePeriod, day, srcFrom, srcTo, dstFrom time.Time) time.Time {
+	if period == CloneWeek {
+		return srcFrom.AddDate(0, 0, daysBetween(dstFrom, day))
+	}
+	nth := (day.Day() - 1) / 7
+	first := srcFrom.AddDate(0, 0, (int(day.Weekday())-int(srcFrom.Weekday())+7)%7)
+	d := first.AddDate(0, 0, 7*nth)
+	for !d.Before(srcTo) {
+		d = d.AddDate(0, 0, -7)
+	}
+	return d
+}
+
+// CloneSchedules menyalin jadwal minggu/bulan sumber ke periode tujuan,
+// disejajarkan per hari (lihat cloneSourceDay) dengan jam & durasi yang sama.
+// Agent yang cuti disetujui di tanggal tujuan dilewati. Preview (DryRun) dan
+// commit memakai perhitungan yang sama; commit dalam satu transaksi dan hasilnya
+// berstatus DRAFT.
+func (s *RosterService) CloneSchedules(ctx context.Context, in CloneScheduleInput) (*CloneResult, error) {
+	srcFrom, srcTo, dstFrom, dstTo, err := clonePeriods(in)
+	if err != nil {
+		return nil, err
+	}
+	if srcFrom.Equal(dstFrom) {
+		return nil, errors.New("periode sumber dan tujuan sama")
+	}
+	if in.Channel != nil && *in.Channel != domain.ChannelVoice && *in.Channel != domain.ChannelSosmed {
+		return nil, errors.New("channel must be VOICE or SOSMED")
+	}
+	allowed := func(uid uint) bool { return true }
+	if in.Scope != nil || len(in.UserIDs) > 0 {
+		inScope := map[uint]bool{}
+		for _, id := range in.Scope {
+			inScope[id] = true
+		}
+		picked := map[uint]bool{}
+		for _, id := range in.UserIDs {
+			picked[id] = true
+		}
+		allowed = func(uid uint) bool {
+			return (in.Scope == nil || inScope[uid]) && (len(in.UserIDs) == 0 || picked[uid])
+		}
+	}
+
+	source, err := s.schedules.ListActiveRange(srcFrom, srcTo, in.Channel)
+	if err != nil {
+		return nil, err
+	}
+	byDay := map[string][]domain.Schedule{}
+	for _, sc := range source {
+		st := sc.StartAt.In(clock.Location())
+		if st.Before(srcFrom) || !st.Before(srcTo) || !allowed(sc.UserID) {
+			continue // shift yang mulai sebelum periode hanya terpotong di awal
+		}
+		k := st.Format("2006-01-02")
+		byDay[k] = append(byDay[k], sc)
+	}
+	onLeave, err := approvedLeaveDays(s.leaves, dstFrom, dstTo)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CloneResult{
+		DryRun: in.DryRun, Period: in.Period,
+		SourceFrom: srcFrom.Format("2006-01-02"), SourceTo: srcTo.AddDate(0, 0, -1).Format("2006-01-02"),
+		TargetFrom: dstFrom.Format("2006-01-02"), TargetTo: dstTo.AddDate(0, 0, -1).Format("2006-01-02"),
+		Items: []CloneItem{},
+	}
+	for day := dstFrom; day.Before(dstTo); day = day.AddDate(0, 0, 1) {
+		src := cloneSourceDay(in.Period, day, srcFrom, srcTo, dstFrom)
+		for _, sc := range byDay[src.Format("2006-01-02")] {
+			st := sc.StartAt.In(clock.Location())
+			start := time.Date(day.Year(), day.Month(), day.Day(), st.Hour(), st.Minute(), st.Second(), 0, clock.Location())
+			res.Items = append(res.Items, CloneItem{
+				SourceScheduleID: sc.ID, UserID: sc.UserID,
+				SourceDate: src.Format("2006-01-02"), Date: day.Format("2006-01-02"),
+				StartAt: start, EndAt: start.Add(sc.EndAt.Sub(sc.StartAt)),
+				Channel: sc.Channel, ShiftName: sc.ShiftName,
+				shiftTemplateID: sc.ShiftTemplateID, notes: sc.Notes,
+			})
+		}
+	}
+	sort.SliceStable(res.Items, func(i, j int) bool {
+		a, b := res.Items[i], res.Items[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.StartAt.Before(b.StartAt)
+	})
+
+	// bentrok: dengan jadwal yang ada, atau antar hasil salinan sendiri
+	prevEnd := map[uint]time.Time{}
+	for i := range res.Items {
+		it := &res.Items[i]
+		switch {
+		case onLeave[leaveKey(it.UserID, it.StartAt)]:
+			it.Skip = "cuti disetujui"
+			res.Skipped++
+			continue
+		case it.StartAt.Before(prevEnd[it.UserID]):
+			it.Conflict = "bentrok dengan shift salinan sebelumnya"
+		default:
+			if ok, err := s.schedules.ExistsOverlap(it.UserID, it.StartAt, it.EndAt, nil); err != nil {
+				return nil, err
+			} else if ok {
+				it.Conflict = "bentrok dengan jadwal yang ada"
+			}
+		}
+		if it.Conflict != "" {
+			res.Conflicts++
+			continue
+		}
+		prevEnd[it.UserID] = it.EndAt
+	}
+	res.Total = len(res.Items)
+	planned := make([]domain.Schedule, 0, res.Total)
+	for _, it := range res.Items {
+		if it.Skip == "" && it.Conflict == "" {
+			planned = append(planned, domain.Schedule{UserID: it.UserID, StartAt: it.StartAt, EndAt: it.EndAt})
+		}
+	}
+	if res.LaborViolations, err = s.labor.CheckBatch(nil, planned); err != nil {
+		return nil, err
+	}
+	if in.DryRun {
+		return res, nil
+	}
+	if res.Conflicts > 0 && !in.SkipConflicts {
+		return res, ErrCloneConflict
+	}
+	if len(res.LaborViolations) > 0 {
+		return res, &LaborRuleError{Violations: res.LaborViolations}
+	}
+
+	vctx := WithScheduleChange(ctx, domain.ScheduleSourceClone,
+		fmt.Sprintf("clone %s %s → %s", in.Period, res.SourceFrom, res.TargetFrom))
+	err = s.schedules.Tx(func(tx *gorm.DB) error {
+		created := make([]domain.Schedule, 0, res.Total)
+		for i := range res.Items {
+			it := &res.Items[i]
+			if it.Skip != "" || it.Conflict != "" {
+				continue
+			}
+			// cek ulang di dalam transaksi: jadwal bisa berubah setelah preview
+			ok, err := s.schedules.ExistsOverlapTx(tx, it.UserID, it.StartAt, it.EndAt, nil)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return fmt.Errorf("%w: user #%d %s", repository.ErrScheduleOverlap, it.UserID, it.StartAt.Format("2006-01-02 15:04"))
+			}
+			m := &domain.Schedule{
+				UserID: it.UserID, StartAt: it.StartAt, EndAt: it.EndAt, Channel: it.Channel,
+				ShiftName: it.ShiftName, ShiftTemplateID: it.shiftTemplateID, Notes: it.notes,
+				Status: domain.ScheduleDraft,
+			}
+			if err := tx.Create(m).Error; err != nil {
+				return err
+			}
+			it.ScheduleID = m.ID
+			created = append(created, *m)
+		}
+		res.Created = len(created)
+		return appendScheduleVersions(vctx, s.versions, tx, domain.ScheduleOpCreate, nil, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "schedule.clone", domain.AuditSchedule, 0, nil, map[string]any{
+		"period": in.Period, "source_from": res.SourceFrom, "target_from": res.TargetFrom,
+		"user_ids": in.UserIDs, "channel": in.Channel,
+		"created": res.Created, "skipped": res.Skipped, "conflicts": res.Conflicts,
+	})
+	return res, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+)
+
+func TestCloneSourceDay(t *testing.T) {
+	d := func(y int, m time.Month, day int) time.Time { return clock.Date(y, m, day) }
+	// minggu: Senin 5 Okt 2026 → Senin 19 Okt 2026
+	weekSrc, weekDst := d(2026, time.October, 5), d(2026, time.October, 19)
+	// bulan: Oktober 2026 (mulai Kamis) → November 2026 (mulai Minggu)
+	octFrom, novFrom := d(2026, time.October, 1), d(2026, time.November, 1)
+
+	tests := []struct {
+		name                string
+		period              ClonePeriod
+		day, srcFrom, srcTo time.Time
+		dstFrom             time.Time
+		want                time.Time
+	}{
+		{name: "minggu: Senin", period: CloneWeek, day: weekDst, srcFrom: weekSrc, srcTo: weekSrc.AddDate(0, 0, 7), dstFrom: weekDst, want: weekSrc},
+		{name: "minggu: Rabu", period: CloneWeek, day: d(2026, time.October, 21), srcFrom: weekSrc, srcTo: weekSrc.AddDate(0, 0, 7), dstFrom: weekDst, want: d(2026, time.October, 7)},
+		{name: "minggu: Minggu", period: CloneWeek, day: d(2026, time.October, 25), srcFrom: weekSrc, srcTo: weekSrc.AddDate(0, 0, 7), dstFrom: weekDst, want: d(2026, time.October, 11)},
+		{name: "bulan: Minggu pertama", period: CloneMonth, day: novFrom, srcFrom: octFrom, srcTo: novFrom, dstFrom: novFrom, want: d(2026, time.October, 4)},
+		{name: "bulan: Senin pertama", period: CloneMonth, day: d(2026, time.November, 2), srcFrom: octFrom, srcTo: novFrom, dstFrom: novFrom, want: d(2026, time.October, 5)},
+		{name: "bulan: Selasa ke-3", period: CloneMonth, day: d(2026, time.November, 17), srcFrom: octFrom, srcTo: novFrom, dstFrom: novFrom, want: d(2026, time.October, 20)},
+		{name: "bulan: Senin ke-5 → Senin terakhir", period: CloneMonth, day: d(2026, time.November, 30), srcFrom: octFrom, srcTo: novFrom, dstFrom: novFrom, want: d(2026, time.October, 26)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cloneSourceDay(tt.period, tt.day, tt.srcFrom, tt.srcTo, tt.dstFrom)
+			if !got.Equal(tt.want) {
+				t.Errorf("cloneSourceDay(%s) = %s, want %s", tt.day.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+			if got.Weekday() != tt.day.Weekday() {
+				t.Errorf("hari %s ≠ %s", got.Weekday(), tt.day.Weekday())
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/tabular"
+)
+
+// Warna sel sama dengan halaman ScheduleMatrix di dashboard.
+const (
+	rosterFillVoice  = "#DBEAFE"
+	rosterFillSosmed = "#DCFCE7"
+	rosterFillOff    = "#FFE4F5"
+	rosterOffLabel   = "OFF"
+)
+
+// MonthlyRoster menyusun matriks agent × hari untuk export. Baris = agent aktif
+// ditambah semua user yang punya jadwal di bulan itu (termasuk yang sudah
+// nonaktif/diarsipkan, supaya export bulan lalu tetap utuh); scope (nil = semua)
+// membatasi ke user tertentu, mis. anggota tim. Hanya jadwal PUBLISHED; DRAFT
+// belum berlaku sehingga tidak ikut diexport. Kolom name, email lalu tanggal
+// "1".."31" sehingga CSV/XLSX hasil export bisa di-import ulang. Untuk PDF
+// (compact) kolom email dibuang dan label dipersingkat.
+func (s *ScheduleService) MonthlyRoster(month time.Time, scope []uint, compact bool) (tabular.Sheet, error) {
+	monthStart := clock.MonthStart(month)
+	days := monthStart.AddDate(0, 1, -1).Day()
+
+	items, err := s.schedules.ListMonthly(nil, monthStart)
+	if err != nil {
+		return tabular.Sheet{}, err
+	}
+	active, _, err := s.users.ListActive(1, 5000)
+	if err != nil {
+		return tabular.Sheet{}, err
+	}
+	var inScope map[uint]bool
+	if scope != nil {
+		inScope = make(map[uint]bool, len(scope))
+		for _, id := range scope {
+			inScope[id] = true
+		}
+	}
+
+	// user → hari ke-(0..days-1) → jadwal
+	byUser := map[uint]map[int][]domain.Schedule{}
+	for _, it := range items {
+		if it.Status == domain.ScheduleDraft {
+			continue
+		}
+		st := it.StartAt.In(clock.Location())
+		if byUser[it.UserID] == nil {
+			byUser[it.UserID] = map[int][]domain.Schedule{}
+		}
+		byUser[it.UserID][st.Day()-1] = append(byUser[it.UserID][st.Day()-1], it)
+	}
+
+	// user yang punya jadwal diambil tanpa filter aktif/arsip
+	ids := make([]uint, 0, len(byUser))
+	for id := range byUser {
+		ids = append(ids, id)
+	}
+	withShifts, err := s.users.ListByIDsWithArchived(ids)
+	if err != nil {
+		return tabular.Sheet{}, err
+	}
+	rowsUsers := make([]domain.User, 0, len(withShifts)+len(active))
+	listed := map[uint]bool{}
+	for _, u := range append(withShifts, active...) {
+		if listed[u.ID] || (inScope != nil && !inScope[u.ID]) {
+			continue
+		}
+		if _, has := byUser[u.ID]; has || userHasRole(&u, domain.RoleAgent) {
+			listed[u.ID] = true
+			rowsUsers = append(rowsUsers, u)
+		}
+	}
+	sort.Slice(rowsUsers, func(i, j int) bool {
+		return strings.ToLower(rowsUsers[i].FullName) < strings.ToLower(rowsUsers[j].FullName)
+	})
+
+	fixed := []string{"name", "email"}
+	if compact {
+		fixed = []string{"Nama Agent"}
+	}
+	sh := tabular.Sheet{
+		Name:       fmt.Sprintf("Roster %s", monthStart.Format("2006-01")),
+		Header:     append([]string{}, fixed...),
+		Rows:       make([][]string, 0, len(rowsUsers)),
+		Fills:      make([][]string, 0, len(rowsUsers)),
+		FreezeCols: len(fixed),
+	}
+	for d := 1; d <= days; d++ {
+		sh.Header = append(sh.Header, fmt.Sprint(d))
+	}
+	for _, u := range rowsUsers {
+		row := []string{u.FullName}
+		if !compact {
+			row = append(row, u.Email)
+		}
+		fill := make([]string, len(row), len(row)+days)
+		for d := 0; d < days; d++ {
+			shifts := byUser[u.ID][d]
+			if len(shifts) == 0 {
+				row = append(row, rosterOffLabel)
+				fill = append(fill, rosterFillOff)
+				continue
+			}
+			labels := make([]string, 0, len(shifts))
+			for _, sc := range shifts {
+				labels = append(labels, rosterLabel(sc, compact))
+			}
+			row = append(row, strings.Join(labels, " / "))
+			fill = append(fill, rosterFill(shifts[0].Channel))
+		}
+		sh.Rows = append(sh.Rows, row)
+		sh.Fills = append(sh.Fills, fill)
+	}
+	return sh, nil
+}
+
+// rosterLabel: nama shift kalau ada, selain itu jam mulai–selesai.
+func rosterLabel(sc domain.Schedule, compact bool) string {
+	if sc.ShiftName != nil && strings.TrimSpace(*sc.ShiftName) != "" {
+		return strings.TrimSpace(*sc.ShiftName)
+	}
+	st, en := sc.StartAt.In(clock.Location()), sc.EndAt.In(clock.Location())
+	if compact {
+		return st.Format("15:04")
+	}
+	return fmt.Sprintf("%s-%s %s", st.Format("15:04"), en.Format("15:04"), sc.Channel)
+}
+
+func rosterFill(ch domain.WorkChannel) string {
+	switch ch {
+	case domain.ChannelVoice:
+		return rosterFillVoice
+	case domain.ChannelSosmed:
+		return rosterFillSosmed
+	}
+	return ""
+}
+
+func userHasRole(u *domain.User, rn domain.RoleName) bool {
+	for _, r := range u.Roles {
+		if r.Name == rn {
+			return true
+		}
+	}
+	return false
+}
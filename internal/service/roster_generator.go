@@ -0,0 +1,559 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// langkah titik cek coverage saat menghitung kebutuhan per shift
+const rosterStep = 30 * time.Minute
+
+type RosterService struct {
+	drafts    repository.RosterDraftRepository
+	shifts    repository.ShiftRepository
+	schedules repository.ScheduleRepository
+	versions  repository.ScheduleVersionRepository
+	users     repository.UserRepository
+	leaves    repository.LeaveRepository
+	coverage  repository.CoverageRepository
+	holidays  repository.PublicHolidayRepository
+	labor     *LaborService
+	audit     *AuditService
+}
+
+func NewRosterService(
+	drafts repository.RosterDraftRepository,
+	shifts repository.ShiftRepository,
+	schedules repository.ScheduleRepository,
+	versions repository.ScheduleVersionRepository,
+	users repository.UserRepository,
+	leaves repository.LeaveRepository,
+	coverage repository.CoverageRepository,
+	holidays repository.PublicHolidayRepository,
+	labor *LaborService,
+	audit *AuditService,
+) *RosterService {
+	return &RosterService{
+		drafts: drafts, shifts: shifts, schedules: schedules, versions: versions, users: users,
+		leaves: leaves, coverage: coverage, holidays: holidays, labor: labor, audit: audit,
+	}
+}
+
+type GenerateRosterInput struct {
+	Month       time.Time
+	TeamID      *uint
+	Scope       []uint // nil = semua agent aktif
+	TemplateIDs []uint // kosong = semua template aktif
+}
+
+// RosterGap = kebutuhan shift yang tidak bisa diisi (agent habis / aturan jam kerja).
+type RosterGap struct {
+	Date     string             `json:"date"`
+	Shift    string             `json:"shift"`
+	Channel  domain.WorkChannel `json:"channel"`
+	Needed   int                `json:"needed"`
+	Assigned int                `json:"assigned"`
+}
+
+// RosterFairness = beban per agent di bulan itu (jadwal lama + hasil generator).
+type RosterFairness struct {
+	UserID   uint    `json:"user_id"`
+	UserName string  `json:"user_name"`
+	Shifts   int     `json:"shifts"`
+	Weekend  int     `json:"weekend"`
+	Night    int     `json:"night"`
+	Hours    float64 `json:"hours"`
+}
+
+type RosterGenerateResult struct {
+	Draft    *domain.RosterDraft `json:"-"`
+	Gaps     []RosterGap         `json:"gaps"`
+	Fairness []RosterFairness    `json:"fairness"`
+}
+
+// state per agent selama generate
+type rosterAgent struct {
+	id      uint
+	name    string
+	channel domain.WorkChannel // channel dominan bulan lalu ("" = belum ada)
+	items   []domain.Schedule  // jadwal lama + hasil generator (untuk aturan jam kerja)
+	fair    RosterFairness
+}
+
+// Generate menyusun draft roster satu bulan secara greedy per hari dan per
+// shift (urut jam mulai). Kebutuhan tiap shift = kekurangan terbesar coverage
+// requirement di jam shift tersebut setelah jadwal yang sudah ada dan hasil
+// generator sebelumnya dihitung. Kandidat: agent aktif yang tidak cuti, belum
+// punya shift di tanggal itu, dan lolos aturan jam kerja; dipilih yang channel
+// dominannya cocok, lalu yang paling sedikit mendapat shift weekend/malam
+// (sesuai jenis shift), lalu total shift & jam paling sedikit.
+func (s *RosterService) Generate(ctx context.Context, in GenerateRosterInput) (*RosterGenerateResult, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	monthStart := clock.MonthStart(in.Month)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	templates, err := s.rosterTemplates(in.TemplateIDs)
+	if err != nil {
+		return nil, err
+	}
+	reqs, err := s.coverage.List(nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(reqs) == 0 {
+		return nil, errors.New("belum ada coverage requirement aktif")
+	}
+	rules, err := s.labor.Rules()
+	if err != nil {
+		return nil, err
+	}
+	if !rules.Active {
+		rules = &domain.LaborRules{}
+	}
+	// jadwal lama: sebulan ke belakang untuk konteks aturan jam kerja
+	existing, err := s.schedules.ListActiveRange(monthStart.AddDate(0, 0, -31), monthEnd.AddDate(0, 0, 1), nil)
+	if err != nil {
+		return nil, err
+	}
+	onLeave, err := approvedLeaveDays(s.leaves, monthStart.AddDate(0, 0, -1), monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	offDays, err := holidayDays(s.holidays, monthStart.AddDate(0, 0, -1), monthEnd.AddDate(0, 0, 2))
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := s.rosterAgents(in.Scope, existing, monthStart, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, errors.New("tidak ada agent aktif untuk dijadwalkan")
+	}
+
+	// shift yang menghitung coverage per channel (user aktif, tidak cuti)
+	covering := map[domain.WorkChannel][]domain.Schedule{}
+	for _, sc := range existing {
+		if !onLeave[leaveKey(sc.UserID, sc.StartAt)] {
+			covering[sc.Channel] = append(covering[sc.Channel], sc)
+		}
+	}
+
+	draft := &domain.RosterDraft{
+		Month: monthStart, TeamID: in.TeamID, Status: domain.RosterDraftOpen, CreatedBy: p.ID,
+		Items: []domain.RosterDraftItem{},
+	}
+	res := &RosterGenerateResult{Draft: draft, Gaps: []RosterGap{}}
+	months := []time.Time{monthStart}
+
+	for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+		weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+		for _, t := range templates {
+			windows, err := requirementWindows(reqs, t.Channel, day.AddDate(0, 0, -1), day.AddDate(0, 0, 2), offDays)
+			if err != nil {
+				return nil, err
+			}
+			st, en, err := t.Window(day, clock.Location())
+			if err != nil {
+				return nil, err
+			}
+			need := shiftShortfall(windows, covering[t.Channel], st, en)
+			if need == 0 {
+				continue
+			}
+			night := nightShift(st, en)
+			cand := domain.Schedule{StartAt: st, EndAt: en, Channel: t.Channel}
+
+			pool := make([]*rosterAgent, 0, len(agents))
+			for _, a := range agents {
+				if onLeave[leaveKey(a.id, day)] || !a.canTake(cand, rules, months) {
+					continue
+				}
+				pool = append(pool, a)
+			}
+			sort.SliceStable(pool, func(i, j int) bool {
+				a, b := pool[i], pool[j]
+				am, bm := a.channel != "" && a.channel != t.Channel, b.channel != "" && b.channel != t.Channel
+				if am != bm {
+					return !am
+				}
+				if weekend && a.fair.Weekend != b.fair.Weekend {
+					return a.fair.Weekend < b.fair.Weekend
+				}
+				if night && a.fair.Night != b.fair.Night {
+					return a.fair.Night < b.fair.Night
+				}
+				if a.fair.Shifts != b.fair.Shifts {
+					return a.fair.Shifts < b.fair.Shifts
+				}
+				if a.fair.Hours != b.fair.Hours {
+					return a.fair.Hours < b.fair.Hours
+				}
+				return a.id < b.id
+			})
+
+			assigned := 0
+			for _, a := range pool {
+				if assigned == need {
+					break
+				}
+				sc := cand
+				sc.UserID = a.id
+				a.take(sc, weekend, night)
+				covering[t.Channel] = append(covering[t.Channel], sc)
+				name := t.Name
+				tid := t.ID
+				draft.Items = append(draft.Items, domain.RosterDraftItem{
+					UserID: a.id, StartAt: st, EndAt: en, Channel: t.Channel,
+					ShiftName: &name, ShiftTemplateID: &tid,
+				})
+				assigned++
+			}
+			if assigned < need {
+				res.Gaps = append(res.Gaps, RosterGap{
+					Date: day.Format("2006-01-02"), Shift: t.Name, Channel: t.Channel,
+					Needed: need, Assigned: assigned,
+				})
+				draft.Unfilled += need - assigned
+			}
+		}
+	}
+
+	if err := s.drafts.Create(draft); err != nil {
+		return nil, err
+	}
+	for _, a := range agents {
+		res.Fairness = append(res.Fairness, a.fair)
+	}
+	sortFairness(res.Fairness)
+	s.audit.Record(ctx, "roster.generate", domain.AuditRoster, draft.ID, nil, map[string]any{
+		"month": monthStart.Format("2006-01"), "team_id": in.TeamID, "items": len(draft.Items),
+		"unfilled": draft.Unfilled, "agents": len(agents),
+	})
+	return res, nil
+}
+
+func (s *RosterService) rosterTemplates(ids []uint) ([]domain.ShiftTemplate, error) {
+	all, err := s.shifts.ListTemplates(true)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		want := map[uint]bool{}
+		for _, id := range ids {
+			want[id] = true
+		}
+		var out []domain.ShiftTemplate
+		for _, t := range all {
+			if want[t.ID] {
+				out = append(out, t)
+				delete(want, t.ID)
+			}
+		}
+		for id := range want {
+			return nil, fmt.Errorf("shift template #%d tidak ditemukan / nonaktif", id)
+		}
+		all = out
+	}
+	if len(all) == 0 {
+		return nil, errors.New("belum ada shift template aktif")
+	}
+	return all, nil // sudah urut start_time
+}
+
+// rosterAgents = user aktif ber-role AGENT (dalam scope) beserta jadwal lama
+// dan beban bulan berjalan.
+func (s *RosterService) rosterAgents(scope []uint, existing []domain.Schedule, monthStart, monthEnd time.Time) ([]*rosterAgent, error) {
+	users, _, err := s.users.ListActive(1, 5000)
+	if err != nil {
+		return nil, err
+	}
+	var inScope map[uint]bool
+	if scope != nil {
+		inScope = make(map[uint]bool, len(scope))
+		for _, id := range scope {
+			inScope[id] = true
+		}
+	}
+	byID := map[uint]*rosterAgent{}
+	var out []*rosterAgent
+	for i := range users {
+		u := &users[i]
+		if !userHasRole(u, domain.RoleAgent) || (inScope != nil && !inScope[u.ID]) {
+			continue
+		}
+		a := &rosterAgent{id: u.ID, name: u.FullName, fair: RosterFairness{UserID: u.ID, UserName: u.FullName}}
+		byID[u.ID] = a
+		out = append(out, a)
+	}
+	chCount := map[uint]map[domain.WorkChannel]int{}
+	for _, sc := range existing {
+		a := byID[sc.UserID]
+		if a == nil {
+			continue
+		}
+		a.items = append(a.items, sc)
+		if sc.StartAt.Before(monthStart) {
+			if chCount[a.id] == nil {
+				chCount[a.id] = map[domain.WorkChannel]int{}
+			}
+			chCount[a.id][sc.Channel]++
+		} else if sc.StartAt.Before(monthEnd) {
+			a.fair.add(sc)
+		}
+	}
+	for id, counts := range chCount {
+		best := 0
+		for ch, n := range counts {
+			if n > best || (n == best && ch < byID[id].channel) {
+				best, byID[id].channel = n, ch
+			}
+		}
+	}
+	return out, nil
+}
+
+// canTake: satu shift per tanggal, tidak overlap, dan lolos aturan jam kerja.
+func (a *rosterAgent) canTake(cand domain.Schedule, rules *domain.LaborRules, months []time.Time) bool {
+	day := dateOnly(cand.StartAt)
+	for _, it := range a.items {
+		if dateOnly(it.StartAt).Equal(day) || (it.StartAt.Before(cand.EndAt) && it.EndAt.After(cand.StartAt)) {
+			return false
+		}
+	}
+	cand.UserID = a.id
+	items := make([]domain.Schedule, 0, len(a.items)+1)
+	items = append(append(items, a.items...), cand)
+	return len(touchingViolations(evaluateLabor(rules, a.id, items, months), []domain.Schedule{cand})) == 0
+}
+
+func (a *rosterAgent) take(sc domain.Schedule, weekend, night bool) {
+	a.items = append(a.items, sc)
+	a.fair.Shifts++
+	a.fair.Hours += sc.EndAt.Sub(sc.StartAt).Hours()
+	if weekend {
+		a.fair.Weekend++
+	}
+	if night {
+		a.fair.Night++
+	}
+}
+
+func (f *RosterFairness) add(sc domain.Schedule) {
+	f.Shifts++
+	f.Hours += sc.EndAt.Sub(sc.StartAt).Hours()
+	if wd := sc.StartAt.In(clock.Location()).Weekday(); wd == time.Saturday || wd == time.Sunday {
+		f.Weekend++
+	}
+	if nightShift(sc.StartAt, sc.EndAt) {
+		f.Night++
+	}
+}
+
+func sortFairness(list []RosterFairness) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].UserName != list[j].UserName {
+			return list[i].UserName < list[j].UserName
+		}
+		return list[i].UserID < list[j].UserID
+	})
+}
+
+// nightShift = shift lewat tengah malam atau mulai dini hari.
+func nightShift(st, en time.Time) bool {
+	return dateOnly(en.Add(-time.Minute)).After(dateOnly(st)) || st.In(clock.Location()).Hour() < 5
+}
+
+// shiftShortfall = kekurangan headcount terbesar di jam [st, en) dengan
+// perhitungan yang sama seperti laporan coverage (slot tertutup penuh).
+func shiftShortfall(windows []reqWindow, shifts []domain.Schedule, st, en time.Time) int {
+	worst := 0
+	for t := st; t.Before(en); t = t.Add(rosterStep) {
+		slotEnd := t.Add(rosterStep)
+		required := 0
+		for _, w := range windows {
+			if !w.start.After(t) && w.end.After(t) {
+				required += w.headcount
+			}
+		}
+		if required == 0 {
+			continue
+		}
+		seen := map[uint]bool{}
+		for _, sc := range shifts {
+			if !sc.StartAt.After(t) && !sc.EndAt.Before(slotEnd) {
+				seen[sc.UserID] = true
+			}
+		}
+		if short := required - len(seen); short > worst {
+			worst = short
+		}
+	}
+	return worst
+}
+
+// ===== Draft =====
+
+func (s *RosterService) ListDrafts(month *time.Time) ([]domain.RosterDraft, error) {
+	return s.drafts.List(month)
+}
+
+func (s *RosterService) GetDraft(id uint) (*domain.RosterDraft, error) { return s.drafts.FindByID(id) }
+
+// Fairness menghitung beban per agent untuk item draft (tanpa jadwal lama).
+func (s *RosterService) Fairness(d *domain.RosterDraft) []RosterFairness {
+	byUser := map[uint]*RosterFairness{}
+	ids := []uint{}
+	for _, it := range d.Items {
+		f := byUser[it.UserID]
+		if f == nil {
+			f = &RosterFairness{UserID: it.UserID}
+			byUser[it.UserID] = f
+			ids = append(ids, it.UserID)
+		}
+		f.add(domain.Schedule{StartAt: it.StartAt, EndAt: it.EndAt})
+	}
+	names, _ := s.users.NamesByIDs(ids)
+	out := make([]RosterFairness, 0, len(byUser))
+	for _, f := range byUser {
+		f.UserName = names[f.UserID]
+		out = append(out, *f)
+	}
+	sortFairness(out)
+	return out
+}
+
+func openDraft(d *domain.RosterDraft) error {
+	if d.Status != domain.RosterDraftOpen {
+		return fmt.Errorf("draft sudah %s", d.Status)
+	}
+	return nil
+}
+
+// RemoveItem membuang satu shift dari draft sebelum di-apply.
+func (s *RosterService) RemoveItem(ctx context.Context, draftID, itemID uint) error {
+	d, err := s.drafts.FindByID(draftID)
+	if err != nil {
+		return err
+	}
+	if err := openDraft(d); err != nil {
+		return err
+	}
+	ok, err := s.drafts.DeleteItem(draftID, itemID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	s.audit.Record(ctx, "roster.item_remove", domain.AuditRoster, draftID, map[string]any{"item_id": itemID}, nil)
+	return nil
+}
+
+func (s *RosterService) Discard(ctx context.Context, id uint) error {
+	d, err := s.drafts.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := openDraft(d); err != nil {
+		return err
+	}
+	d.Status = domain.RosterDraftDiscarded
+	if err := s.drafts.Save(d); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "roster.discard", domain.AuditRoster, d.ID, nil, map[string]any{"status": d.Status})
+	return nil
+}
+
+// DraftLaborViolations = pelanggaran aturan jam kerja kalau draft di-apply
+// sekarang (jadwal bisa berubah setelah draft disusun). Draft yang sudah
+// ditutup tidak dicek.
+func (s *RosterService) DraftLaborViolations(d *domain.RosterDraft) ([]LaborViolation, error) {
+	if openDraft(d) != nil {
+		return nil, nil
+	}
+	return s.labor.CheckBatch(nil, draftSchedules(d))
+}
+
+func draftSchedules(d *domain.RosterDraft) []domain.Schedule {
+	out := make([]domain.Schedule, len(d.Items))
+	for i, it := range d.Items {
+		out[i] = domain.Schedule{UserID: it.UserID, StartAt: it.StartAt, EndAt: it.EndAt}
+	}
+	return out
+}
+
+// Apply membuat jadwal dari seluruh item draft dalam satu transaksi; bentrok
+// dengan jadwal yang dibuat setelah draft disusun membatalkan semuanya,
+// begitu juga pelanggaran aturan jam kerja (LaborRuleError).
+func (s *RosterService) Apply(ctx context.Context, id uint) (int, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return 0, err
+	}
+	d, err := s.drafts.FindByID(id)
+	if err != nil {
+		return 0, err
+	}
+	if err := openDraft(d); err != nil {
+		return 0, err
+	}
+	violations, err := s.labor.CheckBatch(nil, draftSchedules(d))
+	if err != nil {
+		return 0, err
+	}
+	if len(violations) > 0 {
+		return 0, &LaborRuleError{Violations: violations}
+	}
+	err = s.drafts.Tx(func(tx *gorm.DB) error {
+		created := make([]domain.Schedule, 0, len(d.Items))
+		for _, it := range d.Items {
+			ok, err := s.schedules.ExistsOverlapTx(tx, it.UserID, it.StartAt, it.EndAt, nil)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return fmt.Errorf("%w: user #%d %s", repository.ErrScheduleOverlap, it.UserID, it.StartAt.Format("2006-01-02 15:04"))
+			}
+			m := &domain.Schedule{
+				UserID: it.UserID, StartAt: it.StartAt, EndAt: it.EndAt, Channel: it.Channel,
+				ShiftName: it.ShiftName, ShiftTemplateID: it.ShiftTemplateID, Status: domain.ScheduleDraft,
+			}
+			if err := tx.Create(m).Error; err != nil {
+				return err
+			}
+			created = append(created, *m)
+		}
+		vctx := WithScheduleChange(ctx, domain.ScheduleSourceRoster, fmt.Sprintf("roster draft #%d", d.ID))
+		if err := appendScheduleVersions(vctx, s.versions, tx, domain.ScheduleOpCreate, nil, created); err != nil {
+			return err
+		}
+		now := time.Now()
+		d.Status = domain.RosterDraftApplied
+		d.AppliedAt = &now
+		d.AppliedBy = &p.ID
+		return tx.Omit("Items").Save(d).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.audit.Record(ctx, "roster.apply", domain.AuditRoster, d.ID, nil, map[string]any{
+		"month": d.Month.Format("2006-01"), "created": len(d.Items),
+	})
+	return len(d.Items), nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+)
+
+func TestShiftShortfall(t *testing.T) {
+	at := func(h, m int) time.Time { return octAt(10, h, m) }
+	sc := testShift
+	morning := reqWindow{start: at(8, 0), end: at(12, 0), headcount: 2}
+
+	tests := []struct {
+		name    string
+		windows []reqWindow
+		shifts  []domain.Schedule
+		st, en  time.Time
+		want    int
+	}{
+		{name: "tanpa kebutuhan", shifts: []domain.Schedule{sc(1, at(8, 0), at(16, 0))}, st: at(8, 0), en: at(16, 0), want: 0},
+		{name: "belum ada agent", windows: []reqWindow{morning}, st: at(8, 0), en: at(16, 0), want: 2},
+		{name: "kurang satu", windows: []reqWindow{morning}, shifts: []domain.Schedule{sc(1, at(8, 0), at(16, 0))}, st: at(8, 0), en: at(16, 0), want: 1},
+		{
+			name: "terpenuhi", windows: []reqWindow{morning},
+			shifts: []domain.Schedule{sc(1, at(8, 0), at(16, 0)), sc(2, at(7, 0), at(15, 0))},
+			st:     at(8, 0), en: at(16, 0), want: 0,
+		},
+		{
+			name: "slot yang tidak tertutup penuh tidak dihitung", windows: []reqWindow{morning},
+			shifts: []domain.Schedule{sc(1, at(8, 0), at(16, 0)), sc(2, at(8, 15), at(16, 0))},
+			st:     at(8, 0), en: at(16, 0), want: 1,
+		},
+		{
+			name:    "kebutuhan window yang tumpang tindih dijumlah",
+			windows: []reqWindow{morning, {start: at(10, 0), end: at(11, 0), headcount: 1}},
+			shifts:  []domain.Schedule{sc(1, at(8, 0), at(16, 0)), sc(2, at(8, 0), at(16, 0))},
+			st:      at(8, 0), en: at(16, 0), want: 1,
+		},
+		{
+			name: "user sama dihitung sekali", windows: []reqWindow{morning},
+			shifts: []domain.Schedule{sc(1, at(8, 0), at(12, 0)), sc(1, at(8, 0), at(10, 0))},
+			st:     at(8, 0), en: at(12, 0), want: 1,
+		},
+		{name: "di luar jam shift", windows: []reqWindow{morning}, st: at(13, 0), en: at(21, 0), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shiftShortfall(tt.windows, tt.shifts, tt.st, tt.en); got != tt.want {
+				t.Errorf("shiftShortfall() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/tabular"
+
+	"gorm.io/gorm"
+)
+
+// Roster matrix: satu baris per agent (kolom "email"), kolom hari berjudul
+// angka tanggal ("1".."31") atau "YYYY-MM-DD". Sel berisi kode / nama shift
+// template, atau kosong / OFF / "-" untuk libur. Kolom lain (mis. "name") diabaikan.
+
+const maxRosterRows = 500
+
+type RosterMode string
+
+const (
+	// RosterMerge: jadwal lama dipertahankan, shift baru tidak boleh bentrok.
+	RosterMerge RosterMode = "merge"
+	// RosterReplace: jadwal bulan itu milik agent yang ada di file dihapus dulu.
+	RosterReplace RosterMode = "replace"
+)
+
+type RosterImportRow struct {
+	Row     int      `json:"row"` // nomor baris di file (header = 1)
+	Email   string   `json:"email"`
+	UserID  uint     `json:"user_id,omitempty"`
+	Shifts  int      `json:"shifts"`
+	Status  string   `json:"status"` // OK | ERROR | IMPORTED
+	Errors  []string `json:"errors,omitempty"`
+	planned []*domain.Schedule
+}
+
+type RosterImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Month   string            `json:"month"`
+	Mode    RosterMode        `json:"mode"`
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Invalid int               `json:"invalid"`
+	Shifts  int               `json:"shifts"`  // shift yang (akan) dibuat
+	Deleted int               `json:"deleted"` // jadwal lama yang (akan) dihapus, mode replace
+	Created int               `json:"created"`
+	Rows    []RosterImportRow `json:"rows"`
+	// pelanggaran aturan jam kerja; barisnya ikut ERROR
+	LaborViolations []LaborViolation `json:"labor_violations"`
+}
+
+// errRosterRollback membatalkan transaksi untuk dry-run / file yang tidak valid.
+var errRosterRollback = errors.New("roster rollback")
+
+// ImportRoster memvalidasi matriks roster satu bulan lalu menulisnya dalam satu
+// transaksi. Pengecekan bentrok dilakukan di dalam transaksi (setelah hapus
+// untuk mode replace) sehingga dry-run melaporkan hasil yang persis sama;
+// aturan jam kerja dicek per agent atas shift di file. Dry-run atau satu
+// baris error → transaksi di-rollback.
+func (s *ShiftService) ImportRoster(ctx context.Context, rows [][]string, month time.Time, mode RosterMode, dryRun bool) (*RosterImportResult, error) {
+	if mode == "" {
+		mode = RosterMerge
+	}
+	if mode != RosterMerge && mode != RosterReplace {
+		return nil, errors.New("mode harus merge atau replace")
+	}
+	if len(rows) < 2 {
+		return nil, errors.New("file kosong (minimal header + 1 baris)")
+	}
+	if len(rows)-1 > maxRosterRows {
+		return nil, fmt.Errorf("maksimal %d agent per import", maxRosterRows)
+	}
+	monthStart := clock.MonthStart(month)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	idx := tabular.HeaderIndex(rows[0])
+	emailCol, ok := idx["email"]
+	if !ok {
+		return nil, errors.New(`kolom "email" tidak ada di header`)
+	}
+	dayCols, err := rosterDayColumns(rows[0], emailCol, monthStart)
+	if err != nil {
+		return nil, err
+	}
+	if len(dayCols) == 0 {
+		return nil, errors.New("tidak ada kolom tanggal di header")
+	}
+
+	templates, err := s.shifts.ListTemplates(true)
+	if err != nil {
+		return nil, err
+	}
+	lookup := make(map[string]domain.ShiftTemplate, len(templates)*2)
+	for _, t := range templates {
+		lookup[strings.ToUpper(t.Name)] = t
+	}
+	for _, t := range templates { // kode menang atas nama kalau sama
+		if t.Code != nil {
+			lookup[*t.Code] = t
+		}
+	}
+
+	res := &RosterImportResult{DryRun: dryRun, Month: monthStart.Format("2006-01"), Mode: mode, Rows: make([]RosterImportRow, 0, len(rows)-1)}
+	seen := map[string]int{}
+	for i, raw := range rows[1:] {
+		r := RosterImportRow{Row: i + 2, Email: strings.ToLower(tabular.Cell(raw, idx, "email"))}
+		switch {
+		case r.Email == "":
+			r.Errors = append(r.Errors, "email wajib diisi")
+		case seen[r.Email] != 0:
+			r.Errors = append(r.Errors, fmt.Sprintf("agent duplikat dengan baris %d", seen[r.Email]))
+		default:
+			seen[r.Email] = r.Row
+			if u, err := s.users.FindByEmail(r.Email); err != nil {
+				r.Errors = append(r.Errors, "agent tidak ditemukan")
+			} else if !u.Active {
+				r.Errors = append(r.Errors, "agent tidak aktif")
+			} else {
+				r.UserID = u.ID
+			}
+		}
+		for _, dc := range dayCols {
+			code := ""
+			if dc.col < len(raw) {
+				code = strings.ToUpper(raw[dc.col])
+			}
+			if isOffCode(code) {
+				continue
+			}
+			day := dc.date.Format("2006-01-02")
+			t, ok := lookup[code]
+			if !ok {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: kode shift %q tidak dikenal", day, code))
+				continue
+			}
+			st, en, err := t.Window(dc.date, clock.Location())
+			if err != nil {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", day, err))
+				continue
+			}
+			name, tid := t.Name, t.ID
+			r.planned = append(r.planned, &domain.Schedule{
+				UserID: r.UserID, StartAt: st, EndAt: en, Channel: t.Channel,
+				ShiftName: &name, ShiftTemplateID: &tid, Status: domain.ScheduleDraft,
+			})
+		}
+		sort.Slice(r.planned, func(a, b int) bool { return r.planned[a].StartAt.Before(r.planned[b].StartAt) })
+		for k := 1; k < len(r.planned); k++ {
+			if r.planned[k].StartAt.Before(r.planned[k-1].EndAt) {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: bentrok dengan shift sebelumnya di file",
+					r.planned[k].StartAt.Format("2006-01-02")))
+			}
+		}
+		r.Shifts = len(r.planned)
+		res.Rows = append(res.Rows, r)
+	}
+	res.Total = len(res.Rows)
+
+	vctx := WithScheduleChange(ctx, domain.ScheduleSourceImport, "import roster "+monthStart.Format("2006-01"))
+	removed := map[uint][]uint{} // jadwal lama yang diganti (mode replace), untuk cek jam kerja
+	err = s.schedules.Tx(func(tx *gorm.DB) error {
+		if mode == RosterReplace {
+			ids := make([]uint, 0, len(res.Rows))
+			for _, r := range res.Rows {
+				if r.UserID != 0 {
+					ids = append(ids, r.UserID)
+				}
+			}
+			if len(ids) > 0 {
+				var old []domain.Schedule
+				if err := tx.Where("user_id IN ? AND start_at >= ? AND start_at < ?", ids, monthStart, monthEnd).
+					Find(&old).Error; err != nil {
+					return err
+				}
+				if len(old) > 0 {
+					oldIDs := make([]uint, len(old))
+					for k := range old {
+						oldIDs[k] = old[k].ID
+						removed[old[k].UserID] = append(removed[old[k].UserID], old[k].ID)
+					}
+					del := tx.Where("id IN ?", oldIDs).Delete(&domain.Schedule{})
+					if del.Error != nil {
+						return del.Error
+					}
+					res.Deleted = int(del.RowsAffected)
+					if err := appendScheduleVersions(vctx, s.versions, tx, domain.ScheduleOpDelete, old, nil); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		var created []domain.Schedule
+		for i := range res.Rows {
+			r := &res.Rows[i]
+			if len(r.Errors) > 0 {
+				continue
+			}
+			for _, m := range r.planned {
+				ok, err := s.schedules.ExistsOverlapTx(tx, m.UserID, m.StartAt, m.EndAt, nil)
+				if err != nil {
+					return err
+				}
+				if ok {
+					r.Errors = append(r.Errors, fmt.Sprintf("%s: bentrok dengan jadwal yang ada", m.StartAt.Format("2006-01-02")))
+					continue
+				}
+				if err := tx.Create(m).Error; err != nil {
+					return err
+				}
+				created = append(created, *m)
+			}
+			if len(r.Errors) > 0 {
+				continue
+			}
+			planned := make([]domain.Schedule, len(r.planned))
+			for k, m := range r.planned {
+				planned[k] = *m
+			}
+			out, err := s.labor.Check(r.UserID, removed[r.UserID], planned)
+			if err != nil {
+				return err
+			}
+			for _, v := range out {
+				r.Errors = append(r.Errors, "aturan jam kerja: "+v.Message)
+			}
+			res.LaborViolations = append(res.LaborViolations, out...)
+		}
+		if err := appendScheduleVersions(vctx, s.versions, tx, domain.ScheduleOpCreate, nil, created); err != nil {
+			return err
+		}
+		for i := range res.Rows {
+			r := &res.Rows[i]
+			if len(r.Errors) > 0 {
+				r.Status = "ERROR"
+				res.Invalid++
+			} else {
+				r.Status = "OK"
+				res.Valid++
+				res.Shifts += r.Shifts
+			}
+		}
+		if dryRun || res.Invalid > 0 {
+			return errRosterRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRosterRollback) {
+		return nil, err
+	}
+	if dryRun || res.Invalid > 0 {
+		if !dryRun {
+			res.Deleted = 0 // tidak ada yang dihapus
+		}
+		return res, nil
+	}
+
+	for i := range res.Rows {
+		res.Rows[i].Status = "IMPORTED"
+	}
+	res.Created = res.Shifts
+	s.audit.Record(ctx, "schedule.import_roster", domain.AuditSchedule, 0, nil, map[string]any{
+		"month": res.Month, "mode": mode, "agents": res.Total, "created": res.Created, "deleted": res.Deleted,
+	})
+	return res, nil
+}
+
+type rosterDayCol struct {
+	col  int
+	date time.Time
+}
+
+// rosterDayColumns membaca judul kolom tanggal. Judul yang bukan angka /
+// tanggal diabaikan; angka atau tanggal di luar bulan ditolak.
+func rosterDayColumns(header []string, emailCol int, monthStart time.Time) ([]rosterDayCol, error) {
+	lastDay := monthStart.AddDate(0, 1, -1).Day()
+	out := []rosterDayCol{}
+	seen := map[int]bool{}
+	for i, h := range header {
+		if i == emailCol || h == "" {
+			continue
+		}
+		var d int
+		if n, err := strconv.Atoi(h); err == nil {
+			d = n
+		} else if t, err := time.ParseInLocation("2006-01-02", h, clock.Location()); err == nil {
+			if t.Year() != monthStart.Year() || t.Month() != monthStart.Month() {
+				return nil, fmt.Errorf("kolom %q di luar bulan %s", h, monthStart.Format("2006-01"))
+			}
+			d = t.Day()
+		} else {
+			continue
+		}
+		if d < 1 || d > lastDay {
+			return nil, fmt.Errorf("kolom %q di luar bulan %s", h, monthStart.Format("2006-01"))
+		}
+		if seen[d] {
+			return nil, fmt.Errorf("kolom tanggal %d duplikat", d)
+		}
+		seen[d] = true
+		out = append(out, rosterDayCol{col: i, date: monthStart.AddDate(0, 0, d-1)})
+	}
+	return out, nil
+}
+
+// isOffCode: sel kosong / OFF / "-" / LIBUR = tidak ada shift.
+func isOffCode(v string) bool {
+	switch strings.ToUpper(strings.TrimSpace(v)) {
+	case "", "OFF", "-", "LIBUR":
+		return true
+	}
+	return false
+}
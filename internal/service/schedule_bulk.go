@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrBulkConflict = errors.New("perubahan massal bentrok dengan jadwal lain")
+
+// dry run memakai transaksi yang sama lalu di-rollback
+var errBulkRollback = errors.New("bulk dry run")
+
+const (
+	maxBulkSchedules = 500
+	maxBulkRangeDays = 62
+)
+
+// BulkScheduleFilter memilih jadwal: IDs eksplisit, atau rentang start_at
+// [From, To) dengan filter tambahan opsional. Keduanya boleh digabung.
+type BulkScheduleFilter struct {
+	IDs       []uint
+	From, To  *time.Time
+	UserIDs   []uint
+	Channel   *domain.WorkChannel
+	ShiftName *string
+	// batas tim dari handler (nil = semua)
+	Scope []uint
+}
+
+type BulkScheduleChange struct {
+	ShiftMinutes int                 // geser start & end (boleh negatif)
+	Channel      *domain.WorkChannel // ganti channel
+	ShiftName    *string             // ganti nama shift ("" = kosongkan)
+}
+
+type BulkScheduleState struct {
+	StartAt   time.Time          `json:"start_at"`
+	EndAt     time.Time          `json:"end_at"`
+	Channel   domain.WorkChannel `json:"channel"`
+	ShiftName *string            `json:"shift_name"`
+}
+
+type BulkScheduleItem struct {
+	ID       uint               `json:"id"`
+	UserID   uint               `json:"user_id"`
+	Before   BulkScheduleState  `json:"before"`
+	After    *BulkScheduleState `json:"after,omitempty"` // nil untuk hapus
+	Conflict string             `json:"conflict,omitempty"`
+}
+
+type BulkScheduleResult struct {
+	DryRun    bool               `json:"dry_run"`
+	Matched   int                `json:"matched"`
+	Conflicts int                `json:"conflicts"`
+	Updated   int                `json:"updated,omitempty"`
+	Deleted   int                `json:"deleted,omitempty"`
+	Items     []BulkScheduleItem `json:"items"`
+	// pelanggaran aturan jam kerja setelah geser jam (juga saat dry run)
+	LaborViolations []LaborViolation `json:"labor_violations"`
+}
+
+func bulkState(m domain.Schedule) BulkScheduleState {
+	return BulkScheduleState{StartAt: m.StartAt, EndAt: m.EndAt, Channel: m.Channel, ShiftName: m.ShiftName}
+}
+
+func (f BulkScheduleFilter) validate() error {
+	if len(f.IDs) == 0 && (f.From == nil || f.To == nil) {
+		return errors.New("isi ids atau rentang from & to")
+	}
+	if (f.From == nil) != (f.To == nil) {
+		return errors.New("from dan to harus diisi bersamaan")
+	}
+	if f.From != nil {
+		if !f.To.After(*f.From) {
+			return errors.New("to harus setelah from")
+		}
+		if f.To.Sub(*f.From) > maxBulkRangeDays*24*time.Hour {
+			return fmt.Errorf("rentang maksimal %d hari", maxBulkRangeDays)
+		}
+	}
+	if len(f.IDs) > maxBulkSchedules {
+		return fmt.Errorf("maksimal %d jadwal per perubahan", maxBulkSchedules)
+	}
+	return nil
+}
+
+// bulkTargets mengunci (FOR UPDATE) jadwal yang cocok dengan filter.
+func bulkTargets(tx *gorm.DB, f BulkScheduleFilter) ([]domain.Schedule, error) {
+	q := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(&domain.Schedule{})
+	if len(f.IDs) > 0 {
+		q = q.Where("id IN ?", f.IDs)
+	}
+	if f.From != nil {
+		q = q.Where("start_at >= ? AND start_at < ?", *f.From, *f.To)
+	}
+	if len(f.UserIDs) > 0 {
+		q = q.Where("user_id IN ?", f.UserIDs)
+	}
+	if f.Scope != nil {
+		q = q.Where("user_id IN ?", append([]uint{0}, f.Scope...))
+	}
+	if f.Channel != nil {
+		q = q.Where("channel = ?", *f.Channel)
+	}
+	if f.ShiftName != nil {
+		q = q.Where("shift_name = ?", *f.ShiftName)
+	}
+	var out []domain.Schedule
+	if err := q.Order("user_id ASC, start_at ASC").Limit(maxBulkSchedules + 1).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	if len(out) > maxBulkSchedules {
+		return nil, fmt.Errorf("lebih dari %d jadwal cocok; persempit filter", maxBulkSchedules)
+	}
+	if len(f.IDs) > 0 {
+		uniq := map[uint]bool{}
+		for _, id := range f.IDs {
+			uniq[id] = true
+		}
+		if len(out) != len(uniq) {
+			return nil, errors.New("sebagian jadwal tidak ditemukan atau tidak cocok dengan filter")
+		}
+	}
+	return out, nil
+}
+
+// BulkUpdate menerapkan satu perubahan (geser jam / channel / nama shift) ke
+// semua jadwal yang cocok. Overlap dicek terhadap kondisi SETELAH perubahan:
+// antar jadwal yang diubah dan dengan jadwal lain milik agent yang sama.
+// Semua atau tidak sama sekali; ada bentrok → ErrBulkConflict + laporannya.
+func (s *ScheduleService) BulkUpdate(ctx context.Context, f BulkScheduleFilter, ch BulkScheduleChange, dryRun bool) (*BulkScheduleResult, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	if ch.ShiftMinutes == 0 && ch.Channel == nil && ch.ShiftName == nil {
+		return nil, errors.New("tidak ada perubahan (shift_minutes, channel, atau shift_name)")
+	}
+	if ch.Channel != nil && *ch.Channel != domain.ChannelVoice && *ch.Channel != domain.ChannelSosmed {
+		return nil, errors.New("channel must be VOICE or SOSMED")
+	}
+	var newName *string
+	if ch.ShiftName != nil {
+		if v := strings.TrimSpace(*ch.ShiftName); v != "" {
+			newName = &v
+		}
+	}
+	shift := time.Duration(ch.ShiftMinutes) * time.Minute
+
+	res := &BulkScheduleResult{DryRun: dryRun, Items: []BulkScheduleItem{}}
+	err := s.schedules.Tx(func(tx *gorm.DB) error {
+		before, err := bulkTargets(tx, f)
+		if err != nil {
+			return err
+		}
+		res.Matched = len(before)
+		if len(before) == 0 {
+			return nil
+		}
+		after := make([]domain.Schedule, len(before))
+		ids := make([]uint, len(before))
+		users := map[uint]bool{}
+		minStart, maxEnd := before[0].StartAt.Add(shift), before[0].EndAt.Add(shift)
+		for i, m := range before {
+			m.StartAt, m.EndAt = m.StartAt.Add(shift), m.EndAt.Add(shift)
+			if ch.Channel != nil {
+				m.Channel = *ch.Channel
+			}
+			if ch.ShiftName != nil {
+				m.ShiftName = newName
+			}
+			after[i], ids[i] = m, m.ID
+			users[m.UserID] = true
+			if m.StartAt.Before(minStart) {
+				minStart = m.StartAt
+			}
+			if m.EndAt.After(maxEnd) {
+				maxEnd = m.EndAt
+			}
+		}
+
+		// kondisi akhir per agent = jadwal lain (tidak ikut diubah) + hasil perubahan
+		userIDs := make([]uint, 0, len(users))
+		for uid := range users {
+			userIDs = append(userIDs, uid)
+		}
+		var others []domain.Schedule
+		if err := tx.Where("user_id IN ? AND id NOT IN ? AND start_at < ? AND end_at > ?", userIDs, ids, maxEnd, minStart).
+			Find(&others).Error; err != nil {
+			return err
+		}
+		byUser := map[uint][]domain.Schedule{}
+		for _, o := range others {
+			byUser[o.UserID] = append(byUser[o.UserID], o)
+		}
+		for i, m := range after {
+			it := BulkScheduleItem{ID: m.ID, UserID: m.UserID, Before: bulkState(before[i])}
+			st := bulkState(m)
+			it.After = &st
+			if ok, err := s.users.IsActive(m.UserID); err != nil {
+				return err
+			} else if !ok {
+				it.Conflict = "user is inactive"
+			}
+			for _, o := range byUser[m.UserID] {
+				if it.Conflict == "" && m.StartAt.Before(o.EndAt) && o.StartAt.Before(m.EndAt) {
+					it.Conflict = fmt.Sprintf("bentrok dengan jadwal #%d", o.ID)
+				}
+			}
+			for j, o := range after {
+				if it.Conflict == "" && j != i && o.UserID == m.UserID && m.StartAt.Before(o.EndAt) && o.StartAt.Before(m.EndAt) {
+					it.Conflict = fmt.Sprintf("bentrok dengan jadwal #%d setelah perubahan", o.ID)
+				}
+			}
+			if it.Conflict != "" {
+				res.Conflicts++
+			}
+			res.Items = append(res.Items, it)
+		}
+		if ch.ShiftMinutes != 0 {
+			if res.LaborViolations, err = s.bulkLaborViolations(before, after); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return errBulkRollback
+		}
+		if res.Conflicts > 0 {
+			return ErrBulkConflict
+		}
+		if len(res.LaborViolations) > 0 {
+			return &LaborRuleError{Violations: res.LaborViolations}
+		}
+		for i := range after {
+			if err := tx.Save(&after[i]).Error; err != nil {
+				return err
+			}
+		}
+		res.Updated = len(after)
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpUpdate, before, after)
+	})
+	switch {
+	case errors.Is(err, errBulkRollback):
+		return res, nil
+	case errors.Is(err, ErrBulkConflict):
+		return res, err
+	case err != nil:
+		return nil, err
+	}
+	if res.Updated > 0 {
+		s.audit.Record(ctx, "schedule.bulk_update", domain.AuditSchedule, 0, nil, map[string]any{
+			"ids": bulkIDs(res.Items), "shift_minutes": ch.ShiftMinutes, "channel": ch.Channel,
+			"shift_name": ch.ShiftName, "updated": res.Updated,
+		})
+	}
+	return res, nil
+}
+
+// bulkLaborViolations: aturan jam kerja per agent setelah geser jam;
+// pelanggaran semua agent digabung.
+func (s *ScheduleService) bulkLaborViolations(before, after []domain.Schedule) ([]LaborViolation, error) {
+	removed := map[uint][]uint{}
+	for i := range after {
+		removed[after[i].UserID] = append(removed[after[i].UserID], before[i].ID)
+	}
+	return s.labor.CheckBatch(removed, after)
+}
+
+// BulkDelete menghapus semua jadwal yang cocok dalam satu transaksi.
+func (s *ScheduleService) BulkDelete(ctx context.Context, f BulkScheduleFilter, dryRun bool) (*BulkScheduleResult, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	res := &BulkScheduleResult{DryRun: dryRun, Items: []BulkScheduleItem{}}
+	err := s.schedules.Tx(func(tx *gorm.DB) error {
+		before, err := bulkTargets(tx, f)
+		if err != nil {
+			return err
+		}
+		res.Matched = len(before)
+		ids := make([]uint, len(before))
+		for i, m := range before {
+			ids[i] = m.ID
+			res.Items = append(res.Items, BulkScheduleItem{ID: m.ID, UserID: m.UserID, Before: bulkState(m)})
+		}
+		if dryRun || len(before) == 0 {
+			return errBulkRollback
+		}
+		if err := tx.Where("id IN ?", ids).Delete(&domain.Schedule{}).Error; err != nil {
+			return err
+		}
+		res.Deleted = len(ids)
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpDelete, before, nil)
+	})
+	if err != nil && !errors.Is(err, errBulkRollback) {
+		return nil, err
+	}
+	if res.Deleted > 0 {
+		s.audit.Record(ctx, "schedule.bulk_delete", domain.AuditSchedule, 0, nil, map[string]any{
+			"ids": bulkIDs(res.Items), "deleted": res.Deleted,
+		})
+	}
+	return res, nil
+}
+
+func bulkIDs(items []BulkScheduleItem) []uint {
+	out := make([]uint, len(items))
+	for i, it := range items {
+		out[i] = it.ID
+	}
+	return out
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type scheduleChangeKey struct{}
+
+type scheduleChange struct {
+	source domain.ScheduleSource
+	reason string
+}
+
+// WithScheduleChange menandai perubahan jadwal berikutnya di ctx dengan
+// sumber & alasan; dicatat di versi jadwal. Tanpa penanda = MANUAL.
+func WithScheduleChange(ctx context.Context, source domain.ScheduleSource, reason string) context.Context {
+	return context.WithValue(ctx, scheduleChangeKey{}, scheduleChange{source: source, reason: strings.TrimSpace(reason)})
+}
+
+func scheduleChangeFrom(ctx context.Context) scheduleChange {
+	if c, ok := ctx.Value(scheduleChangeKey{}).(scheduleChange); ok {
+		return c
+	}
+	return scheduleChange{source: domain.ScheduleSourceManual}
+}
+
+func snapshotSchedule(m domain.Schedule) domain.ScheduleVersion {
+	return domain.ScheduleVersion{
+		ScheduleID: m.ID, UserID: m.UserID, StartAt: m.StartAt, EndAt: m.EndAt, Channel: m.Channel,
+		ShiftName: m.ShiftName, ShiftTemplateID: m.ShiftTemplateID, Notes: m.Notes, Status: m.Status,
+	}
+}
+
+// appendScheduleVersions mencatat perubahan; before = kondisi lama (UPDATE /
+// DELETE), after = kondisi baru (CREATE / UPDATE). tx nil = di luar transaksi.
+func appendScheduleVersions(ctx context.Context, repo repository.ScheduleVersionRepository, tx *gorm.DB,
+	op domain.ScheduleChangeOp, before, after []domain.Schedule) error {
+	if repo == nil {
+		return nil
+	}
+	ch := scheduleChangeFrom(ctx)
+	actor := actorOrNil(auth.ActorID(ctx))
+	var reason *string
+	if ch.reason != "" {
+		reason = &ch.reason
+	}
+
+	baseline := make([]domain.ScheduleVersion, 0, len(before))
+	for _, b := range before {
+		v := snapshotSchedule(b)
+		v.Op, v.Source = domain.ScheduleOpCreate, domain.ScheduleSourceLegacy
+		v.CreatedAt = b.UpdatedAt
+		if v.CreatedAt.IsZero() {
+			v.CreatedAt = b.CreatedAt
+		}
+		baseline = append(baseline, v)
+	}
+	src := after
+	if op == domain.ScheduleOpDelete {
+		src = before
+	}
+	items := make([]domain.ScheduleVersion, 0, len(src))
+	for _, m := range src {
+		v := snapshotSchedule(m)
+		v.Op, v.Source, v.Reason, v.ActorID = op, ch.source, reason, actor
+		items = append(items, v)
+	}
+	return repo.Append(tx, baseline, items)
+}
+
+// recordVersions = appendScheduleVersions di luar transaksi; gagal hanya di-log
+// (sama seperti audit) karena perubahan jadwalnya sudah tersimpan.
+func (s *ScheduleService) recordVersions(ctx context.Context, op domain.ScheduleChangeOp, before, after []domain.Schedule) {
+	if err := appendScheduleVersions(ctx, s.versions, nil, op, before, after); err != nil {
+		log.Printf("[schedule-version] FAILED op=%s err=%v", op, err)
+	}
+}
+
+// ===== History & diff =====
+
+func (s *ScheduleService) History(scheduleID uint) ([]domain.ScheduleVersion, error) {
+	return s.versions.ListBySchedule(scheduleID)
+}
+
+type ScheduleSnapshot struct {
+	VersionID uint                  `json:"version_id"`
+	UserID    uint                  `json:"user_id"`
+	StartAt   time.Time             `json:"start_at"`
+	EndAt     time.Time             `json:"end_at"`
+	Channel   domain.WorkChannel    `json:"channel"`
+	ShiftName *string               `json:"shift_name"`
+	Notes     *string               `json:"notes"`
+	Status    domain.ScheduleStatus `json:"status"`
+	Source    domain.ScheduleSource `json:"source"`
+	Reason    *string               `json:"reason"`
+	ActorID   *uint                 `json:"actor_id"`
+	At        time.Time             `json:"at"`
+}
+
+func scheduleSnapshot(v *domain.ScheduleVersion) *ScheduleSnapshot {
+	if v == nil || v.Op == domain.ScheduleOpDelete {
+		return nil
+	}
+	return &ScheduleSnapshot{
+		VersionID: v.ID, UserID: v.UserID, StartAt: v.StartAt, EndAt: v.EndAt, Channel: v.Channel,
+		ShiftName: v.ShiftName, Notes: v.Notes, Status: v.Status,
+		Source: v.Source, Reason: v.Reason, ActorID: v.ActorID, At: v.CreatedAt,
+	}
+}
+
+type ScheduleDiffEntry struct {
+	ScheduleID uint              `json:"schedule_id"`
+	Change     string            `json:"change"` // ADDED | REMOVED | CHANGED
+	Fields     []string          `json:"fields,omitempty"`
+	Before     *ScheduleSnapshot `json:"before"`
+	After      *ScheduleSnapshot `json:"after"`
+	// perubahan terakhir yang membentuk kondisi "after" (untuk REMOVED: penghapusnya)
+	Source domain.ScheduleSource `json:"source"`
+	Reason *string               `json:"reason"`
+}
+
+type ScheduleDiff struct {
+	Month   string              `json:"month"`
+	From    time.Time           `json:"from"`
+	To      time.Time           `json:"to"`
+	Added   int                 `json:"added"`
+	Removed int                 `json:"removed"`
+	Changed int                 `json:"changed"`
+	Entries []ScheduleDiffEntry `json:"entries"`
+}
+
+// MonthDiff membandingkan kondisi jadwal bulan itu pada waktu from dan to
+// berdasarkan versi yang tercatat.
+func (s *ScheduleService) MonthDiff(month, from, to time.Time) (*ScheduleDiff, error) {
+	if !to.After(from) {
+		return nil, errors.New("to harus setelah from")
+	}
+	monthStart := clock.MonthStart(month)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	versions, err := s.versions.ListForMonth(monthStart, monthEnd, to)
+	if err != nil {
+		return nil, err
+	}
+	// kondisi per jadwal pada from & to = versi terakhir <= waktu tsb
+	type pair struct{ at, now *domain.ScheduleVersion }
+	states := map[uint]*pair{}
+	var ids []uint
+	for i := range versions {
+		v := &versions[i]
+		p := states[v.ScheduleID]
+		if p == nil {
+			p = &pair{}
+			states[v.ScheduleID] = p
+			ids = append(ids, v.ScheduleID)
+		}
+		if !v.CreatedAt.After(from) {
+			p.at = v
+		}
+		p.now = v
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	inMonth := func(v *ScheduleSnapshot) bool {
+		return v != nil && !v.StartAt.Before(monthStart) && v.StartAt.Before(monthEnd)
+	}
+	diff := &ScheduleDiff{Month: monthStart.Format("2006-01"), From: from, To: to, Entries: []ScheduleDiffEntry{}}
+	for _, id := range ids {
+		p := states[id]
+		if p.at == p.now {
+			continue // tidak berubah di rentang ini
+		}
+		before, after := scheduleSnapshot(p.at), scheduleSnapshot(p.now)
+		if !inMonth(before) && !inMonth(after) {
+			continue
+		}
+		e := ScheduleDiffEntry{ScheduleID: id, Before: before, After: after, Source: p.now.Source, Reason: p.now.Reason}
+		switch {
+		case before == nil && after == nil:
+			continue // dibuat lalu dihapus di rentang ini
+		case before == nil:
+			e.Change = "ADDED"
+			diff.Added++
+		case after == nil:
+			e.Change = "REMOVED"
+			diff.Removed++
+		default:
+			e.Fields = changedScheduleFields(before, after)
+			if len(e.Fields) == 0 {
+				continue
+			}
+			e.Change = "CHANGED"
+			diff.Changed++
+		}
+		diff.Entries = append(diff.Entries, e)
+	}
+	return diff, nil
+}
+
+func changedScheduleFields(a, b *ScheduleSnapshot) []string {
+	var out []string
+	if a.UserID != b.UserID {
+		out = append(out, "user_id")
+	}
+	if !a.StartAt.Equal(b.StartAt) {
+		out = append(out, "start_at")
+	}
+	if !a.EndAt.Equal(b.EndAt) {
+		out = append(out, "end_at")
+	}
+	if a.Channel != b.Channel {
+		out = append(out, "channel")
+	}
+	if derefStr(a.ShiftName) != derefStr(b.ShiftName) {
+		out = append(out, "shift_name")
+	}
+	if derefStr(a.Notes) != derefStr(b.Notes) {
+		out = append(out, "notes")
+	}
+	if a.Status != b.Status {
+		out = append(out, "status")
+	}
+	return out
+}
+
+func derefStr(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+// ===== Restore =====
+
+// Restore mengembalikan jadwal ke kondisi versi tertentu (versionID nil =
+// kondisi sebelum perubahan terakhir). Jadwal yang sudah dihapus dibuat ulang
+// dengan ID yang sama. Validasi overlap, user aktif & aturan jam kerja tetap berlaku.
+func (s *ScheduleService) Restore(ctx context.Context, scheduleID uint, versionID *uint, reason string) (*domain.Schedule, error) {
+	versions, err := s.versions.ListBySchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, errors.New("jadwal belum punya riwayat perubahan")
+	}
+	current, err := s.schedules.FindByID(scheduleID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var target *domain.ScheduleVersion
+	if versionID != nil {
+		for i := range versions {
+			if versions[i].ID == *versionID {
+				target = &versions[i]
+			}
+		}
+		if target == nil {
+			return nil, errors.New("versi tidak ditemukan untuk jadwal ini")
+		}
+		if target.Op == domain.ScheduleOpDelete {
+			return nil, errors.New("versi penghapusan tidak bisa di-restore; pilih versi sebelumnya")
+		}
+	} else {
+		// dihapus → versi hidup terakhir; masih ada → versi sebelum yang terakhir
+		last := len(versions) - 1
+		if current != nil {
+			last--
+		}
+		for i := last; i >= 0 && target == nil; i-- {
+			if versions[i].Op != domain.ScheduleOpDelete {
+				target = &versions[i]
+			}
+		}
+		if target == nil {
+			return nil, errors.New("tidak ada versi sebelumnya untuk di-restore")
+		}
+	}
+
+	m := target.Schedule()
+	if err := s.ensureActiveUser(m.UserID); err != nil {
+		return nil, err
+	}
+	if ok, err := s.schedules.ExistsOverlap(m.UserID, m.StartAt, m.EndAt, &m.ID); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, errors.New("schedule overlaps existing slot")
+	}
+	if err := s.labor.Guard(m.UserID, []uint{m.ID}, []domain.Schedule{m}); err != nil {
+		return nil, err
+	}
+
+	ctx = WithScheduleChange(ctx, domain.ScheduleSourceRestore, reason)
+	err = s.schedules.Tx(func(tx *gorm.DB) error {
+		if current != nil {
+			m.CreatedAt = current.CreatedAt
+			m.PublishedAt = current.PublishedAt
+			if err := tx.Save(&m).Error; err != nil {
+				return err
+			}
+			return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpUpdate, []domain.Schedule{*current}, []domain.Schedule{m})
+		}
+		if m.Status == domain.SchedulePublished {
+			now := time.Now()
+			m.PublishedAt = &now
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpCreate, nil, []domain.Schedule{m})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "schedule.restore", domain.AuditSchedule, m.ID, current, map[string]any{
+		"schedule": m, "version_id": target.ID,
+	})
+	return &m, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+)
+
+type PublishMonthResult struct {
+	Month     string `json:"month"`
+	Published int    `json:"published"` // jadwal DRAFT yang berubah jadi PUBLISHED
+	Agents    int    `json:"agents"`    // agent yang dikirimi ringkasan
+}
+
+// PublishMonth mem-publish seluruh jadwal DRAFT bulan itu sekaligus, lalu
+// mengirim satu notifikasi per agent yang terdampak berisi daftar shift
+// PUBLISHED-nya di bulan tersebut.
+func (s *ScheduleService) PublishMonth(ctx context.Context, month time.Time) (*PublishMonthResult, error) {
+	monthStart := clock.MonthStart(month)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	changed, err := s.schedules.PublishMonth(monthStart, monthEnd, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	res := &PublishMonthResult{Month: monthStart.Format("2006-01"), Published: len(changed)}
+	if len(changed) == 0 {
+		return res, nil
+	}
+	before := make([]domain.Schedule, len(changed))
+	for i, it := range changed {
+		it.Status, it.PublishedAt = domain.ScheduleDraft, nil
+		before[i] = it
+	}
+	s.recordVersions(WithScheduleChange(ctx, domain.ScheduleSourcePublish, res.Month), domain.ScheduleOpUpdate, before, changed)
+
+	affected := map[uint]bool{}
+	for _, it := range changed {
+		affected[it.UserID] = true
+	}
+	res.Agents = len(affected)
+	s.audit.Record(ctx, "schedule.publish_month", domain.AuditSchedule, 0, nil, map[string]any{
+		"month": res.Month, "published": res.Published, "agents": res.Agents,
+	})
+
+	if s.notif == nil {
+		return res, nil
+	}
+	all, err := s.schedules.ListMonthly(nil, monthStart)
+	if err != nil {
+		return res, nil // publish sudah tersimpan; notifikasi best-effort
+	}
+	byUser := map[uint][]domain.Schedule{}
+	for _, it := range all {
+		if affected[it.UserID] && it.Status == domain.SchedulePublished {
+			byUser[it.UserID] = append(byUser[it.UserID], it)
+		}
+	}
+	title := fmt.Sprintf("Jadwal %s Terbit", monthStart.Format("01/2006"))
+	for uid, items := range byUser {
+		_ = s.notif.Notify(uid, title, publishSummary(items), "SCHEDULE", nil)
+	}
+	return res, nil
+}
+
+// publishSummary: satu baris per shift, mis. "Sen 02 Jun 07:00–15:00 VOICE (Pagi)".
+func publishSummary(items []domain.Schedule) string {
+	sort.Slice(items, func(i, j int) bool { return items[i].StartAt.Before(items[j].StartAt) })
+	days := []string{"Min", "Sen", "Sel", "Rab", "Kam", "Jum", "Sab"}
+	lines := make([]string, 0, len(items)+1)
+	lines = append(lines, fmt.Sprintf("Total %d shift:", len(items)))
+	for _, it := range items {
+		st, en := it.StartAt.In(clock.Location()), it.EndAt.In(clock.Location())
+		line := fmt.Sprintf("%s %s %s–%s %s", days[st.Weekday()], st.Format("02 Jan"), st.Format("15:04"), en.Format("15:04"), it.Channel)
+		if it.ShiftName != nil && *it.ShiftName != "" {
+			line += " (" + *it.ShiftName + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"time"
 
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
 
@@ -12,10 +15,27 @@ import (
 
 type ScheduleService struct {
 	schedules repository.ScheduleRepository
+	versions  repository.ScheduleVersionRepository
+	users     repository.UserRepository
+	labor     *LaborService
+	notif     *NotificationService
+	audit     *AuditService
 }
 
-func NewScheduleService(s repository.ScheduleRepository) *ScheduleService {
-	return &ScheduleService{schedules: s}
+func NewScheduleService(s repository.ScheduleRepository, versions repository.ScheduleVersionRepository, users repository.UserRepository, labor *LaborService, notif *NotificationService, audit *AuditService) *ScheduleService {
+	return &ScheduleService{schedules: s, versions: versions, users: users, labor: labor, notif: notif, audit: audit}
+}
+
+// user nonaktif tidak boleh dijadwalkan
+func (s *ScheduleService) ensureActiveUser(userID uint) error {
+	ok, err := s.users.IsActive(userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("user is inactive")
+	}
+	return nil
 }
 
 type CreateScheduleInput struct {
@@ -25,29 +45,64 @@ type CreateScheduleInput struct {
 	Channel   domain.WorkChannel
 	ShiftName *string
 	Notes     *string
+	// diisi kalau jadwal dibentuk dari shift template
+	ShiftTemplateID *uint
+	// kosong = DRAFT (belum terlihat agent sampai bulan dipublish)
+	Status domain.ScheduleStatus
 }
 
-func (s *ScheduleService) Create(in CreateScheduleInput) (*domain.Schedule, error) {
+func (s *ScheduleService) Create(ctx context.Context, in CreateScheduleInput) (*domain.Schedule, error) {
+	m, err := s.newSchedule(in)
+	if err != nil {
+		return nil, err
+	}
+	// jadwal & versinya satu transaksi: versi gagal disimpan → create batal
+	err = s.schedules.Tx(func(tx *gorm.DB) error {
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpCreate, nil, []domain.Schedule{*m})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "schedule.create", domain.AuditSchedule, m.ID, nil, m)
+	return m, nil
+}
+
+// newSchedule memvalidasi input (user aktif, bentrok, aturan jam kerja) lalu
+// membentuk jadwal yang siap disimpan.
+func (s *ScheduleService) newSchedule(in CreateScheduleInput) (*domain.Schedule, error) {
 	if in.UserID == 0 || in.EndAt.Sub(in.StartAt) <= 0 {
 		return nil, errors.New("invalid user or time range")
 	}
 	if in.Channel != domain.ChannelVoice && in.Channel != domain.ChannelSosmed {
 		return nil, errors.New("channel must be VOICE or SOSMED")
 	}
+	if err := s.ensureActiveUser(in.UserID); err != nil {
+		return nil, err
+	}
 	if ok, err := s.schedules.ExistsOverlap(in.UserID, in.StartAt, in.EndAt, nil); err != nil {
 		return nil, err
 	} else if ok {
 		return nil, errors.New("schedule overlaps existing slot")
 	}
 	m := &domain.Schedule{
-		UserID:    in.UserID,
-		StartAt:   in.StartAt,
-		EndAt:     in.EndAt,
-		Channel:   in.Channel,
-		ShiftName: in.ShiftName,
-		Notes:     in.Notes,
-	}
-	if err := s.schedules.Create(m); err != nil {
+		UserID:          in.UserID,
+		StartAt:         in.StartAt,
+		EndAt:           in.EndAt,
+		Channel:         in.Channel,
+		ShiftName:       in.ShiftName,
+		Notes:           in.Notes,
+		ShiftTemplateID: in.ShiftTemplateID,
+		Status:          domain.ScheduleDraft,
+	}
+	if in.Status == domain.SchedulePublished {
+		now := time.Now()
+		m.Status = domain.SchedulePublished
+		m.PublishedAt = &now
+	}
+	if err := s.labor.Guard(in.UserID, nil, []domain.Schedule{*m}); err != nil {
 		return nil, err
 	}
 	return m, nil
@@ -57,10 +112,100 @@ func (s *ScheduleService) ListMonthly(userID *uint, month time.Time) ([]domain.S
 	return s.schedules.ListMonthly(userID, month)
 }
 
-func (s *ScheduleService) UpdateSchedule(sch *domain.Schedule) error {
+// ListVisibleMonthly = ListMonthly; tanpa withDrafts jadwal DRAFT dibuang
+// (tampilan agent).
+func (s *ScheduleService) ListVisibleMonthly(userID *uint, month time.Time, withDrafts bool) ([]domain.Schedule, error) {
+	items, err := s.schedules.ListMonthly(userID, month)
+	if err != nil || withDrafts {
+		return items, err
+	}
+	out := items[:0]
+	for _, it := range items {
+		if it.Status != domain.ScheduleDraft {
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+// OwnedOn = jadwal user yang tanggal bisnisnya = day (lihat domain.Schedule.BusinessDate).
+// Shift malam dari hari sebelumnya yang melimpah ke day tidak ikut.
+func (s *ScheduleService) OwnedOn(userID uint, day time.Time) ([]domain.Schedule, error) {
+	d := clock.DayOf(day)
+	items, err := s.schedules.ListByUserRange(userID, d, d.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	out := []domain.Schedule{}
+	for _, it := range items {
+		if it.BusinessDate(clock.Location()).Equal(d) {
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+// OffCarryOver = hari OFF yang jam paginya masih tertutup shift malam hari sebelumnya.
+type OffCarryOver struct {
+	Date       string    `json:"date"`
+	ScheduleID uint      `json:"schedule_id"`
+	Until      time.Time `json:"until"`
+}
+
+type OffDaysResult struct {
+	UserID    uint           `json:"user_id"`
+	Month     string         `json:"month"`
+	OffDates  []string       `json:"off_dates"`
+	CarryOver []OffCarryOver `json:"carry_over"`
+}
+
+// OffDays: tanggal OFF = tanggal tanpa shift milik tanggal itu. Shift malam
+// (termasuk dari tanggal terakhir bulan lalu) tidak membuat tanggal berikutnya
+// busy, tapi dilaporkan di CarryOver supaya tampilan tahu jam berapa agent bebas.
+func (s *ScheduleService) OffDays(userID uint, month time.Time, withDrafts bool) (*OffDaysResult, error) {
+	loc := clock.Location()
+	start := clock.MonthStart(month)
+	end := start.AddDate(0, 1, 0)
+	items, err := s.schedules.ListByUserRange(userID, start.AddDate(0, 0, -1), end)
+	if err != nil {
+		return nil, err
+	}
+	busy := map[string]bool{}
+	spill := map[string]domain.Schedule{}
+	for _, it := range items {
+		if it.Status == domain.ScheduleDraft && !withDrafts {
+			continue
+		}
+		bd := it.BusinessDate(loc)
+		busy[bd.Format("2006-01-02")] = true
+		if it.Overnight(loc) {
+			next := bd.AddDate(0, 0, 1).Format("2006-01-02")
+			if prev, ok := spill[next]; !ok || it.EndAt.After(prev.EndAt) {
+				spill[next] = it
+			}
+		}
+	}
+	res := &OffDaysResult{UserID: userID, Month: start.Format("2006-01"), OffDates: []string{}, CarryOver: []OffCarryOver{}}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		if busy[key] {
+			continue
+		}
+		res.OffDates = append(res.OffDates, key)
+		if it, ok := spill[key]; ok {
+			res.CarryOver = append(res.CarryOver, OffCarryOver{Date: key, ScheduleID: it.ID, Until: it.EndAt.In(loc)})
+		}
+	}
+	return res, nil
+}
+
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, sch *domain.Schedule) error {
 	if sch.EndAt.Sub(sch.StartAt) <= 0 {
 		return errors.New("invalid time range")
 	}
+	if err := s.ensureActiveUser(sch.UserID); err != nil {
+		return err
+	}
 	ex := &sch.ID
 	ok, err := s.schedules.ExistsOverlap(sch.UserID, sch.StartAt, sch.EndAt, ex)
 	if err != nil {
@@ -69,19 +214,105 @@ func (s *ScheduleService) UpdateSchedule(sch *domain.Schedule) error {
 	if ok {
 		return errors.New("schedule overlaps existing slot")
 	}
-	return s.schedules.Update(sch)
+	if err := s.labor.Guard(sch.UserID, []uint{sch.ID}, []domain.Schedule{*sch}); err != nil {
+		return err
+	}
+	before, err := s.schedules.FindByID(sch.ID)
+	if err != nil {
+		return err
+	}
+	err = s.schedules.Tx(func(tx *gorm.DB) error {
+		if err := tx.Save(sch).Error; err != nil {
+			return err
+		}
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpUpdate, []domain.Schedule{*before}, []domain.Schedule{*sch})
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "schedule.update", domain.AuditSchedule, sch.ID, before, sch)
+	return nil
 }
 
 func (s *ScheduleService) FindByID(id uint) (*domain.Schedule, error) {
 	return s.schedules.FindByID(id)
 }
 
-func (s *ScheduleService) Delete(id uint) error { return s.schedules.Delete(id) }
+func (s *ScheduleService) Delete(ctx context.Context, id uint) error {
+	before, err := s.schedules.FindByID(id)
+	if err != nil {
+		return err
+	}
+	err = s.schedules.Tx(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.Schedule{}, id).Error; err != nil {
+			return err
+		}
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpDelete, []domain.Schedule{*before}, nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "schedule.delete", domain.AuditSchedule, id, before, nil)
+	return nil
+}
+
+// ApplyApproval menjalankan perubahan jadwal hasil approval (cuti, tukar
+// libur) dalam satu transaksi: add (opsional) dibuat, remove dihapus, lalu fn
+// menyimpan status pengajuan lewat tx (created = jadwal hasil add, nil kalau
+// tidak ada). Satu langkah gagal → semuanya batal.
+func (s *ScheduleService) ApplyApproval(ctx context.Context, add *CreateScheduleInput, remove []domain.Schedule, fn func(tx *gorm.DB, created *domain.Schedule) error) (*domain.Schedule, error) {
+	var m *domain.Schedule
+	if add != nil {
+		var err error
+		if m, err = s.newSchedule(*add); err != nil {
+			return nil, err
+		}
+	}
+	err := s.schedules.Tx(func(tx *gorm.DB) error {
+		if m != nil {
+			if err := tx.Create(m).Error; err != nil {
+				return err
+			}
+			if err := appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpCreate, nil, []domain.Schedule{*m}); err != nil {
+				return err
+			}
+		}
+		for _, it := range remove {
+			res := tx.Delete(&domain.Schedule{}, it.ID)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("jadwal #%d sudah berubah, ulangi approval", it.ID)
+			}
+		}
+		if len(remove) > 0 {
+			if err := appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpDelete, remove, nil); err != nil {
+				return err
+			}
+		}
+		return fn(tx, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if m != nil {
+		s.audit.Record(ctx, "schedule.create", domain.AuditSchedule, m.ID, nil, m)
+	}
+	for _, it := range remove {
+		s.audit.Record(ctx, "schedule.delete", domain.AuditSchedule, it.ID, it, nil)
+	}
+	return m, nil
+}
 
 func (s *ScheduleService) ExistsOverlap(userID uint, start, end time.Time, excludeID *uint) (bool, error) {
 	return s.schedules.ExistsOverlap(userID, start, end, excludeID)
 }
 
+func (s *ScheduleService) ListByUserRange(userID uint, from, to time.Time) ([]domain.Schedule, error) {
+	return s.schedules.ListByUserRange(userID, from, to)
+}
+
 func (s *ScheduleService) FindByUserAndWindow(userID uint, start, end time.Time) (*domain.Schedule, error) {
 	return s.schedules.FindByUserAndWindow(userID, start, end)
 }
@@ -95,7 +326,7 @@ func (s *ScheduleService) FindByUserAndSameDay(userID uint, dayStart, dayEnd tim
 }
 
 // Swap 2 schedule atomik
-func (s *ScheduleService) SwapSchedules(reqSchID, cpSchID uint, requesterID, counterpartyID uint) error {
+func (s *ScheduleService) SwapSchedules(ctx context.Context, reqSchID, cpSchID uint, requesterID, counterpartyID uint) error {
 	reqSch, err := s.schedules.FindByID(reqSchID)
 	if err != nil {
 		return err
@@ -123,6 +354,10 @@ func (s *ScheduleService) SwapSchedules(reqSchID, cpSchID uint, requesterID, cou
 	} else if ok {
 		return errors.New("swap invalid: jadwal baru counterparty bentrok")
 	}
+	if err := s.guardSwapLabor(reqSch, cpSch); err != nil {
+		return err
+	}
+	before := []domain.Schedule{*reqSch, *cpSch}
 	return s.schedules.Tx(func(tx *gorm.DB) error {
 		reqSch.UserID = counterpartyID
 		if err := tx.Save(reqSch).Error; err != nil {
@@ -132,12 +367,32 @@ func (s *ScheduleService) SwapSchedules(reqSchID, cpSchID uint, requesterID, cou
 		if err := tx.Save(cpSch).Error; err != nil {
 			return err
 		}
-		return nil
+		return appendScheduleVersions(ctx, s.versions, tx, domain.ScheduleOpUpdate, before, []domain.Schedule{*reqSch, *cpSch})
 	})
 }
 
-// ResolveChannelForUser mencoba mendapatkan channel user di window tertentu:
-// urutan: overlap → exact → same-day. Return error kalau tidak ketemu sama sekali.
+// guardSwapLabor: aturan jam kerja untuk kedua pihak setelah jadwal bertukar;
+// pelanggaran keduanya digabung dalam satu LaborRuleError.
+func (s *ScheduleService) guardSwapLabor(reqSch, cpSch *domain.Schedule) error {
+	var all []LaborViolation
+	for _, side := range []struct {
+		user     uint
+		from, to *domain.Schedule
+	}{{reqSch.UserID, reqSch, cpSch}, {cpSch.UserID, cpSch, reqSch}} {
+		err := s.labor.Guard(side.user, []uint{side.from.ID}, []domain.Schedule{*side.to})
+		var le *LaborRuleError
+		if errors.As(err, &le) {
+			all = append(all, le.Violations...)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(all) > 0 {
+		return &LaborRuleError{Violations: all}
+	}
+	return nil
+}
+
 // ResolveChannelForUser mencoba mendapatkan channel user di window tertentu:
 // urutan: overlap → exact → same-day. Return error kalau tidak ketemu sama sekali.
 func (s *ScheduleService) ResolveChannelForUser(userID uint, start, end time.Time) (domain.WorkChannel, error) {
@@ -147,7 +402,7 @@ func (s *ScheduleService) ResolveChannelForUser(userID uint, start, end time.Tim
 	if sch, err := s.schedules.FindByUserAndWindow(userID, start, end); err == nil && sch != nil {
 		return sch.Channel, nil
 	}
-	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	dayStart := clock.DayOf(start)
 	dayEnd := dayStart.Add(24 * time.Hour)
 	if sch, err := s.schedules.FindByUserAndSameDay(userID, dayStart, dayEnd); err == nil && sch != nil {
 		return sch.Channel, nil
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+const (
+	maxRotationDays  = 92
+	maxRotationUsers = 500
+)
+
+var ErrRotationConflict = errors.New("rotasi bentrok dengan jadwal yang ada")
+
+type ShiftService struct {
+	shifts    repository.ShiftRepository
+	schedules repository.ScheduleRepository
+	versions  repository.ScheduleVersionRepository
+	users     repository.UserRepository
+	labor     *LaborService
+	audit     *AuditService
+}
+
+func NewShiftService(shifts repository.ShiftRepository, schedules repository.ScheduleRepository, versions repository.ScheduleVersionRepository, users repository.UserRepository, labor *LaborService, audit *AuditService) *ShiftService {
+	return &ShiftService{shifts: shifts, schedules: schedules, versions: versions, users: users, labor: labor, audit: audit}
+}
+
+// ===== Shift template =====
+
+type ShiftTemplateInput struct {
+	Name      string
+	Code      *string // nil = tidak diubah, "" = kosongkan
+	StartTime string
+	EndTime   string
+	Channel   domain.WorkChannel
+	Active    *bool
+}
+
+func (s *ShiftService) ListTemplates(activeOnly bool) ([]domain.ShiftTemplate, error) {
+	return s.shifts.ListTemplates(activeOnly)
+}
+
+func (s *ShiftService) GetTemplate(id uint) (*domain.ShiftTemplate, error) {
+	return s.shifts.FindTemplate(id)
+}
+
+func (s *ShiftService) CreateTemplate(ctx context.Context, in ShiftTemplateInput) (*domain.ShiftTemplate, error) {
+	t := &domain.ShiftTemplate{Active: true}
+	if err := applyTemplateInput(t, in, true); err != nil {
+		return nil, err
+	}
+	if err := s.shifts.CreateTemplate(t); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "shift_template.create", domain.AuditShift, t.ID, nil, t)
+	return t, nil
+}
+
+// UpdateTemplate: field kosong = tidak diubah. Jadwal yang sudah dibuat dari
+// template ini tidak ikut berubah.
+func (s *ShiftService) UpdateTemplate(ctx context.Context, id uint, in ShiftTemplateInput) (*domain.ShiftTemplate, error) {
+	t, err := s.shifts.FindTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	if err := applyTemplateInput(t, in, false); err != nil {
+		return nil, err
+	}
+	if err := s.shifts.UpdateTemplate(t); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "shift_template.update", domain.AuditShift, t.ID, before, t)
+	return t, nil
+}
+
+// DeleteTemplate ditolak kalau template masih dipakai pola rotasi; nonaktifkan saja.
+func (s *ShiftService) DeleteTemplate(ctx context.Context, id uint) error {
+	before, err := s.shifts.FindTemplate(id)
+	if err != nil {
+		return err
+	}
+	if used, err := s.shifts.TemplateInUse(id); err != nil {
+		return err
+	} else if used {
+		return errors.New("template masih dipakai pola rotasi; nonaktifkan saja")
+	}
+	if err := s.shifts.DeleteTemplate(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "shift_template.delete", domain.AuditShift, id, before, nil)
+	return nil
+}
+
+func applyTemplateInput(t *domain.ShiftTemplate, in ShiftTemplateInput, create bool) error {
+	if n := strings.TrimSpace(in.Name); n != "" {
+		t.Name = n
+	} else if create {
+		return errors.New("name required")
+	}
+	if in.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*in.Code))
+		switch {
+		case code == "":
+			t.Code = nil
+		case len(code) > 10 || strings.ContainsAny(code, " \t,;/"):
+			return errors.New("code maksimal 10 karakter tanpa spasi/pemisah")
+		case isOffCode(code):
+			return fmt.Errorf("code %q dipakai untuk hari libur", code)
+		default:
+			t.Code = &code
+		}
+	}
+	if in.StartTime != "" {
+		t.StartTime = in.StartTime
+	}
+	if in.EndTime != "" {
+		t.EndTime = in.EndTime
+	}
+	if in.Channel != "" {
+		t.Channel = in.Channel
+	}
+	if in.Active != nil {
+		t.Active = *in.Active
+	}
+	if !validHHMM(t.StartTime) || !validHHMM(t.EndTime) {
+		return errors.New("start_time/end_time harus HH:MM")
+	}
+	if t.StartTime == t.EndTime {
+		return errors.New("start_time dan end_time tidak boleh sama")
+	}
+	if t.Channel != domain.ChannelVoice && t.Channel != domain.ChannelSosmed {
+		return errors.New("channel must be VOICE or SOSMED")
+	}
+	return nil
+}
+
+func validHHMM(v string) bool {
+	if len(v) != 5 {
+		return false
+	}
+	_, err := time.Parse("15:04", v)
+	return err == nil
+}
+
+// ===== Rotation pattern =====
+
+type RotationDayInput struct {
+	DayIndex        int
+	ShiftTemplateID uint
+}
+
+type RotationPatternInput struct {
+	Name   string
+	Weeks  int
+	Active *bool
+	Days   []RotationDayInput // hari yang tidak disebut = OFF
+}
+
+func (s *ShiftService) ListPatterns() ([]domain.RotationPattern, error) {
+	return s.shifts.ListPatterns()
+}
+
+func (s *ShiftService) GetPattern(id uint) (*domain.RotationPattern, error) {
+	return s.shifts.FindPattern(id)
+}
+
+func (s *ShiftService) CreatePattern(ctx context.Context, in RotationPatternInput) (*domain.RotationPattern, error) {
+	p := &domain.RotationPattern{Active: true}
+	if err := s.applyPatternInput(p, in, true); err != nil {
+		return nil, err
+	}
+	if err := s.shifts.CreatePattern(p); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "rotation_pattern.create", domain.AuditRotation, p.ID, nil, p)
+	return s.shifts.FindPattern(p.ID)
+}
+
+// UpdatePattern: kalau Days dikirim (termasuk kosong) seluruh slot diganti.
+func (s *ShiftService) UpdatePattern(ctx context.Context, id uint, in RotationPatternInput) (*domain.RotationPattern, error) {
+	p, err := s.shifts.FindPattern(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *p
+	if err := s.applyPatternInput(p, in, false); err != nil {
+		return nil, err
+	}
+	if err := s.shifts.SavePattern(p); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "rotation_pattern.update", domain.AuditRotation, p.ID, before, p)
+	return s.shifts.FindPattern(p.ID)
+}
+
+func (s *ShiftService) DeletePattern(ctx context.Context, id uint) error {
+	before, err := s.shifts.FindPattern(id)
+	if err != nil {
+		return err
+	}
+	if err := s.shifts.DeletePattern(id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "rotation_pattern.delete", domain.AuditRotation, id, before, nil)
+	return nil
+}
+
+func (s *ShiftService) applyPatternInput(p *domain.RotationPattern, in RotationPatternInput, create bool) error {
+	if n := strings.TrimSpace(in.Name); n != "" {
+		p.Name = n
+	} else if create {
+		return errors.New("name required")
+	}
+	if in.Weeks != 0 {
+		p.Weeks = in.Weeks
+	}
+	if p.Weeks < 1 || p.Weeks > 12 {
+		return errors.New("weeks harus 1..12")
+	}
+	if in.Active != nil {
+		p.Active = *in.Active
+	}
+	if in.Days == nil && !create {
+		// slot lama tetap, tapi pastikan masih muat di jumlah minggu baru
+		for _, sl := range p.Slots {
+			if sl.DayIndex >= p.CycleDays() {
+				return fmt.Errorf("slot hari ke-%d di luar siklus %d minggu", sl.DayIndex, p.Weeks)
+			}
+		}
+		return nil
+	}
+	seen := map[int]bool{}
+	slots := make([]domain.RotationSlot, 0, len(in.Days))
+	for _, d := range in.Days {
+		if d.DayIndex < 0 || d.DayIndex >= p.CycleDays() {
+			return fmt.Errorf("day_index %d di luar 0..%d", d.DayIndex, p.CycleDays()-1)
+		}
+		if seen[d.DayIndex] {
+			return fmt.Errorf("day_index %d duplikat", d.DayIndex)
+		}
+		seen[d.DayIndex] = true
+		t, err := s.shifts.FindTemplate(d.ShiftTemplateID)
+		if err != nil {
+			return fmt.Errorf("shift template #%d tidak ditemukan", d.ShiftTemplateID)
+		}
+		if !t.Active {
+			return fmt.Errorf("shift template %q nonaktif", t.Name)
+		}
+		slots = append(slots, domain.RotationSlot{DayIndex: d.DayIndex, ShiftTemplateID: t.ID, ShiftTemplate: *t})
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].DayIndex < slots[j].DayIndex })
+	p.Slots = slots
+	return nil
+}
+
+// ===== Apply rotation =====
+
+type ApplyRotationInput struct {
+	PatternID uint
+	UserIDs   []uint
+	From, To  time.Time // tanggal, inklusif
+	// StaggerWeeks menggeser siklus tiap agent: agent ke-i mulai di minggu
+	// ke-(i*StaggerWeeks) pola, supaya tidak semua agent OFF di hari yang sama.
+	StaggerWeeks  int
+	SkipConflicts bool // lewati hari yang bentrok alih-alih menolak semuanya
+	DryRun        bool
+}
+
+type RotationDay struct {
+	UserID          uint               `json:"user_id"`
+	Date            string             `json:"date"` // YYYY-MM-DD
+	ShiftTemplateID uint               `json:"shift_template_id"`
+	ShiftName       string             `json:"shift_name"`
+	StartAt         time.Time          `json:"start_at"`
+	EndAt           time.Time          `json:"end_at"`
+	Channel         domain.WorkChannel `json:"channel"`
+	Conflict        string             `json:"conflict,omitempty"`
+	ScheduleID      uint               `json:"schedule_id,omitempty"`
+}
+
+type RotationResult struct {
+	DryRun    bool          `json:"dry_run"`
+	Total     int           `json:"total"`     // jumlah shift (hari OFF tidak dihitung)
+	Conflicts int           `json:"conflicts"` // hari bentrok
+	Created   int           `json:"created"`
+	Days      []RotationDay `json:"days"`
+	// pelanggaran aturan jam kerja atas shift yang (akan) dibuat
+	LaborViolations []LaborViolation `json:"labor_violations"`
+}
+
+// ApplyRotation menjabarkan pola ke jadwal per agent per hari. Hari pertama
+// siklus (index 0) = Senin pada minggu tanggal From. Preview (DryRun) dan
+// apply memakai perhitungan yang sama; apply dibuat dalam satu transaksi dan
+// ditolak (LaborRuleError) kalau melanggar aturan jam kerja.
+func (s *ShiftService) ApplyRotation(ctx context.Context, in ApplyRotationInput) (*RotationResult, error) {
+	if len(in.UserIDs) == 0 {
+		return nil, errors.New("user_ids required")
+	}
+	if len(in.UserIDs) > maxRotationUsers {
+		return nil, fmt.Errorf("maksimal %d agent per apply", maxRotationUsers)
+	}
+	from := dateOnly(in.From)
+	to := dateOnly(in.To)
+	if to.Before(from) {
+		return nil, errors.New("to harus >= from")
+	}
+	if daysBetween(from, to)+1 > maxRotationDays {
+		return nil, fmt.Errorf("rentang maksimal %d hari", maxRotationDays)
+	}
+	if in.StaggerWeeks < 0 {
+		return nil, errors.New("stagger_weeks tidak boleh negatif")
+	}
+	p, err := s.shifts.FindPattern(in.PatternID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Active {
+		return nil, errors.New("pola rotasi nonaktif")
+	}
+	bySlot := map[int]domain.ShiftTemplate{}
+	for _, sl := range p.Slots {
+		bySlot[sl.DayIndex] = sl.ShiftTemplate
+	}
+	cycle := p.CycleDays()
+	anchor := weekMonday(from)
+
+	res := &RotationResult{DryRun: in.DryRun, Days: []RotationDay{}}
+	seenUser := map[uint]bool{}
+	for i, uid := range in.UserIDs {
+		if seenUser[uid] {
+			return nil, fmt.Errorf("user #%d duplikat", uid)
+		}
+		seenUser[uid] = true
+		active, err := s.users.IsActive(uid)
+		if err != nil {
+			return nil, err
+		}
+		offset := i * in.StaggerWeeks * 7
+		var prevEnd time.Time
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			idx := (daysBetween(anchor, d) + offset) % cycle
+			t, ok := bySlot[idx]
+			if !ok {
+				continue // OFF
+			}
+			st, en, err := t.Window(d, clock.Location())
+			if err != nil {
+				return nil, err
+			}
+			day := RotationDay{
+				UserID: uid, Date: d.Format("2006-01-02"), ShiftTemplateID: t.ID, ShiftName: t.Name,
+				StartAt: st, EndAt: en, Channel: t.Channel,
+			}
+			switch {
+			case !active:
+				day.Conflict = "user tidak aktif / tidak ditemukan"
+			case st.Before(prevEnd):
+				day.Conflict = "bentrok dengan shift hari sebelumnya di pola ini"
+			default:
+				if ok, err := s.schedules.ExistsOverlap(uid, st, en, nil); err != nil {
+					return nil, err
+				} else if ok {
+					day.Conflict = "bentrok dengan jadwal yang ada"
+				}
+			}
+			if day.Conflict == "" {
+				prevEnd = en
+			} else {
+				res.Conflicts++
+			}
+			res.Days = append(res.Days, day)
+		}
+	}
+	res.Total = len(res.Days)
+
+	items := make([]*domain.Schedule, 0, res.Total-res.Conflicts)
+	refs := make([]int, 0, cap(items))
+	for i := range res.Days {
+		d := &res.Days[i]
+		if d.Conflict != "" {
+			continue
+		}
+		name := d.ShiftName
+		tid := d.ShiftTemplateID
+		items = append(items, &domain.Schedule{
+			UserID: d.UserID, StartAt: d.StartAt, EndAt: d.EndAt, Channel: d.Channel,
+			ShiftName: &name, ShiftTemplateID: &tid, Status: domain.ScheduleDraft,
+		})
+		refs = append(refs, i)
+	}
+	planned := make([]domain.Schedule, len(items))
+	for k, m := range items {
+		planned[k] = *m
+	}
+	if res.LaborViolations, err = s.labor.CheckBatch(nil, planned); err != nil {
+		return nil, err
+	}
+	if in.DryRun {
+		return res, nil
+	}
+	if res.Conflicts > 0 && !in.SkipConflicts {
+		return res, ErrRotationConflict
+	}
+	if len(res.LaborViolations) > 0 {
+		return res, &LaborRuleError{Violations: res.LaborViolations}
+	}
+	if len(items) == 0 {
+		return res, nil
+	}
+	if err := s.schedules.CreateBatch(items); err != nil {
+		return nil, err
+	}
+	created := make([]domain.Schedule, len(items))
+	for k, m := range items {
+		res.Days[refs[k]].ScheduleID = m.ID
+		created[k] = *m
+	}
+	vctx := WithScheduleChange(ctx, domain.ScheduleSourceRotation, fmt.Sprintf("rotasi #%d", p.ID))
+	if err := appendScheduleVersions(vctx, s.versions, nil, domain.ScheduleOpCreate, nil, created); err != nil {
+		log.Printf("[schedule-version] FAILED op=%s err=%v", domain.ScheduleOpCreate, err)
+	}
+	res.Created = len(items)
+	s.audit.Record(ctx, "schedule.apply_rotation", domain.AuditRotation, p.ID, nil, map[string]any{
+		"user_ids": in.UserIDs, "from": from.Format("2006-01-02"), "to": to.Format("2006-01-02"),
+		"stagger_weeks": in.StaggerWeeks, "created": res.Created, "skipped": res.Conflicts,
+	})
+	return res, nil
+}
+
+// ScheduleInputFromTemplate membentuk input jadwal tunggal dari template + tanggal.
+func (s *ShiftService) ScheduleInputFromTemplate(templateID, userID uint, day time.Time, notes *string) (CreateScheduleInput, error) {
+	t, err := s.shifts.FindTemplate(templateID)
+	if err != nil {
+		return CreateScheduleInput{}, errors.New("shift template not found")
+	}
+	if !t.Active {
+		return CreateScheduleInput{}, fmt.Errorf("shift template %q nonaktif", t.Name)
+	}
+	st, en, err := t.Window(day, clock.Location())
+	if err != nil {
+		return CreateScheduleInput{}, err
+	}
+	name := t.Name
+	tid := t.ID
+	return CreateScheduleInput{
+		UserID: userID, StartAt: st, EndAt: en, Channel: t.Channel,
+		ShiftName: &name, ShiftTemplateID: &tid, Notes: notes,
+	}, nil
+}
+
+func dateOnly(t time.Time) time.Time {
+	return clock.DayOf(t)
+}
+
+// daysBetween dibulatkan supaya aman terhadap pergeseran DST.
+func daysBetween(a, b time.Time) int { return int((b.Sub(a).Hours() + 12) / 24) }
+
+// weekMonday = Senin pada minggu tanggal d.
+func weekMonday(d time.Time) time.Time {
+	wd := (int(d.Weekday()) + 6) % 7 // Senin=0
+	return d.AddDate(0, 0, -wd)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type StaffingService struct {
+	rules     repository.StaffingRepository
+	schedules repository.ScheduleRepository
+	perms     *PermissionService
+	audit     *AuditService
+}
+
+func NewStaffingService(rules repository.StaffingRepository, schedules repository.ScheduleRepository, perms *PermissionService, audit *AuditService) *StaffingService {
+	return &StaffingService{rules: rules, schedules: schedules, perms: perms, audit: audit}
+}
+
+// ===== Rule =====
+
+type StaffingRuleInput struct {
+	MinHeadcount int
+	Mode         domain.StaffingMode
+	Active       *bool
+}
+
+func (s *StaffingService) ListRules() ([]domain.StaffingRule, error) { return s.rules.List() }
+
+// SetRule membuat / mengganti rule satu channel.
+func (s *StaffingService) SetRule(ctx context.Context, ch domain.WorkChannel, in StaffingRuleInput) (*domain.StaffingRule, error) {
+	if ch != domain.ChannelVoice && ch != domain.ChannelSosmed {
+		return nil, errors.New("channel must be VOICE or SOSMED")
+	}
+	if in.MinHeadcount < 1 {
+		return nil, errors.New("min_headcount minimal 1")
+	}
+	if in.Mode == "" {
+		in.Mode = domain.StaffingWarn
+	}
+	if in.Mode != domain.StaffingWarn && in.Mode != domain.StaffingBlock {
+		return nil, errors.New("mode harus WARN atau BLOCK")
+	}
+	m, err := s.rules.FindByChannel(ch)
+	var before any
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		m = &domain.StaffingRule{Channel: ch, Active: true}
+	case err != nil:
+		return nil, err
+	default:
+		b := *m
+		before = b
+	}
+	m.MinHeadcount = in.MinHeadcount
+	m.Mode = in.Mode
+	if in.Active != nil {
+		m.Active = *in.Active
+	}
+	if err := s.rules.Save(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "staffing.rule_set", domain.AuditStaffing, m.ID, before, m)
+	return m, nil
+}
+
+func (s *StaffingService) DeleteRule(ctx context.Context, ch domain.WorkChannel) error {
+	before, err := s.rules.FindByChannel(ch)
+	if err != nil {
+		return err
+	}
+	if err := s.rules.DeleteByChannel(ch); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "staffing.rule_delete", domain.AuditStaffing, before.ID, before, nil)
+	return nil
+}
+
+// ===== Check =====
+
+type StaffingViolation struct {
+	Channel   domain.WorkChannel  `json:"channel"`
+	Start     time.Time           `json:"start"` // shift yang berkurang
+	End       time.Time           `json:"end"`
+	At        time.Time           `json:"at"`        // titik headcount terendah
+	Remaining int                 `json:"remaining"` // headcount setelah perubahan
+	Minimum   int                 `json:"minimum"`
+	Mode      domain.StaffingMode `json:"mode"`
+}
+
+type StaffingCheck struct {
+	Violations []StaffingViolation `json:"violations"`
+	Blocked    bool                `json:"blocked"`    // ada rule BLOCK yang dilanggar
+	Overridden bool                `json:"overridden"` // diloloskan dengan override
+}
+
+// StaffingBlockedError = perubahan ditolak rule BLOCK; Check berisi rinciannya.
+type StaffingBlockedError struct{ Check *StaffingCheck }
+
+func (e *StaffingBlockedError) Error() string {
+	for _, v := range e.Check.Violations {
+		if v.Mode == domain.StaffingBlock {
+			return fmt.Sprintf("minimum staffing %s: tersisa %d dari minimal %d agent pada %s",
+				v.Channel, v.Remaining, v.Minimum, v.At.In(clock.Location()).Format("02 Jan 2006 15:04"))
+		}
+	}
+	return "minimum staffing terlanggar"
+}
+
+// Evaluate menghitung headcount per channel setelah jadwal removed dihapus dan
+// added ditambahkan. Hanya rentang jadwal yang dihapus yang dicek (penambahan
+// tidak mungkin menurunkan headcount).
+func (s *StaffingService) Evaluate(removed, added []domain.Schedule) (*StaffingCheck, error) {
+	check := &StaffingCheck{Violations: []StaffingViolation{}}
+	if s == nil || len(removed) == 0 {
+		return check, nil
+	}
+	rules, err := s.rules.List()
+	if err != nil {
+		return nil, err
+	}
+	byChannel := map[domain.WorkChannel]domain.StaffingRule{}
+	for _, r := range rules {
+		if r.Active {
+			byChannel[r.Channel] = r
+		}
+	}
+	gone := map[uint]bool{}
+	for _, r := range removed {
+		gone[r.ID] = true
+	}
+	for _, r := range removed {
+		rule, ok := byChannel[r.Channel]
+		if !ok {
+			continue
+		}
+		ch := r.Channel
+		current, err := s.schedules.ListActiveRange(r.StartAt, r.EndAt, &ch)
+		if err != nil {
+			return nil, err
+		}
+		after := make([]domain.Schedule, 0, len(current)+len(added))
+		for _, sc := range current {
+			if !gone[sc.ID] {
+				after = append(after, sc)
+			}
+		}
+		for _, sc := range added {
+			if sc.Channel == ch && sc.StartAt.Before(r.EndAt) && sc.EndAt.After(r.StartAt) {
+				after = append(after, sc)
+			}
+		}
+		at, low := lowestHeadcount(after, r.StartAt, r.EndAt)
+		if low >= rule.MinHeadcount {
+			continue
+		}
+		check.Violations = append(check.Violations, StaffingViolation{
+			Channel: ch, Start: r.StartAt, End: r.EndAt, At: at,
+			Remaining: low, Minimum: rule.MinHeadcount, Mode: rule.Mode,
+		})
+		if rule.Mode == domain.StaffingBlock {
+			check.Blocked = true
+		}
+	}
+	return check, nil
+}
+
+// lowestHeadcount mencari titik dengan agent (distinct) paling sedikit di [from, to).
+func lowestHeadcount(items []domain.Schedule, from, to time.Time) (time.Time, int) {
+	points := []time.Time{from}
+	for _, sc := range items {
+		for _, t := range []time.Time{sc.StartAt, sc.EndAt} {
+			if t.After(from) && t.Before(to) {
+				points = append(points, t)
+			}
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Before(points[j]) })
+	bestAt, best := from, -1
+	for _, t := range points {
+		seen := map[uint]bool{}
+		for _, sc := range items {
+			if !sc.StartAt.After(t) && sc.EndAt.After(t) {
+				seen[sc.UserID] = true
+			}
+		}
+		if best < 0 || len(seen) < best {
+			bestAt, best = t, len(seen)
+		}
+	}
+	return bestAt, best
+}
+
+// Guard = Evaluate + penerapan mode. Pelanggaran WARN lolos (dikembalikan di
+// check); BLOCK → *StaffingBlockedError kecuali override=true oleh pemegang
+// staffing:override. Override dicatat lewat RecordOverride oleh pemanggil
+// setelah entitasnya tersimpan.
+func (s *StaffingService) Guard(ctx context.Context, removed, added []domain.Schedule, override bool) (*StaffingCheck, error) {
+	check, err := s.Evaluate(removed, added)
+	if err != nil || !check.Blocked {
+		return check, err
+	}
+	if !override {
+		return check, &StaffingBlockedError{Check: check}
+	}
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return check, err
+	}
+	if !s.perms.Has(p.Roles, domain.PermStaffingOverride) {
+		return check, errors.New("override minimum staffing memerlukan permission staffing:override")
+	}
+	check.Overridden = true
+	return check, nil
+}
+
+// RecordOverride mencatat override ke audit log (no-op bila tidak di-override).
+func (s *StaffingService) RecordOverride(ctx context.Context, action, entityType string, entityID uint, check *StaffingCheck) {
+	if s == nil || check == nil || !check.Overridden {
+		return
+	}
+	s.audit.Record(ctx, "staffing.override", entityType, entityID, nil, map[string]any{
+		"action": action, "violations": check.Violations,
+	})
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"bjb-backoffice/internal/domain"
+)
+
+func TestLowestHeadcount(t *testing.T) {
+	at := func(h int) time.Time { return octAt(10, h, 0) }
+	sc := func(uid uint, from, to int) domain.Schedule { return testShift(uid, at(from), at(to)) }
+
+	tests := []struct {
+		name     string
+		items    []domain.Schedule
+		from, to int
+		wantAt   int
+		want     int
+	}{
+		{name: "kosong", from: 8, to: 16, wantAt: 8, want: 0},
+		{name: "titik terendah pertama menang", items: []domain.Schedule{sc(1, 8, 16), sc(2, 12, 20)}, from: 8, to: 20, wantAt: 8, want: 1},
+		{name: "celah di tengah", items: []domain.Schedule{sc(1, 8, 10), sc(2, 11, 13)}, from: 8, to: 13, wantAt: 10, want: 0},
+		{name: "user sama dihitung sekali", items: []domain.Schedule{sc(1, 8, 12), sc(1, 10, 14), sc(2, 8, 14)}, from: 9, to: 11, wantAt: 9, want: 2},
+		{name: "shift berakhir tepat di awal rentang", items: []domain.Schedule{sc(1, 6, 8), sc(2, 8, 16)}, from: 8, to: 12, wantAt: 8, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAt, got := lowestHeadcount(tt.items, at(tt.from), at(tt.to))
+			if got != tt.want || !gotAt.Equal(at(tt.wantAt)) {
+				t.Errorf("lowestHeadcount() = %d @ %s, want %d @ %s", got, gotAt.Format("15:04"), tt.want, at(tt.wantAt).Format("15:04"))
+			}
+		})
+	}
+}
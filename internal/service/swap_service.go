@@ -1,12 +1,14 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
+	"bjb-backoffice/internal/auth"
+	"bjb-backoffice/internal/clock"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
 )
@@ -16,6 +18,9 @@ type SwapService struct {
 	sched *ScheduleService
 	notif *NotificationService
 	users repository.UserRepository
+	teams *TeamService
+	staff *StaffingService
+	audit *AuditService
 }
 
 func NewSwapService(
@@ -23,74 +28,58 @@ func NewSwapService(
 	sched *ScheduleService,
 	notif *NotificationService,
 	users repository.UserRepository,
+	teams *TeamService,
+	staff *StaffingService,
+	audit *AuditService,
 ) *SwapService {
-	return &SwapService{repo: repo, sched: sched, notif: notif, users: users}
+	return &SwapService{repo: repo, sched: sched, notif: notif, users: users, teams: teams, staff: staff, audit: audit}
 }
 
 // helper: ambil nama user (fallback "Agent #<id>")
 func (s *SwapService) getName(uid uint) string {
-	if uid == 0 || s.users == nil {
-		return fmt.Sprintf("Agent #%d", uid)
-	}
-	u, err := s.users.FindByID(uid)
-	if err != nil || u == nil || u.FullName == "" {
-		return fmt.Sprintf("Agent #%d", uid)
-	}
-	return u.FullName
+	return displayName(s.users, uid, "Agent")
 }
 
-// helper: list semua user id (via paginasi repo.List)
+// helper: list semua user id aktif
 func (s *SwapService) listAllUserIDs() ([]uint, error) {
-	out := []uint{}
 	if s.users == nil {
-		return out, nil
+		return []uint{}, nil
 	}
-	const pageSize = 1000
-	page := 1
-	for {
-		users, total, err := s.users.List(page, pageSize)
-		if err != nil {
-			return nil, err
-		}
-		for _, u := range users {
-			out = append(out, u.ID)
-		}
-		if int64(page*pageSize) >= total || len(users) == 0 {
-			break
-		}
-		page++
+	return s.users.ListAllIDs()
+}
+
+// helper: TL/SPV tim para pihak (fallback: semua backoffice aktif)
+func (s *SwapService) reviewerIDs(uids ...uint) []uint {
+	if s.teams == nil {
+		return []uint{}
 	}
-	return out, nil
+	return s.teams.ReviewerIDsFor(uids...)
 }
 
-// helper: ambil semua user id yang ber-ROLE Backoffice/Admin
-func (s *SwapService) getBackofficeIDs() []uint {
-	out := []uint{}
+// helper: user harus ada & aktif untuk ikut swap
+func (s *SwapService) ensureActive(uid uint, who string) error {
 	if s.users == nil {
-		return out
+		return nil
 	}
-	users, _, err := s.users.List(1, 2000)
-	if err != nil {
-		return out
-	}
-	for _, u := range users {
-		for _, r := range u.Roles {
-			// r.Name bertipe domain.RoleName -> cast ke string
-			name := strings.ToUpper(strings.TrimSpace(string(r.Name)))
-			if name == "BACKOFFICE" || name == "ADMIN" {
-				out = append(out, u.ID)
-				break
-			}
-		}
+	if ok, err := s.users.IsActive(uid); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%s tidak aktif", who)
 	}
-	return out
+	return nil
 }
 
 // Buat swap dari RFC3339 start_at (end_at default +8 jam)
-// targetUserID optional: jika diisi, maka notif HANYA ke requester, target, dan Backoffice
-func (s *SwapService) CreateFromRFC3339(requester uint, startRFC3339 string, reason string, targetUserID *uint) (*domain.SwapRequest, error) {
-	if requester == 0 {
-		return nil, errors.New("invalid requester")
+// targetUserID optional: jika diisi, maka notif HANYA ke requester, target, dan TL/SPV tim mereka
+// Requester = user yang login.
+func (s *SwapService) CreateFromRFC3339(ctx context.Context, startRFC3339 string, reason string, targetUserID *uint) (*domain.SwapRequest, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requester := p.ID
+	if targetUserID != nil && *targetUserID == requester {
+		return nil, errors.New("tidak bisa tukar dengan diri sendiri")
 	}
 	start, err := time.Parse(time.RFC3339, startRFC3339)
 	if err != nil {
@@ -98,6 +87,12 @@ func (s *SwapService) CreateFromRFC3339(requester uint, startRFC3339 string, rea
 	}
 	end := start.Add(8 * time.Hour)
 
+	if targetUserID != nil && *targetUserID != 0 {
+		if err := s.ensureActive(*targetUserID, "target"); err != nil {
+			return nil, err
+		}
+	}
+
 	// opsional: cek requester memang punya jadwal di window tsb
 	if ok, err := s.sched.ExistsOverlap(requester, start, end, nil); err != nil {
 		return nil, err
@@ -116,6 +111,7 @@ func (s *SwapService) CreateFromRFC3339(requester uint, startRFC3339 string, rea
 	if err := s.repo.Create(m); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "swap.create", domain.AuditSwap, m.ID, nil, m)
 	log.Printf("[swap-create] ok swapID=%d uid=%d start=%s end=%s target=%v",
 		m.ID, requester, m.StartAt.Format(time.RFC3339), m.EndAt.Format(time.RFC3339), targetUserID)
 
@@ -144,8 +140,8 @@ func (s *SwapService) CreateFromRFC3339(requester uint, startRFC3339 string, rea
 			log.Printf("[swap-create] ERROR notify target uid=%d swapID=%d err=%v", target, m.ID, err)
 		}
 
-		// backoffice
-		for _, bid := range s.getBackofficeIDs() {
+		// TL/SPV tim requester & target
+		for _, bid := range s.reviewerIDs(requester, target) {
 			if bid == requester || bid == target {
 				continue
 			}
@@ -186,20 +182,35 @@ type acceptParams struct {
 	end           time.Time
 }
 
-// Agent penerima menyetujui, pilih schedule miliknya untuk ditukar
-func (s *SwapService) Accept(id uint, me uint, counterpartyScheduleID uint) (*domain.SwapRequest, error) {
-	if me == 0 || counterpartyScheduleID == 0 {
-		return nil, errors.New("invalid parameters")
+// Agent penerima (user yang login) menyetujui, pilih schedule miliknya untuk ditukar
+func (s *SwapService) Accept(ctx context.Context, id uint, counterpartyScheduleID uint, override bool) (*domain.SwapRequest, *StaffingCheck, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	me := p.ID
+	if counterpartyScheduleID == 0 {
+		return nil, nil, errors.New("invalid parameters")
 	}
 
 	sw, err := s.repo.FindByID(id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if sw.Status != domain.SwapPending {
-		return nil, errors.New("swap bukan PENDING")
+		return nil, nil, errors.New("swap bukan PENDING")
+	}
+	if sw.RequesterID == me {
+		return nil, nil, errors.New("pengaju tidak bisa menerima swap sendiri")
+	}
+	if sw.TargetUserID != nil && *sw.TargetUserID != me {
+		return nil, nil, errors.New("swap ini ditujukan ke agent lain")
+	}
+	if err := s.ensureActive(sw.RequesterID, "requester"); err != nil {
+		return nil, nil, err
 	}
 
+	before := *sw
 	params := acceptParams{
 		cpScheduleID: counterpartyScheduleID,
 		reqUID:       sw.RequesterID,
@@ -212,18 +223,31 @@ func (s *SwapService) Accept(id uint, me uint, counterpartyScheduleID uint) (*do
 	reqSch, err := s.sched.FindByUserAndOverlap(params.reqUID, params.start, params.end)
 	if err != nil {
 		if reqSch, err = s.sched.FindByUserAndWindow(params.reqUID, params.start, params.end); err != nil {
-			dayStart := time.Date(params.start.Year(), params.start.Month(), params.start.Day(), 0, 0, 0, 0, params.start.Location())
+			dayStart := clock.DayOf(params.start)
 			dayEnd := dayStart.Add(24 * time.Hour)
 			if reqSch, err = s.sched.FindByUserAndSameDay(params.reqUID, dayStart, dayEnd); err != nil {
-				return nil, errors.New("jadwal requester tidak ditemukan untuk window ini")
+				return nil, nil, errors.New("jadwal requester tidak ditemukan untuk window ini")
 			}
 		}
 	}
 	params.reqScheduleID = reqSch.ID
 
+	// cek minimum staffing: kedua jadwal bertukar pemilik
+	cpSch, err := s.sched.FindByID(params.cpScheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	reqAfter, cpAfter := *reqSch, *cpSch
+	reqAfter.UserID, cpAfter.UserID = params.cpUID, params.reqUID
+	check, err := s.staff.Guard(ctx, []domain.Schedule{*reqSch, *cpSch}, []domain.Schedule{reqAfter, cpAfter}, override)
+	if err != nil {
+		return nil, check, err
+	}
+
 	// swap jadwal
-	if err := s.sched.SwapSchedules(params.reqScheduleID, params.cpScheduleID, params.reqUID, params.cpUID); err != nil {
-		return nil, err
+	swapCtx := WithScheduleChange(ctx, domain.ScheduleSourceSwap, fmt.Sprintf("swap #%d", sw.ID))
+	if err := s.sched.SwapSchedules(swapCtx, params.reqScheduleID, params.cpScheduleID, params.reqUID, params.cpUID); err != nil {
+		return nil, nil, err
 	}
 
 	now := time.Now()
@@ -232,14 +256,18 @@ func (s *SwapService) Accept(id uint, me uint, counterpartyScheduleID uint) (*do
 	sw.ApprovedAt = &now
 
 	if err := s.repo.Update(sw); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	s.audit.Record(ctx, "swap.accept", domain.AuditSwap, sw.ID, before, map[string]any{
+		"swap": sw, "requester_schedule_id": params.reqScheduleID, "counterparty_schedule_id": params.cpScheduleID,
+	})
+	s.staff.RecordOverride(ctx, "swap.accept", domain.AuditSwap, sw.ID, check)
 	log.Printf("[swap-accept] ok swapID=%d by uid=%d status=%s", sw.ID, me, sw.Status)
 
 	// === NOTIF saat APPROVED ===
 	if s.notif == nil {
 		log.Printf("[swap-accept] WARN notif service is nil; skip notif APPROVED swapID=%d", sw.ID)
-		return sw, nil
+		return sw, check, nil
 	}
 	refID := sw.ID
 	reqName := s.getName(params.reqUID)
@@ -253,16 +281,21 @@ func (s *SwapService) Accept(id uint, me uint, counterpartyScheduleID uint) (*do
 		// fallback minimal ke dua pihak
 		_ = s.notif.Notify(params.reqUID, title, body, "SWAP", &refID)
 		_ = s.notif.Notify(params.cpUID, title, body, "SWAP", &refID)
-		return sw, nil
+		return sw, check, nil
 	}
 	for _, uid := range allIDs {
 		_ = s.notif.Notify(uid, title, body, "SWAP", &refID)
 	}
 	log.Printf("[swap-accept] broadcast APPROVED OK swapID=%d to %d users", sw.ID, len(allIDs))
-	return sw, nil
+	return sw, check, nil
 }
 
-func (s *SwapService) Cancel(id uint, requester uint) (*domain.SwapRequest, error) {
+func (s *SwapService) Cancel(ctx context.Context, id uint) (*domain.SwapRequest, error) {
+	p, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	requester := p.ID
 	sw, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -274,10 +307,12 @@ func (s *SwapService) Cancel(id uint, requester uint) (*domain.SwapRequest, erro
 		return nil, errors.New("hanya bisa cancel saat PENDING")
 	}
 
+	before := *sw
 	sw.Status = domain.SwapCancelled
 	if err := s.repo.Update(sw); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "swap.cancel", domain.AuditSwap, sw.ID, before, sw)
 	log.Printf("[swap-cancel] ok swapID=%d by uid=%d status=%s", sw.ID, requester, sw.Status)
 
 	if s.notif == nil {
@@ -287,8 +322,8 @@ func (s *SwapService) Cancel(id uint, requester uint) (*domain.SwapRequest, erro
 	refID := sw.ID
 	body := fmt.Sprintf("Swap #%d dibatalkan • Window %s–%s",
 		sw.ID,
-		sw.StartAt.Format("02 Jan 06 15:04"),
-		sw.EndAt.Format("02 Jan 06 15:04"),
+		sw.StartAt.In(clock.Location()).Format("02 Jan 06 15:04"),
+		sw.EndAt.In(clock.Location()).Format("02 Jan 06 15:04"),
 	)
 	if err := s.notif.Notify(requester, "Swap Dibatalkan", body, "SWAP", &refID); err != nil {
 		log.Printf("[swap-cancel] ERROR notify swapID=%d uid=%d err=%v", sw.ID, requester, err)
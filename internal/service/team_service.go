@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bjb-backoffice/internal/clock"
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/repository"
+)
+
+type TeamService struct {
+	teams repository.TeamRepository
+	users repository.UserRepository
+	audit *AuditService
+}
+
+func NewTeamService(teams repository.TeamRepository, users repository.UserRepository, audit *AuditService) *TeamService {
+	return &TeamService{teams: teams, users: users, audit: audit}
+}
+
+type TeamInput struct {
+	Name         string
+	LeaderID     *uint
+	SupervisorID *uint
+	Active       *bool
+}
+
+func (s *TeamService) Create(ctx context.Context, in TeamInput) (*domain.Team, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return nil, errors.New("name required")
+	}
+	if err := s.checkName(name, 0); err != nil {
+		return nil, err
+	}
+	if err := s.checkLead(in.LeaderID, domain.RoleTL, "leader"); err != nil {
+		return nil, err
+	}
+	if err := s.checkLead(in.SupervisorID, domain.RoleSPV, "supervisor"); err != nil {
+		return nil, err
+	}
+	t := &domain.Team{Name: name, LeaderID: nilIfZero(in.LeaderID), SupervisorID: nilIfZero(in.SupervisorID), Active: true}
+	if in.Active != nil {
+		t.Active = *in.Active
+	}
+	if err := s.teams.Create(t); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "team.create", domain.AuditTeam, t.ID, nil, t)
+	return t, nil
+}
+
+func (s *TeamService) Update(ctx context.Context, id uint, in TeamInput) (*domain.Team, error) {
+	t, err := s.teams.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	if n := strings.TrimSpace(in.Name); n != "" {
+		if err := s.checkName(n, t.ID); err != nil {
+			return nil, err
+		}
+		t.Name = n
+	}
+	if in.LeaderID != nil {
+		if err := s.checkLead(in.LeaderID, domain.RoleTL, "leader"); err != nil {
+			return nil, err
+		}
+		t.LeaderID = nilIfZero(in.LeaderID) // 0 = kosongkan
+	}
+	if in.SupervisorID != nil {
+		if err := s.checkLead(in.SupervisorID, domain.RoleSPV, "supervisor"); err != nil {
+			return nil, err
+		}
+		t.SupervisorID = nilIfZero(in.SupervisorID)
+	}
+	if in.Active != nil {
+		t.Active = *in.Active
+	}
+	if err := s.teams.Update(t); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "team.update", domain.AuditTeam, t.ID, before, t)
+	return t, nil
+}
+
+func (s *TeamService) Delete(ctx context.Context, id uint) error {
+	before, err := s.teams.FindByID(id)
+	if err != nil {
+		return err
+	}
+	// histori keanggotaan dipertahankan; tim berhenti berlaku mulai hari ini
+	if err := s.teams.Delete(id, clock.DayOf(clock.Now())); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "team.delete", domain.AuditTeam, id, before, nil)
+	return nil
+}
+
+func (s *TeamService) Get(id uint) (*domain.Team, error) { return s.teams.FindByID(id) }
+
+func (s *TeamService) List() ([]domain.Team, error) { return s.teams.List() }
+
+// AddMember memasukkan user ke tim mulai tanggal from; keanggotaan lama otomatis ditutup.
+func (s *TeamService) AddMember(ctx context.Context, teamID, userID uint, from time.Time) (*domain.TeamMember, error) {
+	if _, err := s.teams.FindByID(teamID); err != nil {
+		return nil, err
+	}
+	if ok, err := s.users.IsActive(userID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.New("user tidak aktif")
+	}
+	m := &domain.TeamMember{
+		TeamID:        teamID,
+		UserID:        userID,
+		EffectiveFrom: time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, clock.Location()),
+	}
+	if err := s.teams.AddMember(m); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "team.member_add", domain.AuditTeam, teamID, nil, m)
+	return m, nil
+}
+
+// RemoveMember mengakhiri keanggotaan per tanggal to (inklusif).
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, userID uint, to time.Time) error {
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, clock.Location())
+	if err := s.teams.EndMember(teamID, userID, end); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "team.member_remove", domain.AuditTeam, teamID, nil,
+		map[string]any{"user_id": userID, "effective_to": end})
+	return nil
+}
+
+func (s *TeamService) ListMembers(teamID uint, day time.Time) ([]domain.TeamMember, error) {
+	return s.teams.ListMembers(teamID, day)
+}
+
+// ReviewerIDs = TL & SPV aktif dari tim user saat ini. Kalau user belum punya
+// tim (atau tim tanpa TL/SPV aktif) fallback ke semua backoffice aktif.
+func (s *TeamService) ReviewerIDs(userID uint) []uint {
+	if t, err := s.teams.FindTeamForUser(userID, clock.Now()); err == nil {
+		out := make([]uint, 0, 2)
+		for _, id := range []*uint{t.LeaderID, t.SupervisorID} {
+			if id == nil || *id == userID {
+				continue
+			}
+			if ok, _ := s.users.IsActive(*id); ok {
+				out = append(out, *id)
+			}
+		}
+		if len(out) > 0 {
+			return out
+		}
+	}
+	ids, err := s.users.ListActiveIDsByRoles(domain.BackofficeRoles...)
+	if err != nil {
+		return []uint{}
+	}
+	return ids
+}
+
+// ReviewerIDsFor menggabungkan reviewer beberapa user (mis. requester & target swap).
+func (s *TeamService) ReviewerIDsFor(userIDs ...uint) []uint {
+	seen := map[uint]bool{}
+	out := []uint{}
+	for _, uid := range userIDs {
+		for _, id := range s.ReviewerIDs(uid) {
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+	}
+	return out
+}
+
+// ScopeUserIDs menerjemahkan filter tim di query (?team=mine atau ?team_id=)
+// menjadi daftar user_id anggota per hari ini. nil = tanpa filter.
+func (s *TeamService) ScopeUserIDs(viewerID uint, team, teamID string) ([]uint, error) {
+	var teamIDs []uint
+	switch {
+	case teamID != "":
+		var id uint
+		if _, err := fmt.Sscan(teamID, &id); err != nil || id == 0 {
+			return nil, errors.New("invalid team_id")
+		}
+		teamIDs = []uint{id}
+	case team == "mine":
+		ids, err := s.teams.ListIDsLedBy(viewerID)
+		if err != nil {
+			return nil, err
+		}
+		teamIDs = ids
+	case team == "":
+		return nil, nil
+	default:
+		return nil, errors.New("invalid team filter (gunakan team=mine atau team_id)")
+	}
+	return s.teams.MemberIDs(teamIDs, clock.Now())
+}
+
+// checkName: nama tim unik di antara tim yang belum dihapus; nama tim yang
+// sudah dihapus boleh dipakai lagi.
+func (s *TeamService) checkName(name string, excludeID uint) error {
+	taken, err := s.teams.NameTaken(name, excludeID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("team name %q already used", name)
+	}
+	return nil
+}
+
+func (s *TeamService) checkLead(id *uint, want domain.RoleName, field string) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	u, err := s.users.FindByID(*id)
+	if err != nil {
+		return fmt.Errorf("%s not found", field)
+	}
+	for _, r := range u.Roles {
+		if r.Name == want || r.Name == domain.RoleSuperAdmin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s harus ber-role %s", field, want)
+}
+
+func nilIfZero(p *uint) *uint {
+	if p == nil || *p == 0 {
+		return nil
+	}
+	return p
+}
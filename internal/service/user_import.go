@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"bjb-backoffice/internal/domain"
+	"bjb-backoffice/internal/tabular"
+
+	"github.com/google/uuid"
+)
+
+// Kolom file import: full_name, email, roles, password (opsional). Export memakai
+// kolom yang sama minus password plus active; kolom tak dikenal diabaikan saat import.
+var userExportHeader = []string{"full_name", "email", "roles", "active"}
+
+const maxUserImportRows = 1000
+
+type UserImportRow struct {
+	Row          int      `json:"row"` // nomor baris di file (header = 1)
+	FullName     string   `json:"full_name"`
+	Email        string   `json:"email"`
+	Roles        []string `json:"roles"`
+	Status       string   `json:"status"` // OK | ERROR | CREATED
+	Errors       []string `json:"errors,omitempty"`
+	UserID       uint     `json:"user_id,omitempty"`
+	TempPassword string   `json:"temp_password,omitempty"` // hanya kalau password dikosongkan
+}
+
+type UserImportResult struct {
+	DryRun  bool            `json:"dry_run"`
+	Total   int             `json:"total"`
+	Valid   int             `json:"valid"`
+	Invalid int             `json:"invalid"`
+	Created int             `json:"created"`
+	Rows    []UserImportRow `json:"rows"`
+}
+
+// ImportUsers memvalidasi semua baris lalu (kalau bukan dry-run dan tidak ada
+// error sama sekali) membuat user dalam satu transaksi. Satu baris gagal →
+// tidak ada yang dibuat.
+func (s *UserService) ImportUsers(ctx context.Context, rows [][]string, dryRun bool) (*UserImportResult, error) {
+	if len(rows) < 2 {
+		return nil, errors.New("file kosong (minimal header + 1 baris)")
+	}
+	idx := tabular.HeaderIndex(rows[0])
+	for _, col := range []string{"full_name", "email", "roles"} {
+		if _, ok := idx[col]; !ok {
+			return nil, fmt.Errorf("kolom %q tidak ada di header", col)
+		}
+	}
+	data := rows[1:]
+	if len(data) > maxUserImportRows {
+		return nil, fmt.Errorf("maksimal %d baris per import", maxUserImportRows)
+	}
+
+	res := &UserImportResult{DryRun: dryRun, Rows: make([]UserImportRow, 0, len(data))}
+	passwords := make([]string, 0, len(data))
+	seen := map[string]int{}
+	emails := make([]string, 0, len(data))
+
+	for i, raw := range data {
+		r := UserImportRow{
+			Row:      i + 2,
+			FullName: tabular.Cell(raw, idx, "full_name"),
+			Email:    strings.ToLower(tabular.Cell(raw, idx, "email")),
+		}
+		pw := tabular.Cell(raw, idx, "password")
+
+		if r.FullName == "" {
+			r.Errors = append(r.Errors, "full_name wajib diisi")
+		}
+		if r.Email == "" {
+			r.Errors = append(r.Errors, "email wajib diisi")
+		} else if a, err := mail.ParseAddress(r.Email); err != nil || a.Address != r.Email {
+			r.Errors = append(r.Errors, "format email tidak valid")
+		} else if prev, dup := seen[r.Email]; dup {
+			r.Errors = append(r.Errors, fmt.Sprintf("email duplikat dengan baris %d", prev))
+		} else {
+			seen[r.Email] = r.Row
+			emails = append(emails, r.Email)
+		}
+		for _, rn := range splitRoles(tabular.Cell(raw, idx, "roles")) {
+			if !domain.RoleName(rn).Valid() {
+				r.Errors = append(r.Errors, fmt.Sprintf("role tidak dikenal: %s", rn))
+				continue
+			}
+			r.Roles = append(r.Roles, rn)
+		}
+		if len(r.Roles) == 0 && len(r.Errors) == 0 {
+			r.Errors = append(r.Errors, "minimal satu role")
+		}
+		if pw != "" && len(pw) < 6 {
+			r.Errors = append(r.Errors, "password minimal 6 karakter")
+		}
+		res.Rows = append(res.Rows, r)
+		passwords = append(passwords, pw)
+	}
+
+	existing, err := s.users.ExistingEmails(emails)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Rows {
+		r := &res.Rows[i]
+		if existing[r.Email] {
+			r.Errors = append(r.Errors, "email sudah terdaftar")
+		}
+		if len(r.Errors) > 0 {
+			r.Status = "ERROR"
+			res.Invalid++
+		} else {
+			r.Status = "OK"
+			res.Valid++
+		}
+	}
+	res.Total = len(res.Rows)
+	if dryRun || res.Invalid > 0 {
+		return res, nil
+	}
+
+	// role di-resolve sekali saja
+	roleCache := map[string]domain.Role{}
+	users := make([]*domain.User, 0, len(res.Rows))
+	for i := range res.Rows {
+		r := &res.Rows[i]
+		pw := passwords[i]
+		if pw == "" {
+			if pw, err = randomToken(9); err != nil {
+				return nil, err
+			}
+			r.TempPassword = pw
+		}
+		hash, err := s.auth.GeneratePasswordHash(pw)
+		if err != nil {
+			return nil, err
+		}
+		u := &domain.User{
+			UUID:         uuid.New(),
+			FullName:     r.FullName,
+			Email:        r.Email,
+			PasswordHash: hash,
+			Active:       true,
+		}
+		for _, rn := range r.Roles {
+			role, ok := roleCache[rn]
+			if !ok {
+				rp, err := s.roles.Ensure(domain.RoleName(rn))
+				if err != nil {
+					return nil, err
+				}
+				role = *rp
+				roleCache[rn] = role
+			}
+			u.Roles = append(u.Roles, role)
+		}
+		users = append(users, u)
+	}
+	if err := s.users.CreateMany(users); err != nil {
+		return nil, err
+	}
+	for i, u := range users {
+		res.Rows[i].UserID = u.ID
+		res.Rows[i].Status = "CREATED"
+		s.audit.Record(ctx, "user.import", domain.AuditUser, u.ID, nil, u)
+	}
+	res.Created = len(users)
+	return res, nil
+}
+
+// ExportUsers menghasilkan header + baris dengan format yang sama dengan import
+// (tanpa password).
+func (s *UserService) ExportUsers() ([]string, [][]string, error) {
+	users, err := s.users.ListAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make([][]string, 0, len(users))
+	for i := range users {
+		u := &users[i]
+		roles := make([]string, 0, len(u.Roles))
+		for _, r := range u.Roles {
+			roles = append(roles, string(r.Name))
+		}
+		out = append(out, []string{u.FullName, u.Email, strings.Join(roles, ";"), fmt.Sprint(u.Active)})
+	}
+	return userExportHeader, out, nil
+}
+
+// splitRoles menerima "AGENT;TL", "AGENT, TL" atau "agent|tl".
+func splitRoles(s string) []string {
+	f := strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' || r == '|' })
+	out := make([]string, 0, len(f))
+	for _, x := range f {
+		if x = strings.ToUpper(strings.TrimSpace(x)); x != "" {
+			out = append(out, x)
+		}
+	}
+	return out
+}
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"bjb-backoffice/internal/auth"
 	"bjb-backoffice/internal/domain"
 	"bjb-backoffice/internal/repository"
 
@@ -11,13 +15,31 @@ import (
 )
 
 type UserService struct {
-	users repository.UserRepository
-	roles repository.RoleRepository
-	auth  *AuthService
+	users     repository.UserRepository
+	roles     repository.RoleRepository
+	auth      *AuthService
+	schedules repository.ScheduleRepository
+	leaves    repository.LeaveRepository
+	swaps     repository.SwapRepository
+	holidays  repository.HolidaySwapRepository
+	audit     *AuditService
 }
 
-func NewUserService(users repository.UserRepository, roles repository.RoleRepository, auth *AuthService) *UserService {
-	return &UserService{users: users, roles: roles, auth: auth}
+func NewUserService(
+	users repository.UserRepository,
+	roles repository.RoleRepository,
+	auth *AuthService,
+	schedules repository.ScheduleRepository,
+	leaves repository.LeaveRepository,
+	swaps repository.SwapRepository,
+	holidays repository.HolidaySwapRepository,
+	audit *AuditService,
+) *UserService {
+	return &UserService{
+		users: users, roles: roles, auth: auth,
+		schedules: schedules, leaves: leaves, swaps: swaps, holidays: holidays,
+		audit: audit,
+	}
 }
 
 type CreateUserInput struct {
@@ -36,10 +58,14 @@ type UpdateUserInput struct { // 👈 ADD
 	Active   *bool
 }
 
-func (s *UserService) CreateUser(in CreateUserInput) (*domain.User, error) {
+// CreateUser: ctx tanpa principal = sistem (seed).
+func (s *UserService) CreateUser(ctx context.Context, in CreateUserInput) (*domain.User, error) {
 	if in.Email == "" || in.Password == "" || in.FullName == "" {
 		return nil, errors.New("fullname/email/password required")
 	}
+	if old, err := s.users.FindByEmailWithArchived(in.Email); err == nil && old.DeletedAt.Valid {
+		return nil, fmt.Errorf("email dipakai user #%d yang diarsipkan; gunakan restore", old.ID)
+	}
 	hash, err := s.auth.GeneratePasswordHash(in.Password)
 	if err != nil {
 		return nil, err
@@ -66,10 +92,15 @@ func (s *UserService) CreateUser(in CreateUserInput) (*domain.User, error) {
 	if err := s.users.Create(u); err != nil {
 		return nil, err
 	}
+	s.audit.Record(ctx, "user.create", domain.AuditUser, u.ID, nil, u)
 	return u, nil
 }
 
-func (s *UserService) AssignRoles(userID uint, roleNames []domain.RoleName) error {
+func (s *UserService) AssignRoles(ctx context.Context, userID uint, roleNames []domain.RoleName) error {
+	before, err := s.users.FindByID(userID)
+	if err != nil {
+		return err
+	}
 	var rs []domain.Role
 	for _, rn := range roleNames {
 		r, err := s.roles.Ensure(rn)
@@ -78,7 +109,13 @@ func (s *UserService) AssignRoles(userID uint, roleNames []domain.RoleName) erro
 		}
 		rs = append(rs, *r)
 	}
-	return s.users.AssignRoles(userID, rs)
+	if err := s.users.AssignRoles(userID, rs); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "user.roles", domain.AuditUser, userID,
+		map[string]any{"roles": before.Roles}, map[string]any{"roles": rs})
+	// roles ada di access token → paksa login ulang supaya token lama tidak berlaku
+	return s.auth.RevokeUserSessions(userID)
 }
 
 func (s *UserService) GetByID(id uint) (*domain.User, error) {
@@ -89,7 +126,25 @@ func (s *UserService) List(page, size int) ([]domain.User, int64, error) {
 	return s.users.List(page, size)
 }
 
-func (s *UserService) UpdateUser(in UpdateUserInput) (*domain.User, error) {
+func (s *UserService) ListActive(page, size int) ([]domain.User, int64, error) {
+	return s.users.ListActive(page, size)
+}
+
+// Deactivate menonaktifkan user: login ditolak, session dicabut, dan user
+// tidak lagi ikut roster/notifikasi.
+func (s *UserService) Deactivate(ctx context.Context, id uint) (*domain.User, error) {
+	f := false
+	return s.UpdateUser(ctx, UpdateUserInput{ID: id, Active: &f})
+}
+
+// Reactivate mengaktifkan kembali user & mencatat siapa yang melakukannya.
+func (s *UserService) Reactivate(ctx context.Context, id uint) (*domain.User, error) {
+	t := true
+	return s.UpdateUser(ctx, UpdateUserInput{ID: id, Active: &t})
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, in UpdateUserInput) (*domain.User, error) {
+	actor := auth.ActorID(ctx)
 	u, err := s.users.FindByID(in.ID)
 	if err != nil {
 		return nil, err
@@ -105,8 +160,19 @@ func (s *UserService) UpdateUser(in UpdateUserInput) (*domain.User, error) {
 	if in.PhotoURL != nil {
 		fields["photo_url"] = in.PhotoURL
 	} // pointer disimpan apa adanya
-	if in.Active != nil {
+	if in.Active != nil && *in.Active != u.Active {
+		if !*in.Active && actor == u.ID {
+			return nil, errors.New("cannot deactivate your own account")
+		}
+		now := time.Now()
 		fields["active"] = *in.Active
+		if *in.Active {
+			fields["reactivated_at"] = now
+			fields["reactivated_by"] = actorOrNil(actor)
+		} else {
+			fields["deactivated_at"] = now
+			fields["deactivated_by"] = actorOrNil(actor)
+		}
 	}
 
 	if in.Password != nil && *in.Password != "" {
@@ -124,20 +190,134 @@ func (s *UserService) UpdateUser(in UpdateUserInput) (*domain.User, error) {
 	if err := s.users.UpdateFields(u.ID, fields); err != nil {
 		return nil, err
 	}
+	_, pwChanged := fields["password_hash"]
+	after, _ := s.users.FindByID(u.ID)
+	s.audit.Record(ctx, userUpdateAction(in, u, pwChanged), domain.AuditUser, u.ID, u, after)
+	deactivated := in.Active != nil && !*in.Active && u.Active
+	if pwChanged || deactivated {
+		if err := s.auth.RevokeUserSessions(u.ID); err != nil {
+			return nil, err
+		}
+	}
 	return s.users.FindByID(u.ID)
 }
 
-func (s *UserService) UpdateSelf(userID uint, in UpdateUserInput) (*domain.User, error) {
-	in.ID = userID
-	return s.UpdateUser(in) // reuse logic: hashing & fields map
+// UpdateSelf mengubah profil user yang login; ID di input diabaikan.
+func (s *UserService) UpdateSelf(ctx context.Context, in UpdateUserInput) (*domain.User, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in.ID = me.ID
+	in.Active = nil              // tidak boleh (de)aktivasi diri sendiri
+	return s.UpdateUser(ctx, in) // reuse logic: hashing & fields map
+}
+
+// ArchiveReport = daftar hal yang masih menempel ke user dan harus dialihkan
+// dulu sebelum user boleh diarsipkan.
+type ArchiveReport struct {
+	FutureSchedules     []domain.Schedule     `json:"future_schedules"`
+	PendingLeaves       []domain.LeaveRequest `json:"pending_leaves"`
+	PendingSwaps        []domain.SwapRequest  `json:"pending_swaps"`
+	PendingHolidaySwaps []domain.HolidaySwap  `json:"pending_holiday_swaps"`
+}
+
+func (r *ArchiveReport) Blocked() bool {
+	return len(r.FutureSchedules)+len(r.PendingLeaves)+len(r.PendingSwaps)+len(r.PendingHolidaySwaps) > 0
+}
+
+// ArchiveBlockedError dikembalikan DeleteUser bila masih ada yang perlu dialihkan.
+type ArchiveBlockedError struct{ Report *ArchiveReport }
+
+func (e *ArchiveBlockedError) Error() string {
+	return "user masih punya jadwal mendatang / pengajuan pending yang harus dialihkan"
+}
+
+func (s *UserService) ArchiveCheck(id uint) (*ArchiveReport, error) {
+	if _, err := s.users.FindByID(id); err != nil {
+		return nil, err
+	}
+	rep := &ArchiveReport{}
+	var err error
+	if rep.FutureSchedules, err = s.schedules.ListUpcomingByUser(id, time.Now()); err != nil {
+		return nil, err
+	}
+	pending := domain.LeavePending
+	if rep.PendingLeaves, _, err = s.leaves.List(&id, &pending, nil, nil, 1, 500); err != nil {
+		return nil, err
+	}
+	if rep.PendingSwaps, err = s.swaps.ListPendingByUser(id); err != nil {
+		return nil, err
+	}
+	if rep.PendingHolidaySwaps, err = s.holidays.ListPendingByUser(id); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// DeleteUser mengarsipkan user (soft delete). Ditolak dengan *ArchiveBlockedError
+// kalau masih ada jadwal mendatang atau pengajuan pending.
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+	by := me.ID
+	if id == by {
+		return errors.New("cannot archive your own account")
+	}
+	rep, err := s.ArchiveCheck(id)
+	if err != nil {
+		return err
+	}
+	if rep.Blocked() {
+		return &ArchiveBlockedError{Report: rep}
+	}
+	before, _ := s.users.FindByID(id)
+	if err := s.users.Archive(id, by); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "user.archive", domain.AuditUser, id, before, nil)
+	return s.auth.RevokeUserSessions(id)
 }
 
-func (s *UserService) DeleteUser(id uint) error {
-	return s.users.Delete(id)
+func (s *UserService) RestoreUser(ctx context.Context, id uint) (*domain.User, error) {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Restore(id, me.ID); err != nil {
+		return nil, err
+	}
+	u, err := s.users.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, "user.restore", domain.AuditUser, id, nil, u)
+	return u, nil
+}
+
+func (s *UserService) ListArchived(page, size int) ([]domain.User, int64, error) {
+	return s.users.ListArchived(page, size)
+}
+
+// DisplayName mengembalikan nama user, termasuk yang sudah diarsipkan.
+func (s *UserService) DisplayName(uid uint) string {
+	return displayName(s.users, uid, "User")
+}
+
+// NamesByIDs = nama user sekaligus (batch), termasuk yang nonaktif/diarsipkan.
+func (s *UserService) NamesByIDs(ids []uint) (map[uint]string, error) {
+	return s.users.NamesByIDs(ids)
 }
 
 // Ganti password diri sendiri dengan verifikasi current password
-func (s *UserService) ChangePassword(userID uint, current, newPass string) error {
+func (s *UserService) ChangePassword(ctx context.Context, current, newPass string) error {
+	me, err := auth.Require(ctx)
+	if err != nil {
+		return err
+	}
+	userID := me.ID
 	u, err := s.users.FindByID(userID)
 	if err != nil {
 		return err
@@ -155,5 +335,41 @@ func (s *UserService) ChangePassword(userID uint, current, newPass string) error
 		return err
 	}
 
-	return s.users.UpdateFields(userID, map[string]any{"password_hash": hash})
+	if err := s.users.UpdateFields(userID, map[string]any{"password_hash": hash}); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, "user.password_change", domain.AuditUser, userID, nil, nil)
+	return s.auth.RevokeUserSessions(userID)
+}
+
+func userUpdateAction(in UpdateUserInput, before *domain.User, pwChanged bool) string {
+	switch {
+	case in.Active != nil && *in.Active != before.Active && *in.Active:
+		return "user.reactivate"
+	case in.Active != nil && *in.Active != before.Active:
+		return "user.deactivate"
+	case pwChanged:
+		return "user.password_reset"
+	}
+	return "user.update"
+}
+
+func actorOrNil(id uint) *uint {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// displayName dipakai semua service untuk resolve nama di notifikasi/list;
+// user yang diarsipkan tetap tampil namanya.
+func displayName(users repository.UserRepository, uid uint, fallback string) string {
+	if users == nil || uid == 0 {
+		return fmt.Sprintf("%s #%d", fallback, uid)
+	}
+	u, err := users.FindByIDWithArchived(uid)
+	if err != nil || u == nil || u.FullName == "" {
+		return fmt.Sprintf("%s #%d", fallback, uid)
+	}
+	return u.FullName
 }
@@ -0,0 +1,121 @@
+package tabular
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pdfMargin   = 8.0
+	pdfRowH     = 6.0
+	pdfFontSize = 7.0
+	pdfMinFont  = 4.5
+	pdfFirstCol = 42.0
+)
+
+// writePDF mencetak sheet sebagai tabel di A3 landscape. Kolom beku (minimal
+// satu) memakai lebar tetap, sisanya dibagi rata; teks yang kepanjangan
+// dikecilkan lalu dipotong. Header diulang di tiap halaman.
+func writePDF(w io.Writer, s Sheet) error {
+	pdf := gofpdf.New("L", "mm", "A3", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.SetTitle(s.Name, true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // cp1252
+
+	cols := len(s.Header)
+	if cols == 0 {
+		pdf.AddPage()
+		return pdf.Output(w)
+	}
+	fixed := s.FreezeCols
+	if fixed < 1 {
+		fixed = 1
+	}
+	if fixed > cols {
+		fixed = cols
+	}
+	pageW, pageH := pdf.GetPageSize()
+	usable := pageW - 2*pdfMargin
+	widths := make([]float64, cols)
+	rest := usable
+	for i := 0; i < fixed; i++ {
+		widths[i] = pdfFirstCol
+		rest -= pdfFirstCol
+	}
+	if cols > fixed {
+		for i := fixed; i < cols; i++ {
+			widths[i] = rest / float64(cols-fixed)
+		}
+	} else {
+		widths[cols-1] += rest
+	}
+
+	cell := func(w float64, text, fill string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		text = tr(text)
+		size := pdfFontSize
+		pdf.SetFont("Helvetica", style, size)
+		for size > pdfMinFont && pdf.GetStringWidth(text) > w-1 {
+			size -= 0.5
+			pdf.SetFontSize(size)
+		}
+		for len(text) > 1 && pdf.GetStringWidth(text) > w-1 {
+			text = text[:len(text)-1]
+		}
+		align := "C"
+		if w >= pdfFirstCol {
+			align = "L"
+		}
+		if r, g, b, ok := hexRGB(fill); ok {
+			pdf.SetFillColor(r, g, b)
+			pdf.CellFormat(w, pdfRowH, text, "1", 0, align, true, 0, "")
+			return
+		}
+		pdf.CellFormat(w, pdfRowH, text, "1", 0, align, false, 0, "")
+	}
+	header := func() {
+		if s.Name != "" {
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.CellFormat(usable, 8, tr(s.Name), "", 1, "L", false, 0, "")
+		}
+		for i, h := range s.Header {
+			cell(widths[i], h, "F3F4F6", true)
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.AddPage()
+	header()
+	for r, row := range s.Rows {
+		if pdf.GetY()+pdfRowH > pageH-pdfMargin {
+			pdf.AddPage()
+			header()
+		}
+		for c := 0; c < cols; c++ {
+			v := ""
+			if c < len(row) {
+				v = row[c]
+			}
+			cell(widths[c], v, s.fill(r, c), c < fixed)
+		}
+		pdf.Ln(-1)
+	}
+	return pdf.Output(w)
+}
+
+func hexRGB(v string) (int, int, int, bool) {
+	if len(v) != 6 {
+		return 0, 0, 0, false
+	}
+	n, err := strconv.ParseUint(v, 16, 32)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return int(n >> 16 & 0xff), int(n >> 8 & 0xff), int(n & 0xff), true
+}
@@ -0,0 +1,237 @@
+// Package tabular membaca & menulis data tabel sederhana (CSV / XLSX)
+// untuk fitur import/export (user, roster, dll).
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatPDF  Format = "pdf" // hanya untuk export
+)
+
+var ErrUnsupportedFormat = errors.New("format tidak didukung (gunakan .csv atau .xlsx)")
+
+// FormatFromName menebak format dari nama file / query ?format=.
+func FormatFromName(name string) (Format, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	if ext == "" {
+		ext = strings.ToLower(name)
+	}
+	switch ext {
+	case "csv":
+		return FormatCSV, nil
+	case "xlsx":
+		return FormatXLSX, nil
+	case "pdf":
+		return FormatPDF, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Read mengembalikan semua baris (sheet pertama untuk XLSX). Sel di-trim,
+// baris kosong di akhir dibuang.
+func Read(r io.Reader, f Format) ([][]string, error) {
+	var rows [][]string
+	switch f {
+	case FormatCSV:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM dari Excel
+		cr := csv.NewReader(bytes.NewReader(data))
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		if rows, err = cr.ReadAll(); err != nil {
+			return nil, fmt.Errorf("csv: %w", err)
+		}
+	case FormatXLSX:
+		x, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("xlsx: %w", err)
+		}
+		defer x.Close()
+		sheets := x.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("xlsx: workbook kosong")
+		}
+		if rows, err = x.GetRows(sheets[0]); err != nil {
+			return nil, fmt.Errorf("xlsx: %w", err)
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	for i := range rows {
+		for j := range rows[i] {
+			rows[i][j] = strings.TrimSpace(rows[i][j])
+		}
+	}
+	for len(rows) > 0 && isBlank(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+// Write menulis header + rows ke w dalam format f.
+func Write(w io.Writer, f Format, sheet string, header []string, rows [][]string) error {
+	return WriteSheet(w, f, Sheet{Name: sheet, Header: header, Rows: rows})
+}
+
+// Sheet = tabel siap tulis. Fills opsional: warna latar per sel data
+// ("#RRGGBB", "" = polos), diabaikan untuk CSV.
+type Sheet struct {
+	Name       string // nama sheet XLSX / judul PDF
+	Header     []string
+	Rows       [][]string
+	Fills      [][]string
+	FreezeCols int // kolom kiri yang dibekukan (XLSX) / diulang lebar (PDF)
+}
+
+func (s Sheet) fill(r, c int) string {
+	if r < len(s.Fills) && c < len(s.Fills[r]) {
+		return strings.TrimPrefix(s.Fills[r][c], "#")
+	}
+	return ""
+}
+
+// WriteSheet menulis sheet ke w dalam format f.
+func WriteSheet(w io.Writer, f Format, s Sheet) error {
+	switch f {
+	case FormatCSV:
+		if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil { // supaya Excel baca UTF-8
+			return err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(s.Header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(s.Rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatXLSX:
+		return writeXLSX(w, s)
+	case FormatPDF:
+		return writePDF(w, s)
+	}
+	return ErrUnsupportedFormat
+}
+
+func writeXLSX(w io.Writer, s Sheet) error {
+	x := excelize.NewFile()
+	defer x.Close()
+	sheet := s.Name
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	if err := x.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+	all := append([][]string{s.Header}, s.Rows...)
+	for i, row := range all {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		vals := make([]any, len(row))
+		for j, v := range row {
+			vals[j] = v
+		}
+		if err := x.SetSheetRow(sheet, cell, &vals); err != nil {
+			return err
+		}
+	}
+	if len(s.Fills) > 0 {
+		styles := map[string]int{}
+		for r, row := range s.Rows {
+			for c := range row {
+				color := s.fill(r, c)
+				if color == "" {
+					continue
+				}
+				id, ok := styles[color]
+				if !ok {
+					var err error
+					id, err = x.NewStyle(&excelize.Style{
+						Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+						Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+					})
+					if err != nil {
+						return err
+					}
+					styles[color] = id
+				}
+				cell, _ := excelize.CoordinatesToCellName(c+1, r+2)
+				if err := x.SetCellStyle(sheet, cell, cell, id); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if s.FreezeCols > 0 {
+		top, _ := excelize.CoordinatesToCellName(s.FreezeCols+1, 2)
+		if err := x.SetPanes(sheet, &excelize.Panes{
+			Freeze: true, XSplit: s.FreezeCols, YSplit: 1, TopLeftCell: top, ActivePane: "bottomRight",
+		}); err != nil {
+			return err
+		}
+		last, _ := excelize.ColumnNumberToName(s.FreezeCols)
+		if err := x.SetColWidth(sheet, "A", last, 28); err != nil {
+			return err
+		}
+	}
+	_, err := x.WriteTo(w)
+	return err
+}
+
+// HeaderIndex memetakan nama kolom (lowercase, spasi → _) ke index kolom.
+func HeaderIndex(header []string) map[string]int {
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		k := strings.ToLower(strings.TrimSpace(h))
+		k = strings.ReplaceAll(k, " ", "_")
+		if _, dup := idx[k]; !dup {
+			idx[k] = i
+		}
+	}
+	return idx
+}
+
+// Cell mengambil nilai kolom col di row; "" bila kolom tidak ada.
+func Cell(row []string, idx map[string]int, col string) string {
+	i, ok := idx[col]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+func isBlank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+-- 20261018_schedules_timestamptz.sql
+-- Jadwal disimpan sebagai TIMESTAMPTZ. Kolom lama bertipe TIMESTAMP (tanpa zona)
+-- berisi jam dinding zona organisasi (APP_TIMEZONE, default Asia/Jakarta).
+-- Dijalankan otomatis oleh database.Connect sebelum AutoMigrate dengan zona
+-- dari APP_TIMEZONE; file ini untuk eksekusi manual (ganti zona bila perlu).
+-- Jangan serahkan konversi ini ke AutoMigrate: ia memakai zona session DB
+-- (biasanya UTC) sehingga jam jadwal lama bergeser. Aman dijalankan ulang.
+DO $$
+DECLARE col RECORD;
+BEGIN
+  FOR col IN
+    SELECT table_name, column_name FROM information_schema.columns
+    WHERE table_schema = current_schema() AND data_type = 'timestamp without time zone'
+      AND (table_name, column_name) IN (
+        ('schedules', 'start_at'), ('schedules', 'end_at'), ('schedules', 'published_at'),
+        ('holiday_swaps', 'off_date'))
+  LOOP
+    EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMPTZ USING %I AT TIME ZONE ''Asia/Jakarta''',
+      col.table_name, col.column_name, col.column_name);
+  END LOOP;
+END $$;